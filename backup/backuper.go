@@ -1,8 +1,14 @@
 package backup
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/tscolari/s3kup/log"
@@ -12,56 +18,421 @@ import (
 type Backuper struct {
 	s3Client       S3Client
 	versionsToKeep int
+	keepRules      []KeepRule
+	limits         DeletionLimits
+}
+
+// KeepRule keeps the newest Versions of the versions that have its labels,
+// whatever the labels of the pushed version are.
+type KeepRule struct {
+	Labels   s3.Labels
+	Versions int
+}
+
+type DeletionLimits struct {
+	MaxDeletes int
+	Force      bool
+}
+
+// Part is streamed like BackupReader's input. Size is -1 when it isn't known
+// ahead, e.g. for FIFOs.
+type Part struct {
+	Name   string
+	Reader io.Reader
+	Size   int64
 }
 
 type S3Client interface {
-	Store(path string, content []byte) error
+	StoreWithLabels(path string, content []byte, labels s3.Labels) error
+	StoreReader(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error)
 	List(path string) (versions s3.Versions, err error)
+	Labels(path string) (s3.Labels, error)
+	Size(path string) (uint64, error)
+	Copy(fromPath, toPath string) error
 	Delete(path string) error
+	VersionPath(backupName, version string) (string, error)
 }
 
-func New(s3Client S3Client, versionsToKeep int) Backuper {
+// ParseKeepRules parses rules in the format key=value=N.
+func ParseKeepRules(rules []string) ([]KeepRule, error) {
+	keepRules := []KeepRule{}
+	for _, rule := range rules {
+		separator := strings.LastIndex(rule, "=")
+		if separator < 0 {
+			return nil, errors.New("Invalid keep rule '" + rule + "'. It must be in the format key=value=N")
+		}
+
+		labels, err := s3.ParseLabels([]string{rule[:separator]})
+		if err != nil {
+			return nil, errors.New("Invalid keep rule '" + rule + "'. It must be in the format key=value=N")
+		}
+
+		versions, err := strconv.Atoi(rule[separator+1:])
+		if err != nil || versions <= 0 {
+			return nil, errors.New("Invalid keep rule '" + rule + "'. The number of versions must be 1 or greater")
+		}
+
+		keepRules = append(keepRules, KeepRule{Labels: labels, Versions: versions})
+	}
+
+	return keepRules, nil
+}
+
+func New(s3Client S3Client, versionsToKeep int, limits DeletionLimits) Backuper {
 	return Backuper{
 		s3Client:       s3Client,
 		versionsToKeep: versionsToKeep,
+		limits:         limits,
 	}
 }
 
-func (b Backuper) Backup(fileName string, fileContent []byte) error {
+func (b Backuper) WithKeepRules(rules []KeepRule) Backuper {
+	b.keepRules = rules
+	return b
+}
+
+func (b Backuper) Backup(fileName string, fileContent []byte, labels s3.Labels) error {
+	return b.BackupReader(fileName, bytes.NewReader(fileContent), int64(len(fileContent)), labels, s3.Attributes{})
+}
+
+func (b Backuper) BackupReader(fileName string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) error {
+	input := bufio.NewReader(reader)
+	if _, err := input.Peek(1); err == io.EOF {
+		return ErrEmptyInput
+	} else if err != nil {
+		return err
+	}
+
 	log.Info("Started backup of", fileName)
-	err := b.putFile(fileName, fileContent)
+	version, storedSize, err := b.putFile(fileName, input, size, labels, attributes)
+	if err != nil {
+		return err
+	}
+
+	return b.cleanUpOldVersions(fileName, version, storedSize, labels)
+}
+
+func (b Backuper) BackupBundle(fileName string, parts []Part, labels s3.Labels) error {
+	log.Info("Started bundle backup of", fileName)
+	err := validateParts(parts)
+	if err != nil {
+		return err
+	}
+
+	version := b.newVersion(fileName, labels)
+	manifestParts, err := b.putParts(fileName, version, parts)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := s3.NewManifest(manifestParts).Encode()
+	if err != nil {
+		return err
+	}
+
+	log.Info(" -- Committing the bundle manifest")
+	path, err := b.s3Client.VersionPath(fileName, version)
+	if err == nil {
+		err = b.s3Client.StoreWithLabels(path, manifest, labels)
+	}
 	if err != nil {
+		b.deletePartPaths(manifestParts)
 		return err
 	}
 
-	return b.cleanUpOldVersions(fileName)
+	return b.cleanUpOldVersions(fileName, version, uint64(len(manifest)), labels)
+}
+
+func (b Backuper) putFile(fileName string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (string, uint64, error) {
+	version := b.newVersion(fileName, labels)
+	path, err := b.s3Client.VersionPath(fileName, version)
+	if err != nil {
+		return version, 0, err
+	}
+
+	stagingPath := s3.StagingPath(fileName, version)
+	storedSize, err := b.s3Client.StoreReader(stagingPath, reader, size, labels, attributes)
+	if err == nil {
+		err = b.verifyStored(stagingPath, storedSize)
+	}
+	if err != nil {
+		b.deleteStaged(stagingPath)
+		return version, storedSize, err
+	}
+
+	log.Info(" -- Committing version:", version)
+	err = b.s3Client.Copy(stagingPath, path)
+	b.deleteStaged(stagingPath)
+	return version, storedSize, err
+}
+
+func (b Backuper) newVersion(fileName string, labels s3.Labels) string {
+	storedVersions, err := b.s3Client.List(fileName)
+	if err != nil {
+		log.Warn(" -- failed to list the stored versions, the version will rely on the local clock only:", err)
+	}
+
+	version := s3.NewVersionID(time.Now(), storedVersions)
+	log.Info(" -- File version:", version)
+	if len(labels) > 0 {
+		log.Info(" -- File labels:", labels.String())
+	}
+	return version
+}
+
+func (b Backuper) putParts(fileName, version string, parts []Part) ([]s3.ManifestPart, error) {
+	manifestParts := []s3.ManifestPart{}
+	for _, part := range parts {
+		manifestPart := s3.ManifestPart{
+			Name: part.Name,
+			Path: s3.PartPath(fileName, version, part.Name),
+		}
+
+		log.Info(" -- Uploading part:", part.Name)
+		size, err := b.s3Client.StoreReader(manifestPart.Path, part.Reader, part.Size, nil, s3.Attributes{})
+		if err != nil {
+			b.deletePartPaths(manifestParts)
+			return nil, err
+		}
+
+		manifestPart.Size = size
+		manifestParts = append(manifestParts, manifestPart)
+		err = b.verifyStored(manifestPart.Path, manifestPart.Size)
+		if err != nil {
+			b.deletePartPaths(manifestParts)
+			return nil, err
+		}
+	}
+
+	return manifestParts, nil
+}
+
+func (b Backuper) verifyStored(path string, expectedSize uint64) error {
+	size, err := b.s3Client.Size(path)
+	if err != nil {
+		return err
+	}
+
+	if size != expectedSize {
+		message := fmt.Sprintf("Uploaded object '%s' has %d bytes, expected %d", path, size, expectedSize)
+		return errors.New(message)
+	}
+
+	return nil
+}
+
+func (b Backuper) deleteStaged(stagingPath string) {
+	if err := b.s3Client.Delete(stagingPath); err != nil {
+		log.Warn(" -- failed to delete the staged upload, it will be removed by `cleanup`:", stagingPath, err)
+	}
+}
+
+func (b Backuper) deletePartPaths(parts []s3.ManifestPart) {
+	for _, part := range parts {
+		if err := b.s3Client.Delete(part.Path); err != nil {
+			log.Warn(" -- failed to delete the uploaded part, it will be removed by `cleanup`:", part.Name, err)
+		}
+	}
 }
 
-func (b Backuper) putFile(fileName string, fileContent []byte) error {
-	timestamp := time.Now().UnixNano()
-	fileName = fmt.Sprintf("%s/%d", fileName, timestamp)
-	log.Info(" -- File version:", timestamp)
-	return b.s3Client.Store(fileName, fileContent)
+func (b Backuper) cleanUpOldVersions(fileName string, uploadedVersion string, uploadedSize uint64, labels s3.Labels) error {
+	err := b.deleteOldVersions(fileName, uploadedVersion, uploadedSize, labels)
+	if err != nil {
+		return &RetentionError{Version: uploadedVersion, Err: err}
+	}
+
+	return nil
 }
 
-func (b Backuper) cleanUpOldVersions(fileName string) error {
+func (b Backuper) deleteOldVersions(fileName string, uploadedVersion string, uploadedSize uint64, labels s3.Labels) error {
 	log.Info(" -- Looking for old versions to delete. keeping", b.versionsToKeep)
 	storedVersions, err := b.s3Client.List(fileName)
 	if err != nil {
 		return err
 	}
 
+	err = verifyUpload(storedVersions, uploadedVersion, uploadedSize)
+	if err != nil {
+		if !b.limits.Force {
+			log.Warn(" -- refusing to delete old versions:", err)
+			return err
+		}
+		log.Warn(" -- forcing the clean up:", err)
+	}
+
 	sort.Sort(storedVersions)
-	if len(storedVersions) >= b.versionsToKeep {
-		extraVersions := len(storedVersions) - b.versionsToKeep
-		log.Info(" --", extraVersions, "old versions will be deleted")
-		for _, version := range storedVersions[:extraVersions] {
-			err = b.s3Client.Delete(version.Path)
-			log.Info(" -- deleted:", version.Version)
-			if err != nil {
-				return err
+	unpinnedVersions := storedVersions.Unpinned()
+	if pinnedVersions := len(storedVersions) - len(unpinnedVersions); pinnedVersions > 0 {
+		log.Info(" --", pinnedVersions, "pinned versions will be kept")
+	}
+
+	if len(unpinnedVersions) <= b.fewestVersionsToKeep() {
+		return nil
+	}
+
+	unpinnedVersions, err = b.withLabels(unpinnedVersions)
+	if err != nil {
+		return err
+	}
+
+	extraVersions := b.extraVersions(unpinnedVersions, labels)
+	if len(extraVersions) == 0 {
+		return nil
+	}
+
+	err = b.checkDeletionLimits(extraVersions)
+	if err != nil {
+		return err
+	}
+
+	log.Info(" --", len(extraVersions), "old versions will be deleted")
+	for _, version := range extraVersions {
+		err = b.deleteVersion(version)
+		log.Info(" -- deleted:", version.Version)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extraVersions applies every keep rule, and versionsToKeep to the versions
+// with exactly the pushed labels when no rule covers them. A version is only
+// extra when all the rules that cover it would delete it.
+func (b Backuper) extraVersions(versions s3.Versions, labels s3.Labels) s3.Versions {
+	kept := map[string]bool{}
+	extra := map[string]bool{}
+	keepNewest := func(group s3.Versions, versionsToKeep int) {
+		for i, version := range group {
+			if i < len(group)-versionsToKeep {
+				extra[version.Version] = true
+			} else {
+				kept[version.Version] = true
 			}
 		}
 	}
+
+	coveredByRule := false
+	for _, rule := range b.keepRules {
+		log.Info(" -- keeping", rule.Versions, "versions labeled", rule.Labels.String())
+		keepNewest(versions.Matching(rule.Labels), rule.Versions)
+		coveredByRule = coveredByRule || labels.Match(rule.Labels)
+	}
+
+	if !coveredByRule {
+		sameLabels := s3.Versions{}
+		for _, version := range versions {
+			if version.Labels.Equal(labels) {
+				sameLabels = append(sameLabels, version)
+			}
+		}
+		keepNewest(sameLabels, b.versionsToKeep)
+	}
+
+	extraVersions := s3.Versions{}
+	for _, version := range versions {
+		if extra[version.Version] && !kept[version.Version] {
+			extraVersions = append(extraVersions, version)
+		}
+	}
+
+	return extraVersions
+}
+
+func (b Backuper) fewestVersionsToKeep() int {
+	fewest := b.versionsToKeep
+	for _, rule := range b.keepRules {
+		if rule.Versions < fewest {
+			fewest = rule.Versions
+		}
+	}
+
+	return fewest
+}
+
+func (b Backuper) deleteVersion(version s3.Version) error {
+	err := b.s3Client.Delete(version.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, partPath := range version.PartPaths {
+		err = b.s3Client.Delete(partPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+func (b Backuper) checkDeletionLimits(versions s3.Versions) error {
+	if b.limits.MaxDeletes <= 0 || len(versions) <= b.limits.MaxDeletes {
+		return nil
+	}
+
+	if b.limits.Force {
+		log.Warn(" -- forcing the deletion of", len(versions), "old versions, above the limit of", b.limits.MaxDeletes)
+		return nil
+	}
+
+	for _, version := range versions {
+		log.Warn(" -- refused to delete:", version.Version)
+	}
+
+	message := fmt.Sprintf("Refused to delete %d old versions, the limit is %d per run (use --force to override)", len(versions), b.limits.MaxDeletes)
+	return errors.New(message)
+}
+
+func validateParts(parts []Part) error {
+	if len(parts) == 0 {
+		return errors.New("A bundle needs at least one part")
+	}
+
+	names := map[string]bool{}
+	for _, part := range parts {
+		if !s3.ValidPartName(part.Name) {
+			return errors.New("Invalid part name '" + part.Name + "'. It can only contain letters, numbers, '.', '_' and '-'")
+		}
+
+		if names[part.Name] {
+			return errors.New("The part '" + part.Name + "' was given more than once")
+		}
+		names[part.Name] = true
+	}
+
+	return nil
+}
+
+func (b Backuper) withLabels(versions s3.Versions) (s3.Versions, error) {
+	labeled := s3.Versions{}
+	for _, version := range versions {
+		versionLabels, err := b.s3Client.Labels(version.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		version.Labels = versionLabels
+		labeled = append(labeled, version)
+	}
+
+	return labeled, nil
+}
+
+func verifyUpload(storedVersions s3.Versions, uploadedVersion string, uploadedSize uint64) error {
+	for _, version := range storedVersions {
+		if version.Version != uploadedVersion {
+			continue
+		}
+
+		if version.Size != uploadedSize {
+			message := fmt.Sprintf("Uploaded version '%s' has %d bytes, expected %d", uploadedVersion, version.Size, uploadedSize)
+			return errors.New(message)
+		}
+
+		return nil
+	}
+
+	message := fmt.Sprintf("Uploaded version '%s' could not be listed back", uploadedVersion)
+	return errors.New(message)
+}
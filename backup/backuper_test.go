@@ -3,6 +3,10 @@ package backup_test
 import (
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing/iotest"
 	"time"
 
 	"github.com/tscolari/s3kup/backup"
@@ -16,45 +20,225 @@ import (
 var _ = Describe("Backuper", func() {
 	var backuper backup.Backuper
 	var s3Client *fakes.FakeS3Client
+	var streamed map[string][]byte
+
+	uploadedVersion := func() s3.Version {
+		stagedPath, _, _, labels, _ := s3Client.StoreReaderArgsForCall(0)
+		content := streamed[stagedPath]
+		_, version := s3Client.VersionPathArgsForCall(0)
+		_, path := s3Client.CopyArgsForCall(0)
+
+		return s3.Version{
+			BackupName:   "myfile",
+			Version:      version,
+			Path:         path,
+			LastModified: time.Now().Add(time.Hour),
+			Size:         uint64(len(content)),
+			Labels:       labels,
+		}
+	}
+
+	withoutLabels := func(versions s3.Versions) s3.Versions {
+		listed := s3.Versions{}
+		for _, version := range versions {
+			version.Labels = nil
+			listed = append(listed, version)
+		}
+		return listed
+	}
+
+	labelsOf := func(versions func() s3.Versions) func(string) (s3.Labels, error) {
+		return func(path string) (s3.Labels, error) {
+			for _, version := range versions() {
+				if version.Path == path {
+					return version.Labels, nil
+				}
+			}
+			return nil, errors.New("not found")
+		}
+	}
+
+	listReturnsWithUpload := func(versions s3.Versions) {
+		stored := func() s3.Versions {
+			if s3Client.CopyCallCount() == 0 {
+				return versions
+			}
+			return append(append(s3.Versions{}, versions...), uploadedVersion())
+		}
+
+		s3Client.ListStub = func(path string) (s3.Versions, error) {
+			return withoutLabels(stored()), nil
+		}
+		s3Client.LabelsStub = labelsOf(stored)
+	}
+
+	retentionFailure := func(err error) error {
+		var retentionErr *backup.RetentionError
+		Expect(errors.As(err, &retentionErr)).To(BeTrue())
+		Expect(err).To(MatchError(MatchRegexp("^Version '\\d{19}-[0-9a-f]{16}' was pushed, but the clean up of old versions failed: ")))
+		return retentionErr.Err
+	}
+
+	deletedPaths := func() []string {
+		paths := []string{}
+		for i := 0; i < s3Client.DeleteCallCount(); i++ {
+			if path := s3Client.DeleteArgsForCall(i); !strings.Contains(path, "/.s3kup/staging/") {
+				paths = append(paths, path)
+			}
+		}
+		return paths
+	}
 
 	BeforeEach(func() {
 		s3Client = new(fakes.FakeS3Client)
-		backuper = backup.New(s3Client, 3)
+		streamed = map[string][]byte{}
+		s3Client.StoreReaderStub = func(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error) {
+			content, err := ioutil.ReadAll(reader)
+			streamed[path] = content
+			return uint64(len(content)), err
+		}
+		s3Client.VersionPathStub = func(backupName, version string) (string, error) {
+			return backupName + "/" + version, nil
+		}
+		s3Client.SizeStub = func(path string) (uint64, error) {
+			for i := 0; i < s3Client.StoreWithLabelsCallCount(); i++ {
+				if storedPath, content, _ := s3Client.StoreWithLabelsArgsForCall(i); storedPath == path {
+					return uint64(len(content)), nil
+				}
+			}
+			if content, ok := streamed[path]; ok {
+				return uint64(len(content)), nil
+			}
+			return 0, errors.New("not found")
+		}
+		listReturnsWithUpload(s3.Versions{})
+		backuper = backup.New(s3Client, 3, backup.DeletionLimits{MaxDeletes: 5})
 	})
 
 	Describe("#Backup", func() {
 
 		It("timestamps the version inside the given filename", func() {
-			err := backuper.Backup("file", []byte("content"))
+			err := backuper.Backup("file", []byte("content"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			_, path := s3Client.CopyArgsForCall(0)
+			Expect(path).To(MatchRegexp(fmt.Sprintf("^%s/\\d{19}-[0-9a-f]{16}$", "file")))
+		})
+
+		It("uses the client's key layout for the version path", func() {
+			s3Client.VersionPathStub = func(backupName, version string) (string, error) {
+				return backupName + "/2015/03/" + version + ".gz", nil
+			}
+
+			err := backuper.Backup("file", []byte("content"), nil)
 			Expect(err).ToNot(HaveOccurred())
-			path, _ := s3Client.StoreArgsForCall(0)
-			Expect(path).To(MatchRegexp(fmt.Sprintf("^%s/\\d{19}$", "file")))
+			_, path := s3Client.CopyArgsForCall(0)
+			Expect(path).To(MatchRegexp("^file/2015/03/\\d{19}-[0-9a-f]{16}\\.gz$"))
+		})
+
+		It("uses a version newer than all the stored versions", func() {
+			future := time.Now().Add(time.Hour).UnixNano()
+			listReturnsWithUpload(s3.Versions{
+				s3.Version{BackupName: "myfile", Version: fmt.Sprintf("%d-0000000000000000", future), Path: "myfile/future"},
+			})
+
+			err := backuper.Backup("file", []byte("content"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			_, path := s3Client.CopyArgsForCall(0)
+			Expect(path).To(HavePrefix(fmt.Sprintf("file/%d-", future+1)))
+		})
+
+		It("uploads to the staging area and commits the version after verifying it", func() {
+			err := backuper.Backup("file", []byte("content"), nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, version := s3Client.VersionPathArgsForCall(0)
+			stagedPath, _, _, _, _ := s3Client.StoreReaderArgsForCall(0)
+			Expect(stagedPath).To(Equal(s3.StagingPath("file", version)))
+			Expect(s3Client.SizeArgsForCall(0)).To(Equal(stagedPath))
+
+			fromPath, toPath := s3Client.CopyArgsForCall(0)
+			Expect(fromPath).To(Equal(stagedPath))
+			Expect(toPath).To(Equal("file/" + version))
+			Expect(s3Client.DeleteArgsForCall(0)).To(Equal(stagedPath))
+		})
+
+		Context("when the staged upload has the wrong size", func() {
+			BeforeEach(func() {
+				s3Client.SizeReturns(3, nil)
+			})
+
+			It("doesn't commit the version and removes the staged upload", func() {
+				err := backuper.Backup("file", []byte("content"), nil)
+				stagedPath, _, _, _, _ := s3Client.StoreReaderArgsForCall(0)
+				Expect(err).To(MatchError(fmt.Sprintf("Uploaded object '%s' has 3 bytes, expected 7", stagedPath)))
+				Expect(s3Client.CopyCallCount()).To(Equal(0))
+				Expect(s3Client.DeleteCallCount()).To(Equal(1))
+				Expect(s3Client.DeleteArgsForCall(0)).To(Equal(stagedPath))
+			})
+		})
+
+		Context("when committing the version fails", func() {
+			BeforeEach(func() {
+				s3Client.CopyReturns(errors.New("failed to copy"))
+			})
+
+			It("returns the error and removes the staged upload", func() {
+				err := backuper.Backup("file", []byte("content"), nil)
+				Expect(err).To(MatchError("failed to copy"))
+				stagedPath, _, _, _, _ := s3Client.StoreReaderArgsForCall(0)
+				Expect(s3Client.DeleteCallCount()).To(Equal(1))
+				Expect(s3Client.DeleteArgsForCall(0)).To(Equal(stagedPath))
+				Expect(s3Client.ListCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the content is empty", func() {
+			It("refuses to push it", func() {
+				err := backuper.Backup("file", []byte{}, nil)
+				Expect(err).To(Equal(backup.ErrEmptyInput))
+				Expect(s3Client.StoreReaderCallCount()).To(Equal(0))
+				Expect(s3Client.DeleteCallCount()).To(Equal(0))
+			})
 		})
 
 		Context("when something fails", func() {
 
 			Context("when storing the file fails", func() {
 				It("returns back the error", func() {
-					s3Client.StoreReturns(errors.New("failed to store"))
-					err := backuper.Backup("file", []byte("content"))
+					s3Client.StoreReaderStub = nil
+					s3Client.StoreReaderReturns(0, errors.New("failed to store"))
+					err := backuper.Backup("file", []byte("content"), nil)
 					Expect(err).To(MatchError("failed to store"))
 				})
 			})
 
+			Context("when reading the input fails", func() {
+				It("returns the error and doesn't commit the version", func() {
+					input := io.MultiReader(strings.NewReader("content"), iotest.ErrReader(errors.New("failed to read")))
+					err := backuper.BackupReader("file", input, -1, nil, s3.Attributes{})
+					Expect(err).To(MatchError("failed to read"))
+					Expect(s3Client.CopyCallCount()).To(Equal(0))
+
+					stagedPath, _, _, _, _ := s3Client.StoreReaderArgsForCall(0)
+					Expect(s3Client.DeleteArgsForCall(0)).To(Equal(stagedPath))
+				})
+			})
+
 			Context("when listing the versions fails", func() {
 
 				BeforeEach(func() {
-					s3Client.StoreReturns(errors.New("Failed to list"))
+					s3Client.ListStub = nil
+					s3Client.ListReturns(nil, errors.New("Failed to list"))
 				})
 
 				It("returns back the error", func() {
-					err := backuper.Backup("file", []byte("content"))
-					Expect(err).To(MatchError("Failed to list"))
+					err := backuper.Backup("file", []byte("content"), nil)
+					Expect(retentionFailure(err)).To(MatchError("Failed to list"))
 				})
 
 				It("still stores the file", func() {
-					backuper.Backup("file", []byte("content"))
-					Expect(s3Client.StoreCallCount()).To(Equal(1))
+					backuper.Backup("file", []byte("content"), nil)
+					Expect(s3Client.StoreReaderCallCount()).To(Equal(1))
 				})
 			})
 
@@ -62,46 +246,35 @@ var _ = Describe("Backuper", func() {
 				BeforeEach(func() {
 					s3Client.DeleteReturns(errors.New("Failed to delete"))
 					versions := s3.Versions{
-						s3.Version{BackupName: "myfile", Version: 20010101},
-						s3.Version{BackupName: "myfile", Version: 20000101},
-						s3.Version{BackupName: "myfile", Version: 20020101},
-						s3.Version{BackupName: "myfile", Version: 20150101},
+						s3.Version{BackupName: "myfile", Version: "20010101"},
+						s3.Version{BackupName: "myfile", Version: "20000101"},
+						s3.Version{BackupName: "myfile", Version: "20020101"},
+						s3.Version{BackupName: "myfile", Version: "20150101"},
 					}
 
-					s3Client.ListReturns(versions, nil)
+					listReturnsWithUpload(versions)
 				})
 
 				It("returns back the error", func() {
-					err := backuper.Backup("file", []byte("content"))
-					Expect(err).To(MatchError("Failed to delete"))
+					err := backuper.Backup("file", []byte("content"), nil)
+					Expect(retentionFailure(err)).To(MatchError("Failed to delete"))
 				})
 
 				It("still store the file", func() {
-					backuper.Backup("file", []byte("content"))
-					Expect(s3Client.StoreCallCount()).To(Equal(1))
+					backuper.Backup("file", []byte("content"), nil)
+					Expect(s3Client.StoreReaderCallCount()).To(Equal(1))
 				})
 			})
 		})
 
 		Context("versions to keep", func() {
-			var storeCallsCount int
-			var deleteCallsCount int
-			var listCallsCount int
-
-			BeforeEach(func() {
-				storeCallsCount = 0
-				deleteCallsCount = 0
-				listCallsCount = 0
-			})
-
 			Context("when there is less versions than `versionsToKeep`", func() {
 				It("does not delete any previous version", func() {
-					s3Client.ListReturns(s3.Versions{}, nil)
 					s3Client.DeleteReturns(nil)
 
-					err := backuper.Backup("file", []byte("content"))
+					err := backuper.Backup("file", []byte("content"), nil)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(s3Client.DeleteCallCount()).To(Equal(0))
+					Expect(deletedPaths()).To(HaveLen(0))
 				})
 			})
 
@@ -109,18 +282,17 @@ var _ = Describe("Backuper", func() {
 				It("deletes the oldest version", func() {
 					baseTime := time.Now()
 					versions := s3.Versions{
-						s3.Version{BackupName: "myfile", Version: 20010101, Path: "myfile/20010101", LastModified: baseTime.Add(2 * time.Minute)},
-						s3.Version{BackupName: "myfile", Version: 20000101, Path: "myfile/20000101", LastModified: baseTime.Add(1 * time.Minute)},
-						s3.Version{BackupName: "myfile", Version: 20020101, Path: "myfile/20020101", LastModified: baseTime.Add(3 * time.Minute)},
-						s3.Version{BackupName: "myfile", Version: 20150101, Path: "myfile/20150101", LastModified: baseTime.Add(4 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "20010101", Path: "myfile/20010101", LastModified: baseTime.Add(2 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "20000101", Path: "myfile/20000101", LastModified: baseTime.Add(1 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "20020101", Path: "myfile/20020101", LastModified: baseTime.Add(3 * time.Minute)},
 					}
 
-					s3Client.ListReturns(versions, nil)
+					listReturnsWithUpload(versions)
 
-					err := backuper.Backup("file", []byte("content"))
+					err := backuper.Backup("file", []byte("content"), nil)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(s3Client.DeleteCallCount()).To(Equal(1))
-					deletedPath := s3Client.DeleteArgsForCall(0)
+					Expect(deletedPaths()).To(HaveLen(1))
+					deletedPath := deletedPaths()[0]
 					Expect(deletedPath).To(Equal("myfile/20000101"))
 				})
 			})
@@ -130,26 +302,443 @@ var _ = Describe("Backuper", func() {
 					baseTime := time.Now()
 
 					versions := s3.Versions{
-						s3.Version{BackupName: "myfile", Version: 20010101, Path: "myfile/20010101", LastModified: baseTime.Add(4 * time.Minute)},
-						s3.Version{BackupName: "myfile", Version: 20030101, Path: "myfile/20030101", LastModified: baseTime.Add(6 * time.Minute)},
-						s3.Version{BackupName: "myfile", Version: 20000101, Path: "myfile/20000101", LastModified: baseTime.Add(3 * time.Minute)},
-						s3.Version{BackupName: "myfile", Version: 20020101, Path: "myfile/20020101", LastModified: baseTime.Add(5 * time.Minute)},
-						s3.Version{BackupName: "myfile", Version: 19990101, Path: "myfile/19990101", LastModified: baseTime.Add(2 * time.Minute)},
-						s3.Version{BackupName: "myfile", Version: 20150101, Path: "myfile/20150101", LastModified: baseTime.Add(7 * time.Minute)},
-						s3.Version{BackupName: "myfile", Version: 19950101, Path: "myfile/19950101", LastModified: baseTime.Add(1 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "20010101", Path: "myfile/20010101", LastModified: baseTime.Add(4 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "20030101", Path: "myfile/20030101", LastModified: baseTime.Add(6 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "20000101", Path: "myfile/20000101", LastModified: baseTime.Add(3 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "20020101", Path: "myfile/20020101", LastModified: baseTime.Add(5 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "19990101", Path: "myfile/19990101", LastModified: baseTime.Add(2 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "19950101", Path: "myfile/19950101", LastModified: baseTime.Add(1 * time.Minute)},
 					}
 
+					listReturnsWithUpload(versions)
+
+					err := backuper.Backup("file", []byte("content"), nil)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deletedPaths()).To(HaveLen(4))
+					Expect(deletedPaths()[0]).To(Equal("myfile/19950101"))
+					Expect(deletedPaths()[1]).To(Equal("myfile/19990101"))
+					Expect(deletedPaths()[2]).To(Equal("myfile/20000101"))
+					Expect(deletedPaths()[3]).To(Equal("myfile/20010101"))
+				})
+			})
+
+			Context("when there are pinned versions", func() {
+				It("never deletes them and doesn't count them as kept versions", func() {
+					baseTime := time.Now()
+
+					versions := s3.Versions{
+						s3.Version{BackupName: "myfile", Version: "19950101", Path: "myfile/19950101", LastModified: baseTime.Add(1 * time.Minute), Pinned: true},
+						s3.Version{BackupName: "myfile", Version: "19990101", Path: "myfile/19990101", LastModified: baseTime.Add(2 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "20000101", Path: "myfile/20000101", LastModified: baseTime.Add(3 * time.Minute)},
+						s3.Version{BackupName: "myfile", Version: "20010101", Path: "myfile/20010101", LastModified: baseTime.Add(4 * time.Minute), Pinned: true},
+						s3.Version{BackupName: "myfile", Version: "20020101", Path: "myfile/20020101", LastModified: baseTime.Add(5 * time.Minute)},
+					}
+
+					listReturnsWithUpload(versions)
+
+					err := backuper.Backup("file", []byte("content"), nil)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deletedPaths()).To(HaveLen(1))
+					Expect(deletedPaths()[0]).To(Equal("myfile/19990101"))
+				})
+			})
+		})
+
+		Context("streaming the input", func() {
+			It("stores the content with its size and attributes", func() {
+				attributes := s3.Attributes{Mode: 0640, ModTime: time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)}
+				err := backuper.BackupReader("file", strings.NewReader("content"), 7, s3.Labels{"kind": "nightly"}, attributes)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(s3Client.StoreReaderCallCount()).To(Equal(1))
+				stagedPath, _, size, labels, storedAttributes := s3Client.StoreReaderArgsForCall(0)
+				Expect(stagedPath).To(ContainSubstring("file/.s3kup/staging/"))
+				Expect(streamed[stagedPath]).To(Equal([]byte("content")))
+				Expect(size).To(Equal(int64(7)))
+				Expect(labels).To(Equal(s3.Labels{"kind": "nightly"}))
+				Expect(storedAttributes).To(Equal(attributes))
+
+				from, _ := s3Client.CopyArgsForCall(0)
+				Expect(from).To(Equal(stagedPath))
+			})
+
+			It("verifies the staged upload against the streamed size when it isn't known ahead", func() {
+				s3Client.SizeReturns(3, nil)
+
+				err := backuper.BackupReader("file", strings.NewReader("content"), -1, nil, s3.Attributes{})
+				stagedPath, _, _, _, _ := s3Client.StoreReaderArgsForCall(0)
+				Expect(err).To(MatchError(fmt.Sprintf("Uploaded object '%s' has 3 bytes, expected 7", stagedPath)))
+				Expect(s3Client.CopyCallCount()).To(Equal(0))
+			})
+
+			It("refuses empty input", func() {
+				err := backuper.BackupReader("file", strings.NewReader(""), -1, nil, s3.Attributes{})
+				Expect(err).To(Equal(backup.ErrEmptyInput))
+				Expect(s3Client.StoreReaderCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("labels", func() {
+			It("stores the labels with the version", func() {
+				err := backuper.Backup("file", []byte("content"), s3.Labels{"kind": "nightly"})
+				Expect(err).ToNot(HaveOccurred())
+				_, _, _, labels, _ := s3Client.StoreReaderArgsForCall(0)
+				Expect(labels).To(Equal(s3.Labels{"kind": "nightly"}))
+			})
+
+			It("only applies `versionsToKeep` to versions with the same labels", func() {
+				baseTime := time.Now()
+				nightly := s3.Labels{"kind": "nightly"}
+				preDeploy := s3.Labels{"kind": "pre-deploy"}
+
+				versions := s3.Versions{
+					s3.Version{BackupName: "myfile", Version: "19950101", Path: "myfile/19950101", LastModified: baseTime.Add(1 * time.Minute), Labels: preDeploy},
+					s3.Version{BackupName: "myfile", Version: "19990101", Path: "myfile/19990101", LastModified: baseTime.Add(2 * time.Minute), Labels: nightly},
+					s3.Version{BackupName: "myfile", Version: "20000101", Path: "myfile/20000101", LastModified: baseTime.Add(3 * time.Minute), Labels: nightly},
+					s3.Version{BackupName: "myfile", Version: "20010101", Path: "myfile/20010101", LastModified: baseTime.Add(4 * time.Minute)},
+					s3.Version{BackupName: "myfile", Version: "20020101", Path: "myfile/20020101", LastModified: baseTime.Add(5 * time.Minute), Labels: nightly},
+					s3.Version{BackupName: "myfile", Version: "20030101", Path: "myfile/20030101", LastModified: baseTime.Add(6 * time.Minute), Labels: preDeploy},
+				}
+
+				listReturnsWithUpload(versions)
+
+				err := backuper.Backup("file", []byte("content"), s3.Labels{"kind": "nightly"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deletedPaths()).To(HaveLen(1))
+				Expect(deletedPaths()[0]).To(Equal("myfile/19990101"))
+			})
+
+			It("only applies `versionsToKeep` to unlabeled versions when pushing without labels", func() {
+				baseTime := time.Now()
+				nightly := s3.Labels{"kind": "nightly"}
+
+				versions := s3.Versions{
+					s3.Version{BackupName: "myfile", Version: "19950101", Path: "myfile/19950101", LastModified: baseTime.Add(1 * time.Minute), Labels: nightly},
+					s3.Version{BackupName: "myfile", Version: "19990101", Path: "myfile/19990101", LastModified: baseTime.Add(2 * time.Minute)},
+					s3.Version{BackupName: "myfile", Version: "20000101", Path: "myfile/20000101", LastModified: baseTime.Add(3 * time.Minute), Labels: nightly},
+					s3.Version{BackupName: "myfile", Version: "20010101", Path: "myfile/20010101", LastModified: baseTime.Add(4 * time.Minute)},
+					s3.Version{BackupName: "myfile", Version: "20020101", Path: "myfile/20020101", LastModified: baseTime.Add(5 * time.Minute)},
+				}
+
+				listReturnsWithUpload(versions)
+
+				err := backuper.Backup("file", []byte("content"), nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deletedPaths()).To(HaveLen(1))
+				Expect(deletedPaths()[0]).To(Equal("myfile/19990101"))
+			})
+
+			Context("with keep rules", func() {
+				var versions s3.Versions
+
+				BeforeEach(func() {
+					baseTime := time.Now()
+					nightly := s3.Labels{"kind": "nightly"}
+					preDeploy := s3.Labels{"kind": "pre-deploy"}
+					manual := s3.Labels{"kind": "manual"}
+
+					versions = s3.Versions{
+						s3.Version{BackupName: "myfile", Version: "19950101", Path: "myfile/19950101", LastModified: baseTime.Add(1 * time.Minute), Labels: preDeploy},
+						s3.Version{BackupName: "myfile", Version: "19990101", Path: "myfile/19990101", LastModified: baseTime.Add(2 * time.Minute), Labels: nightly},
+						s3.Version{BackupName: "myfile", Version: "20000101", Path: "myfile/20000101", LastModified: baseTime.Add(3 * time.Minute), Labels: nightly},
+						s3.Version{BackupName: "myfile", Version: "20010101", Path: "myfile/20010101", LastModified: baseTime.Add(4 * time.Minute), Labels: manual},
+						s3.Version{BackupName: "myfile", Version: "20020101", Path: "myfile/20020101", LastModified: baseTime.Add(5 * time.Minute), Labels: nightly},
+						s3.Version{BackupName: "myfile", Version: "20030101", Path: "myfile/20030101", LastModified: baseTime.Add(6 * time.Minute), Labels: preDeploy},
+						s3.Version{BackupName: "myfile", Version: "20040101", Path: "myfile/20040101", LastModified: baseTime.Add(7 * time.Minute), Labels: manual},
+					}
+				})
+
+				It("keeps the given number of versions of each label, whatever the pushed labels", func() {
+					listReturnsWithUpload(versions)
+					backuper = backuper.WithKeepRules([]backup.KeepRule{
+						{Labels: s3.Labels{"kind": "nightly"}, Versions: 2},
+						{Labels: s3.Labels{"kind": "pre-deploy"}, Versions: 1},
+					})
+
+					err := backuper.Backup("file", []byte("content"), s3.Labels{"kind": "manual"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deletedPaths()).To(ConsistOf("myfile/19950101", "myfile/19990101"))
+				})
+
+				It("doesn't apply `versionsToKeep` when a rule covers the pushed labels", func() {
+					listReturnsWithUpload(versions)
+					backuper = backuper.WithKeepRules([]backup.KeepRule{
+						{Labels: s3.Labels{"kind": "nightly"}, Versions: 4},
+					})
+
+					err := backuper.Backup("file", []byte("content"), s3.Labels{"kind": "nightly"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deletedPaths()).To(BeEmpty())
+				})
+
+				It("only deletes the versions that every matching rule would delete", func() {
+					listReturnsWithUpload(versions)
+					backuper = backuper.WithKeepRules([]backup.KeepRule{
+						{Labels: s3.Labels{"kind": "nightly"}, Versions: 1},
+						{Labels: s3.Labels{}, Versions: 5},
+					})
+
+					err := backuper.Backup("file", []byte("content"), s3.Labels{"kind": "nightly"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deletedPaths()).To(ConsistOf("myfile/19950101", "myfile/19990101", "myfile/20000101"))
+				})
+
+				It("doesn't read the labels when there are fewer versions than any rule keeps", func() {
+					listReturnsWithUpload(versions[:2])
+					backuper = backuper.WithKeepRules([]backup.KeepRule{
+						{Labels: s3.Labels{"kind": "nightly"}, Versions: 5},
+					})
+
+					err := backuper.Backup("file", []byte("content"), s3.Labels{"kind": "nightly"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(s3Client.LabelsCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("deletion limits", func() {
+			var versions s3.Versions
+
+			BeforeEach(func() {
+				baseTime := time.Now()
+				versions = s3.Versions{}
+				for i := 0; i < 8; i++ {
+					version := fmt.Sprintf("%d", 20000101+i)
+					versions = append(versions, s3.Version{
+						BackupName:   "myfile",
+						Version:      version,
+						Path:         "myfile/" + version,
+						LastModified: baseTime.Add(time.Duration(i) * time.Minute),
+					})
+				}
+			})
+
+			Context("when more versions than `MaxDeletes` would be deleted", func() {
+				BeforeEach(func() {
+					listReturnsWithUpload(versions)
+				})
+
+				It("refuses to delete any version", func() {
+					err := backuper.Backup("file", []byte("content"), nil)
+					Expect(retentionFailure(err)).To(MatchError("Refused to delete 6 old versions, the limit is 5 per run (use --force to override)"))
+					Expect(deletedPaths()).To(HaveLen(0))
+				})
+
+				It("deletes them when forced", func() {
+					backuper = backup.New(s3Client, 3, backup.DeletionLimits{MaxDeletes: 5, Force: true})
+
+					err := backuper.Backup("file", []byte("content"), nil)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deletedPaths()).To(HaveLen(6))
+				})
+
+				It("deletes them when there is no limit", func() {
+					backuper = backup.New(s3Client, 3, backup.DeletionLimits{})
+
+					err := backuper.Backup("file", []byte("content"), nil)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deletedPaths()).To(HaveLen(6))
+				})
+			})
+
+			Context("when the uploaded version can't be listed back", func() {
+				BeforeEach(func() {
+					s3Client.ListStub = nil
 					s3Client.ListReturns(versions, nil)
+					s3Client.LabelsStub = nil
+				})
 
-					err := backuper.Backup("file", []byte("content"))
+				It("refuses to delete any version", func() {
+					err := backuper.Backup("file", []byte("content"), nil)
+					Expect(retentionFailure(err)).To(MatchError(MatchRegexp("^Uploaded version '\\d{19}-[0-9a-f]{16}' could not be listed back$")))
+					Expect(deletedPaths()).To(HaveLen(0))
+				})
+
+				It("deletes old versions when forced", func() {
+					backuper = backup.New(s3Client, 3, backup.DeletionLimits{MaxDeletes: 5, Force: true})
+
+					err := backuper.Backup("file", []byte("content"), nil)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(s3Client.DeleteCallCount()).To(Equal(4))
-					Expect(s3Client.DeleteArgsForCall(0)).To(Equal("myfile/19950101"))
-					Expect(s3Client.DeleteArgsForCall(1)).To(Equal("myfile/19990101"))
-					Expect(s3Client.DeleteArgsForCall(2)).To(Equal("myfile/20000101"))
-					Expect(s3Client.DeleteArgsForCall(3)).To(Equal("myfile/20010101"))
+					Expect(deletedPaths()).To(HaveLen(5))
 				})
 			})
+
+			Context("when the uploaded version has the wrong size", func() {
+				BeforeEach(func() {
+					s3Client.ListStub = func(path string) (s3.Versions, error) {
+						if s3Client.CopyCallCount() == 0 {
+							return s3.Versions{}, nil
+						}
+						version := uploadedVersion()
+						version.Size = 1
+						return s3.Versions{version}, nil
+					}
+				})
+
+				It("refuses to delete any version", func() {
+					err := backuper.Backup("file", []byte("content"), nil)
+					Expect(retentionFailure(err)).To(MatchError(MatchRegexp("^Uploaded version '\\d{19}-[0-9a-f]{16}' has 1 bytes, expected 7$")))
+					Expect(deletedPaths()).To(HaveLen(0))
+				})
+			})
+		})
+	})
+
+	Describe("#BackupBundle", func() {
+		var parts []backup.Part
+
+		bundleVersion := func() s3.Version {
+			path, content, labels := s3Client.StoreWithLabelsArgsForCall(s3Client.StoreWithLabelsCallCount() - 1)
+			_, version := s3Client.VersionPathArgsForCall(0)
+
+			return s3.Version{BackupName: "file", Version: version, Path: path, Size: uint64(len(content)), Labels: labels}
+		}
+
+		listBundles := func(versions func() s3.Versions) {
+			committed := func() s3.Versions {
+				if s3Client.VersionPathCallCount() == 0 {
+					return s3.Versions{}
+				}
+				return versions()
+			}
+
+			s3Client.ListStub = func(path string) (s3.Versions, error) {
+				return withoutLabels(committed()), nil
+			}
+			s3Client.LabelsStub = labelsOf(committed)
+		}
+
+		BeforeEach(func() {
+			parts = []backup.Part{
+				{Name: "db", Reader: strings.NewReader("db content"), Size: 10},
+				{Name: "uploads", Reader: strings.NewReader("uploads content"), Size: -1},
+			}
+
+			listBundles(func() s3.Versions {
+				return s3.Versions{bundleVersion()}
+			})
+		})
+
+		It("streams every part before committing the manifest as the version", func() {
+			err := backuper.BackupBundle("file", parts, s3.Labels{"kind": "nightly"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s3Client.StoreReaderCallCount()).To(Equal(2))
+			Expect(s3Client.StoreWithLabelsCallCount()).To(Equal(1))
+
+			_, version := s3Client.VersionPathArgsForCall(0)
+			path, _, size, labels, _ := s3Client.StoreReaderArgsForCall(0)
+			Expect(path).To(Equal(s3.PartPath("file", version, "db")))
+			Expect(streamed[path]).To(Equal([]byte("db content")))
+			Expect(size).To(Equal(int64(10)))
+			Expect(labels).To(BeNil())
+
+			path, _, size, _, _ = s3Client.StoreReaderArgsForCall(1)
+			Expect(path).To(Equal(s3.PartPath("file", version, "uploads")))
+			Expect(streamed[path]).To(Equal([]byte("uploads content")))
+			Expect(size).To(Equal(int64(-1)))
+
+			path, content, labels := s3Client.StoreWithLabelsArgsForCall(0)
+			Expect(path).To(Equal("file/" + version))
+			Expect(labels).To(Equal(s3.Labels{"kind": "nightly"}))
+
+			manifest, err := s3.ParseManifest(content)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Parts).To(Equal([]s3.ManifestPart{
+				{Name: "db", Path: s3.PartPath("file", version, "db"), Size: 10},
+				{Name: "uploads", Path: s3.PartPath("file", version, "uploads"), Size: 15},
+			}))
+		})
+
+		It("deletes the parts of old bundles", func() {
+			listBundles(func() s3.Versions {
+				return s3.Versions{
+					s3.Version{BackupName: "file", Version: "1", Path: "file/1", PartPaths: []string{"file/.s3kup/parts/1/db", "file/.s3kup/parts/1/uploads"}},
+					s3.Version{BackupName: "file", Version: "2", Path: "file/2"},
+					s3.Version{BackupName: "file", Version: "3", Path: "file/3"},
+					bundleVersion(),
+				}
+			})
+
+			err := backuper.BackupBundle("file", parts, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s3Client.DeleteCallCount()).To(Equal(3))
+			Expect(s3Client.DeleteArgsForCall(0)).To(Equal("file/1"))
+			Expect(s3Client.DeleteArgsForCall(1)).To(Equal("file/.s3kup/parts/1/db"))
+			Expect(s3Client.DeleteArgsForCall(2)).To(Equal("file/.s3kup/parts/1/uploads"))
 		})
+
+		Context("when a part fails to upload", func() {
+			BeforeEach(func() {
+				s3Client.StoreReaderStub = func(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error) {
+					if s3Client.StoreReaderCallCount() == 2 {
+						return 0, errors.New("failed to store")
+					}
+					content, err := ioutil.ReadAll(reader)
+					streamed[path] = content
+					return uint64(len(content)), err
+				}
+			})
+
+			It("doesn't commit the manifest and removes the uploaded parts", func() {
+				err := backuper.BackupBundle("file", parts, nil)
+				Expect(err).To(MatchError("failed to store"))
+				Expect(s3Client.StoreReaderCallCount()).To(Equal(2))
+				Expect(s3Client.StoreWithLabelsCallCount()).To(Equal(0))
+				Expect(s3Client.VersionPathCallCount()).To(Equal(0))
+
+				uploadedPart, _, _, _, _ := s3Client.StoreReaderArgsForCall(0)
+				Expect(s3Client.DeleteCallCount()).To(Equal(1))
+				Expect(s3Client.DeleteArgsForCall(0)).To(Equal(uploadedPart))
+			})
+		})
+
+		Context("when a part has the wrong size", func() {
+			BeforeEach(func() {
+				s3Client.SizeStub = nil
+				s3Client.SizeReturns(3, nil)
+			})
+
+			It("doesn't commit the manifest and removes the uploaded part", func() {
+				err := backuper.BackupBundle("file", parts, nil)
+				uploadedPart, _, _, _, _ := s3Client.StoreReaderArgsForCall(0)
+				Expect(err).To(MatchError(fmt.Sprintf("Uploaded object '%s' has 3 bytes, expected 10", uploadedPart)))
+				Expect(s3Client.StoreReaderCallCount()).To(Equal(1))
+				Expect(s3Client.DeleteCallCount()).To(Equal(1))
+				Expect(s3Client.DeleteArgsForCall(0)).To(Equal(uploadedPart))
+			})
+		})
+
+		It("fails for invalid or repeated part names", func() {
+			err := backuper.BackupBundle("file", []backup.Part{{Name: "my/db"}}, nil)
+			Expect(err).To(MatchError("Invalid part name 'my/db'. It can only contain letters, numbers, '.', '_' and '-'"))
+
+			err = backuper.BackupBundle("file", []backup.Part{{Name: "db"}, {Name: "db"}}, nil)
+			Expect(err).To(MatchError("The part 'db' was given more than once"))
+
+			err = backuper.BackupBundle("file", []backup.Part{}, nil)
+			Expect(err).To(MatchError("A bundle needs at least one part"))
+			Expect(s3Client.StoreReaderCallCount()).To(Equal(0))
+		})
+	})
+})
+
+var _ = Describe("ParseKeepRules", func() {
+	It("parses the label and the number of versions", func() {
+		rules, err := backup.ParseKeepRules([]string{"kind=nightly=7", "env=a=b=3"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rules).To(Equal([]backup.KeepRule{
+			{Labels: s3.Labels{"kind": "nightly"}, Versions: 7},
+			{Labels: s3.Labels{"env": "a=b"}, Versions: 3},
+		}))
+	})
+
+	It("fails for rules without a label", func() {
+		_, err := backup.ParseKeepRules([]string{"nightly=7"})
+		Expect(err).To(MatchError("Invalid keep rule 'nightly=7'. It must be in the format key=value=N"))
+	})
+
+	It("fails for rules without a valid number of versions", func() {
+		_, err := backup.ParseKeepRules([]string{"kind=nightly=0"})
+		Expect(err).To(MatchError("Invalid keep rule 'kind=nightly=0'. The number of versions must be 1 or greater"))
 	})
 })
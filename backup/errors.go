@@ -0,0 +1,21 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrEmptyInput = errors.New("The input is empty. Nothing was pushed")
+
+type RetentionError struct {
+	Version string
+	Err     error
+}
+
+func (e *RetentionError) Error() string {
+	return fmt.Sprintf("Version '%s' was pushed, but the clean up of old versions failed: %s", e.Version, e.Err)
+}
+
+func (e *RetentionError) Unwrap() error {
+	return e.Err
+}
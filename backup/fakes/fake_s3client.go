@@ -2,6 +2,7 @@
 package fakes
 
 import (
+	"io"
 	"sync"
 
 	"github.com/tscolari/s3kup/backup"
@@ -9,15 +10,29 @@ import (
 )
 
 type FakeS3Client struct {
-	StoreStub        func(path string, content []byte) error
-	storeMutex       sync.RWMutex
-	storeArgsForCall []struct {
+	StoreWithLabelsStub        func(path string, content []byte, labels s3.Labels) error
+	storeWithLabelsMutex       sync.RWMutex
+	storeWithLabelsArgsForCall []struct {
 		path    string
 		content []byte
+		labels  s3.Labels
 	}
-	storeReturns struct {
+	storeWithLabelsReturns struct {
 		result1 error
 	}
+	StoreReaderStub        func(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error)
+	storeReaderMutex       sync.RWMutex
+	storeReaderArgsForCall []struct {
+		path       string
+		reader     io.Reader
+		size       int64
+		labels     s3.Labels
+		attributes s3.Attributes
+	}
+	storeReaderReturns struct {
+		result1 uint64
+		result2 error
+	}
 	ListStub        func(path string) (versions s3.Versions, err error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
@@ -27,6 +42,33 @@ type FakeS3Client struct {
 		result1 s3.Versions
 		result2 error
 	}
+	LabelsStub        func(path string) (s3.Labels, error)
+	labelsMutex       sync.RWMutex
+	labelsArgsForCall []struct {
+		path string
+	}
+	labelsReturns struct {
+		result1 s3.Labels
+		result2 error
+	}
+	SizeStub        func(path string) (uint64, error)
+	sizeMutex       sync.RWMutex
+	sizeArgsForCall []struct {
+		path string
+	}
+	sizeReturns struct {
+		result1 uint64
+		result2 error
+	}
+	CopyStub        func(fromPath, toPath string) error
+	copyMutex       sync.RWMutex
+	copyArgsForCall []struct {
+		fromPath string
+		toPath   string
+	}
+	copyReturns struct {
+		result1 error
+	}
 	DeleteStub        func(path string) error
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
@@ -35,41 +77,89 @@ type FakeS3Client struct {
 	deleteReturns struct {
 		result1 error
 	}
+	VersionPathStub        func(backupName, version string) (string, error)
+	versionPathMutex       sync.RWMutex
+	versionPathArgsForCall []struct {
+		backupName string
+		version    string
+	}
+	versionPathReturns struct {
+		result1 string
+		result2 error
+	}
 }
 
-func (fake *FakeS3Client) Store(path string, content []byte) error {
-	fake.storeMutex.Lock()
-	fake.storeArgsForCall = append(fake.storeArgsForCall, struct {
+func (fake *FakeS3Client) StoreWithLabels(path string, content []byte, labels s3.Labels) error {
+	fake.storeWithLabelsMutex.Lock()
+	fake.storeWithLabelsArgsForCall = append(fake.storeWithLabelsArgsForCall, struct {
 		path    string
 		content []byte
-	}{path, content})
-	fake.storeMutex.Unlock()
-	if fake.StoreStub != nil {
-		return fake.StoreStub(path, content)
+		labels  s3.Labels
+	}{path, content, labels})
+	fake.storeWithLabelsMutex.Unlock()
+	if fake.StoreWithLabelsStub != nil {
+		return fake.StoreWithLabelsStub(path, content, labels)
 	} else {
-		return fake.storeReturns.result1
+		return fake.storeWithLabelsReturns.result1
 	}
 }
 
-func (fake *FakeS3Client) StoreCallCount() int {
-	fake.storeMutex.RLock()
-	defer fake.storeMutex.RUnlock()
-	return len(fake.storeArgsForCall)
+func (fake *FakeS3Client) StoreWithLabelsCallCount() int {
+	fake.storeWithLabelsMutex.RLock()
+	defer fake.storeWithLabelsMutex.RUnlock()
+	return len(fake.storeWithLabelsArgsForCall)
 }
 
-func (fake *FakeS3Client) StoreArgsForCall(i int) (string, []byte) {
-	fake.storeMutex.RLock()
-	defer fake.storeMutex.RUnlock()
-	return fake.storeArgsForCall[i].path, fake.storeArgsForCall[i].content
+func (fake *FakeS3Client) StoreWithLabelsArgsForCall(i int) (string, []byte, s3.Labels) {
+	fake.storeWithLabelsMutex.RLock()
+	defer fake.storeWithLabelsMutex.RUnlock()
+	return fake.storeWithLabelsArgsForCall[i].path, fake.storeWithLabelsArgsForCall[i].content, fake.storeWithLabelsArgsForCall[i].labels
 }
 
-func (fake *FakeS3Client) StoreReturns(result1 error) {
-	fake.StoreStub = nil
-	fake.storeReturns = struct {
+func (fake *FakeS3Client) StoreWithLabelsReturns(result1 error) {
+	fake.StoreWithLabelsStub = nil
+	fake.storeWithLabelsReturns = struct {
 		result1 error
 	}{result1}
 }
 
+func (fake *FakeS3Client) StoreReader(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error) {
+	fake.storeReaderMutex.Lock()
+	fake.storeReaderArgsForCall = append(fake.storeReaderArgsForCall, struct {
+		path       string
+		reader     io.Reader
+		size       int64
+		labels     s3.Labels
+		attributes s3.Attributes
+	}{path, reader, size, labels, attributes})
+	fake.storeReaderMutex.Unlock()
+	if fake.StoreReaderStub != nil {
+		return fake.StoreReaderStub(path, reader, size, labels, attributes)
+	} else {
+		return fake.storeReaderReturns.result1, fake.storeReaderReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) StoreReaderCallCount() int {
+	fake.storeReaderMutex.RLock()
+	defer fake.storeReaderMutex.RUnlock()
+	return len(fake.storeReaderArgsForCall)
+}
+
+func (fake *FakeS3Client) StoreReaderArgsForCall(i int) (string, io.Reader, int64, s3.Labels, s3.Attributes) {
+	fake.storeReaderMutex.RLock()
+	defer fake.storeReaderMutex.RUnlock()
+	return fake.storeReaderArgsForCall[i].path, fake.storeReaderArgsForCall[i].reader, fake.storeReaderArgsForCall[i].size, fake.storeReaderArgsForCall[i].labels, fake.storeReaderArgsForCall[i].attributes
+}
+
+func (fake *FakeS3Client) StoreReaderReturns(result1 uint64, result2 error) {
+	fake.StoreReaderStub = nil
+	fake.storeReaderReturns = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeS3Client) List(path string) (versions s3.Versions, err error) {
 	fake.listMutex.Lock()
 	fake.listArgsForCall = append(fake.listArgsForCall, struct {
@@ -103,6 +193,105 @@ func (fake *FakeS3Client) ListReturns(result1 s3.Versions, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeS3Client) Labels(path string) (s3.Labels, error) {
+	fake.labelsMutex.Lock()
+	fake.labelsArgsForCall = append(fake.labelsArgsForCall, struct {
+		path string
+	}{path})
+	fake.labelsMutex.Unlock()
+	if fake.LabelsStub != nil {
+		return fake.LabelsStub(path)
+	} else {
+		return fake.labelsReturns.result1, fake.labelsReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) LabelsCallCount() int {
+	fake.labelsMutex.RLock()
+	defer fake.labelsMutex.RUnlock()
+	return len(fake.labelsArgsForCall)
+}
+
+func (fake *FakeS3Client) LabelsArgsForCall(i int) string {
+	fake.labelsMutex.RLock()
+	defer fake.labelsMutex.RUnlock()
+	return fake.labelsArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) LabelsReturns(result1 s3.Labels, result2 error) {
+	fake.LabelsStub = nil
+	fake.labelsReturns = struct {
+		result1 s3.Labels
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) Size(path string) (uint64, error) {
+	fake.sizeMutex.Lock()
+	fake.sizeArgsForCall = append(fake.sizeArgsForCall, struct {
+		path string
+	}{path})
+	fake.sizeMutex.Unlock()
+	if fake.SizeStub != nil {
+		return fake.SizeStub(path)
+	} else {
+		return fake.sizeReturns.result1, fake.sizeReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) SizeCallCount() int {
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
+	return len(fake.sizeArgsForCall)
+}
+
+func (fake *FakeS3Client) SizeArgsForCall(i int) string {
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
+	return fake.sizeArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) SizeReturns(result1 uint64, result2 error) {
+	fake.SizeStub = nil
+	fake.sizeReturns = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) Copy(fromPath, toPath string) error {
+	fake.copyMutex.Lock()
+	fake.copyArgsForCall = append(fake.copyArgsForCall, struct {
+		fromPath string
+		toPath   string
+	}{fromPath, toPath})
+	fake.copyMutex.Unlock()
+	if fake.CopyStub != nil {
+		return fake.CopyStub(fromPath, toPath)
+	} else {
+		return fake.copyReturns.result1
+	}
+}
+
+func (fake *FakeS3Client) CopyCallCount() int {
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
+	return len(fake.copyArgsForCall)
+}
+
+func (fake *FakeS3Client) CopyArgsForCall(i int) (string, string) {
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
+	return fake.copyArgsForCall[i].fromPath, fake.copyArgsForCall[i].toPath
+}
+
+func (fake *FakeS3Client) CopyReturns(result1 error) {
+	fake.CopyStub = nil
+	fake.copyReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeS3Client) Delete(path string) error {
 	fake.deleteMutex.Lock()
 	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
@@ -135,4 +324,38 @@ func (fake *FakeS3Client) DeleteReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeS3Client) VersionPath(backupName, version string) (string, error) {
+	fake.versionPathMutex.Lock()
+	fake.versionPathArgsForCall = append(fake.versionPathArgsForCall, struct {
+		backupName string
+		version    string
+	}{backupName, version})
+	fake.versionPathMutex.Unlock()
+	if fake.VersionPathStub != nil {
+		return fake.VersionPathStub(backupName, version)
+	} else {
+		return fake.versionPathReturns.result1, fake.versionPathReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) VersionPathCallCount() int {
+	fake.versionPathMutex.RLock()
+	defer fake.versionPathMutex.RUnlock()
+	return len(fake.versionPathArgsForCall)
+}
+
+func (fake *FakeS3Client) VersionPathArgsForCall(i int) (string, string) {
+	fake.versionPathMutex.RLock()
+	defer fake.versionPathMutex.RUnlock()
+	return fake.versionPathArgsForCall[i].backupName, fake.versionPathArgsForCall[i].version
+}
+
+func (fake *FakeS3Client) VersionPathReturns(result1 string, result2 error) {
+	fake.VersionPathStub = nil
+	fake.versionPathReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 var _ backup.S3Client = new(FakeS3Client)
@@ -0,0 +1,69 @@
+package cleanup
+
+import (
+	"time"
+
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type Cleaner struct {
+	s3 S3Client
+}
+
+type S3Client interface {
+	ListUncommitted(path string) (objects []s3.Object, err error)
+	ListIncompleteUploads(path string) (uploads []s3.Upload, err error)
+	Delete(path string) error
+	AbortUpload(path, uploadID string) error
+}
+
+func New(client S3Client) Cleaner {
+	return Cleaner{
+		s3: client,
+	}
+}
+
+func (c Cleaner) Clean(backupName string, olderThan time.Time) (int, error) {
+	log.Info("Looking for stale uploads of", backupName)
+	objects, err := c.s3.ListUncommitted(backupName)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, object := range objects {
+		if !object.LastModified.Before(olderThan) {
+			log.Info(" -- keeping recent upload:", object.Path)
+			continue
+		}
+
+		err = c.s3.Delete(object.Path)
+		if err != nil {
+			return deleted, err
+		}
+		log.Info(" -- deleted:", object.Path)
+		deleted++
+	}
+
+	uploads, err := c.s3.ListIncompleteUploads(backupName)
+	if err != nil {
+		return deleted, err
+	}
+
+	for _, upload := range uploads {
+		if !upload.Initiated.Before(olderThan) {
+			log.Info(" -- keeping recent multipart upload:", upload.Path)
+			continue
+		}
+
+		err = c.s3.AbortUpload(upload.Path, upload.UploadID)
+		if err != nil {
+			return deleted, err
+		}
+		log.Info(" -- aborted multipart upload:", upload.Path)
+		deleted++
+	}
+
+	return deleted, nil
+}
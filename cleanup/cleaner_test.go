@@ -0,0 +1,84 @@
+package cleanup_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tscolari/s3kup/cleanup"
+	"github.com/tscolari/s3kup/cleanup/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cleaner", func() {
+	var cleaner cleanup.Cleaner
+	var s3Client *fakes.FakeS3Client
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Now()
+		s3Client = new(fakes.FakeS3Client)
+		s3Client.ListUncommittedReturns([]s3.Object{
+			s3.Object{Path: "my-backup/.s3kup/staging/1", LastModified: now.Add(-48 * time.Hour)},
+			s3.Object{Path: "my-backup/.s3kup/staging/2", LastModified: now.Add(-1 * time.Minute)},
+			s3.Object{Path: "my-backup/.s3kup/parts/3/db", LastModified: now.Add(-25 * time.Hour)},
+		}, nil)
+		s3Client.ListIncompleteUploadsReturns([]s3.Upload{
+			{Path: "my-backup/.s3kup/staging/4", UploadID: "upload-4", Initiated: now.Add(-30 * time.Hour)},
+			{Path: "my-backup/.s3kup/parts/5/db", UploadID: "upload-5", Initiated: now.Add(-1 * time.Minute)},
+		}, nil)
+
+		cleaner = cleanup.New(s3Client)
+	})
+
+	It("lists the uncommitted objects of the backup", func() {
+		cleaner.Clean("my-backup", now)
+		Expect(s3Client.ListUncommittedArgsForCall(0)).To(Equal("my-backup"))
+		Expect(s3Client.ListIncompleteUploadsArgsForCall(0)).To(Equal("my-backup"))
+	})
+
+	It("deletes the uncommitted objects older than the given time", func() {
+		deleted, err := cleaner.Clean("my-backup", now.Add(-24*time.Hour))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(3))
+		Expect(s3Client.DeleteCallCount()).To(Equal(2))
+		Expect(s3Client.DeleteArgsForCall(0)).To(Equal("my-backup/.s3kup/staging/1"))
+		Expect(s3Client.DeleteArgsForCall(1)).To(Equal("my-backup/.s3kup/parts/3/db"))
+	})
+
+	It("aborts the incomplete multipart uploads older than the given time", func() {
+		_, err := cleaner.Clean("my-backup", now.Add(-24*time.Hour))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s3Client.AbortUploadCallCount()).To(Equal(1))
+		path, uploadID := s3Client.AbortUploadArgsForCall(0)
+		Expect(path).To(Equal("my-backup/.s3kup/staging/4"))
+		Expect(uploadID).To(Equal("upload-4"))
+	})
+
+	Context("when listing fails", func() {
+		It("forwards the error", func() {
+			s3Client.ListUncommittedReturns(nil, errors.New("failed to list"))
+
+			_, err := cleaner.Clean("my-backup", now)
+			Expect(err).To(MatchError("failed to list"))
+		})
+
+		It("forwards the error of listing the uploads", func() {
+			s3Client.ListIncompleteUploadsReturns(nil, errors.New("failed to list uploads"))
+
+			_, err := cleaner.Clean("my-backup", now)
+			Expect(err).To(MatchError("failed to list uploads"))
+		})
+	})
+
+	Context("when deleting fails", func() {
+		It("forwards the error", func() {
+			s3Client.DeleteReturns(errors.New("failed to delete"))
+
+			_, err := cleaner.Clean("my-backup", now)
+			Expect(err).To(MatchError("failed to delete"))
+		})
+	})
+})
@@ -0,0 +1,180 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/tscolari/s3kup/cleanup"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type FakeS3Client struct {
+	ListUncommittedStub        func(path string) (objects []s3.Object, err error)
+	listUncommittedMutex       sync.RWMutex
+	listUncommittedArgsForCall []struct {
+		path string
+	}
+	listUncommittedReturns struct {
+		result1 []s3.Object
+		result2 error
+	}
+	ListIncompleteUploadsStub        func(path string) (uploads []s3.Upload, err error)
+	listIncompleteUploadsMutex       sync.RWMutex
+	listIncompleteUploadsArgsForCall []struct {
+		path string
+	}
+	listIncompleteUploadsReturns struct {
+		result1 []s3.Upload
+		result2 error
+	}
+	DeleteStub        func(path string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		path string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+	AbortUploadStub        func(path, uploadID string) error
+	abortUploadMutex       sync.RWMutex
+	abortUploadArgsForCall []struct {
+		path     string
+		uploadID string
+	}
+	abortUploadReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeS3Client) ListUncommitted(path string) (objects []s3.Object, err error) {
+	fake.listUncommittedMutex.Lock()
+	fake.listUncommittedArgsForCall = append(fake.listUncommittedArgsForCall, struct {
+		path string
+	}{path})
+	fake.listUncommittedMutex.Unlock()
+	if fake.ListUncommittedStub != nil {
+		return fake.ListUncommittedStub(path)
+	} else {
+		return fake.listUncommittedReturns.result1, fake.listUncommittedReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) ListUncommittedCallCount() int {
+	fake.listUncommittedMutex.RLock()
+	defer fake.listUncommittedMutex.RUnlock()
+	return len(fake.listUncommittedArgsForCall)
+}
+
+func (fake *FakeS3Client) ListUncommittedArgsForCall(i int) string {
+	fake.listUncommittedMutex.RLock()
+	defer fake.listUncommittedMutex.RUnlock()
+	return fake.listUncommittedArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) ListUncommittedReturns(result1 []s3.Object, result2 error) {
+	fake.ListUncommittedStub = nil
+	fake.listUncommittedReturns = struct {
+		result1 []s3.Object
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) ListIncompleteUploads(path string) (uploads []s3.Upload, err error) {
+	fake.listIncompleteUploadsMutex.Lock()
+	fake.listIncompleteUploadsArgsForCall = append(fake.listIncompleteUploadsArgsForCall, struct {
+		path string
+	}{path})
+	fake.listIncompleteUploadsMutex.Unlock()
+	if fake.ListIncompleteUploadsStub != nil {
+		return fake.ListIncompleteUploadsStub(path)
+	} else {
+		return fake.listIncompleteUploadsReturns.result1, fake.listIncompleteUploadsReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) ListIncompleteUploadsCallCount() int {
+	fake.listIncompleteUploadsMutex.RLock()
+	defer fake.listIncompleteUploadsMutex.RUnlock()
+	return len(fake.listIncompleteUploadsArgsForCall)
+}
+
+func (fake *FakeS3Client) ListIncompleteUploadsArgsForCall(i int) string {
+	fake.listIncompleteUploadsMutex.RLock()
+	defer fake.listIncompleteUploadsMutex.RUnlock()
+	return fake.listIncompleteUploadsArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) ListIncompleteUploadsReturns(result1 []s3.Upload, result2 error) {
+	fake.ListIncompleteUploadsStub = nil
+	fake.listIncompleteUploadsReturns = struct {
+		result1 []s3.Upload
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) Delete(path string) error {
+	fake.deleteMutex.Lock()
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		path string
+	}{path})
+	fake.deleteMutex.Unlock()
+	if fake.DeleteStub != nil {
+		return fake.DeleteStub(path)
+	} else {
+		return fake.deleteReturns.result1
+	}
+}
+
+func (fake *FakeS3Client) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeS3Client) DeleteArgsForCall(i int) string {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return fake.deleteArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) DeleteReturns(result1 error) {
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeS3Client) AbortUpload(path, uploadID string) error {
+	fake.abortUploadMutex.Lock()
+	fake.abortUploadArgsForCall = append(fake.abortUploadArgsForCall, struct {
+		path     string
+		uploadID string
+	}{path, uploadID})
+	fake.abortUploadMutex.Unlock()
+	if fake.AbortUploadStub != nil {
+		return fake.AbortUploadStub(path, uploadID)
+	} else {
+		return fake.abortUploadReturns.result1
+	}
+}
+
+func (fake *FakeS3Client) AbortUploadCallCount() int {
+	fake.abortUploadMutex.RLock()
+	defer fake.abortUploadMutex.RUnlock()
+	return len(fake.abortUploadArgsForCall)
+}
+
+func (fake *FakeS3Client) AbortUploadArgsForCall(i int) (string, string) {
+	fake.abortUploadMutex.RLock()
+	defer fake.abortUploadMutex.RUnlock()
+	return fake.abortUploadArgsForCall[i].path, fake.abortUploadArgsForCall[i].uploadID
+}
+
+func (fake *FakeS3Client) AbortUploadReturns(result1 error) {
+	fake.AbortUploadStub = nil
+	fake.abortUploadReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ cleanup.S3Client = new(FakeS3Client)
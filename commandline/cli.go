@@ -2,35 +2,89 @@ package commandline
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/tscolari/s3kup/config"
+	"github.com/tscolari/s3kup/fetch"
 	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
 )
 
+func Execute() {
+	err := New().Execute()
+	if err != nil {
+		fatal(invalidUsage(err))
+	}
+}
+
 func New() *cobra.Command {
 	mainCmd := mainCommand()
 	pushCmd := pushCommand()
 	listCmd := listCommand()
 	pullCmd := pullCommand()
+	restoreCmd := restoreCommand()
+	migrateCmd := migrateCommand()
+	doctorCmd := doctorCommand()
+	diffCmd := diffCommand()
+	pinCmd := pinCommand()
+	unpinCmd := unpinCommand()
+	cleanupCmd := cleanupCommand()
+	fsckCmd := fsckCommand()
+	deleteCmd := deleteCommand()
+	infoCmd := infoCommand()
+	configCmd := configCommand()
+	runCmd := runCommand()
+	daemonCmd := daemonCommand()
+	completionCmd := completionCommand()
 
 	mainCmd.AddCommand(pushCmd)
+	mainCmd.AddCommand(runCmd)
 	mainCmd.AddCommand(listCmd)
 	mainCmd.AddCommand(pullCmd)
+	mainCmd.AddCommand(restoreCmd)
+	mainCmd.AddCommand(migrateCmd)
+	mainCmd.AddCommand(doctorCmd)
+	mainCmd.AddCommand(diffCmd)
+	mainCmd.AddCommand(pinCmd)
+	mainCmd.AddCommand(unpinCmd)
+	mainCmd.AddCommand(cleanupCmd)
+	mainCmd.AddCommand(fsckCmd)
+	mainCmd.AddCommand(deleteCmd)
+	mainCmd.AddCommand(infoCmd)
+	mainCmd.AddCommand(configCmd)
+	mainCmd.AddCommand(daemonCmd)
+	mainCmd.AddCommand(completionCmd)
 
 	setGlobalFlags(mainCmd)
-	initViperFlags(mainCmd, pushCmd)
+	mainCmd.RegisterFlagCompletionFunc("file-name", completeFileNames)
+	initViperFlags(mainCmd)
 	return mainCmd
 }
 
 func mainCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use: "s3kup",
+		Use:           "s3kup",
+		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			bindSettingFlags(cmd)
+			err := applyFlagsEnv(cmd)
+			if err != nil {
+				fatal(invalidUsage(err))
+			}
+
+			_, err = loadConfig(cmd)
+			if err != nil {
+				fatal(invalidUsage(err))
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			initLogger()
 			_, _, _, _, _, err := fetchAndValidateGlobalParams()
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 		},
 	}
@@ -44,24 +98,29 @@ func setGlobalFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringP("secret-key", "s", "", "AWS Secret Key")
 	cmd.PersistentFlags().StringP("bucket-name", "b", "", "Target S3 bucket")
 	cmd.PersistentFlags().StringP("file-name", "n", "", "How the file will be called on s3")
+	cmd.PersistentFlags().String("key-template", s3.DefaultKeyTemplate, "Layout of the version keys on s3. Fields: {{.Name}} {{.ID}} {{.Ext}} {{.Year}} {{.Month}} {{.Day}} {{.Hour}}")
+	cmd.PersistentFlags().String("key-extension", "", "Value of {{.Ext}} in the key template, e.g. '.sql.gz'")
+	cmd.PersistentFlags().String("encryption", "", "Server side encryption of the pushed objects. Only AES256 is supported")
 	cmd.PersistentFlags().BoolP("verbose", "v", false, "Verbose mode")
+	cmd.PersistentFlags().String("config", "", "Config file (default "+config.DefaultPath()+")")
+	cmd.PersistentFlags().String("profile", "", "Named profile of the config file to use")
 }
 
 func fetchAndValidateGlobalParams() (accessKey, secretKey, bucketName, fileName, endpointURL string, err error) {
 	if accessKey = viper.GetString("access-key"); accessKey == "" {
-		err = errors.New("missing access key argument")
+		err = missingSettingError("access key", "access-key")
 	}
 
 	if secretKey = viper.GetString("secret-key"); secretKey == "" {
-		err = errors.New("missing secret key argument")
+		err = missingSettingError("secret key", "secret-key")
 	}
 
 	if fileName = viper.GetString("file-name"); fileName == "" {
-		err = errors.New("missing file name argument")
+		err = missingSettingError("file name", "file-name")
 	}
 
 	if bucketName = viper.GetString("bucket-name"); bucketName == "" {
-		err = errors.New("missing bucket name argument")
+		err = missingSettingError("bucket name", "bucket-name")
 	}
 
 	endpointURL = viper.GetString("endpoint-url")
@@ -69,6 +128,88 @@ func fetchAndValidateGlobalParams() (accessKey, secretKey, bucketName, fileName,
 	return accessKey, secretKey, bucketName, fileName, endpointURL, err
 }
 
+func missingSettingError(description, key string) error {
+	return invalidUsage(fmt.Errorf("missing %s argument. Set it with --%s, %s or in the config file", description, key, config.EnvVar(key)))
+}
+
+func newS3Client(accessKey, secretKey, bucketName, endpointURL string) (*s3.Client, error) {
+	return configureS3Client(accessKey, secretKey, bucketName, endpointURL, viper.GetString("key-template"), viper.GetString("key-extension"), viper.GetString("encryption"))
+}
+
+func configureS3Client(accessKey, secretKey, bucketName, endpointURL, template, extension, encryption string) (*s3.Client, error) {
+	keyTemplate, err := s3.NewKeyTemplate(template, extension)
+	if err != nil {
+		return nil, invalidUsage(err)
+	}
+
+	client := s3.NewWithKeyTemplate(accessKey, secretKey, bucketName, endpointURL, keyTemplate)
+
+	switch encryption {
+	case "":
+	case s3.ServerSideEncryption:
+		client.EnableServerSideEncryption()
+	default:
+		return nil, invalidUsage(errors.New("Invalid encryption '" + encryption + "'. Only " + s3.ServerSideEncryption + " is supported"))
+	}
+
+	return client, nil
+}
+
+func addSelectorFlags(cmd *cobra.Command) {
+	cmd.Flags().String("before", "", "Select the latest version before the given time, e.g. '2026-10-01 03:00'")
+	cmd.Flags().String("at", "", "Select the latest version of the given day, e.g. 'yesterday' or '2026-10-01'")
+	cmd.Flags().Bool("oldest", false, "Select the oldest version")
+	cmd.Flags().StringSlice("label", []string{}, "Only select versions with the given label (key=value)")
+}
+
+func fetchSelector(cmd *cobra.Command, args []string) (selector fetch.Selector, selected bool, err error) {
+	expressions := append([]string{}, args...)
+	if before, _ := cmd.Flags().GetString("before"); before != "" {
+		expressions = append(expressions, "before:"+before)
+	}
+	if at, _ := cmd.Flags().GetString("at"); at != "" {
+		expressions = append(expressions, "at:"+at)
+	}
+	if oldest, _ := cmd.Flags().GetBool("oldest"); oldest {
+		expressions = append(expressions, "oldest")
+	}
+
+	if len(expressions) > 1 {
+		return selector, false, invalidUsage(errors.New("You can specify only one version"))
+	}
+
+	expression := ""
+	if len(expressions) == 1 {
+		expression = expressions[0]
+	}
+
+	labels, err := fetchLabels(cmd)
+	if err != nil {
+		return selector, false, err
+	}
+
+	selector, err = fetch.ParseSelector(expression, time.Now())
+	if err != nil {
+		return selector, false, invalidUsage(err)
+	}
+
+	return selector.WithLabels(labels), expression != "" || len(labels) > 0, nil
+}
+
+func fetchLabels(cmd *cobra.Command) (s3.Labels, error) {
+	labels, err := cmd.Flags().GetStringSlice("label")
+	if err != nil {
+		return nil, err
+	}
+
+	parsedLabels, err := s3.ParseLabels(labels)
+	if err != nil {
+		return nil, invalidUsage(err)
+	}
+
+	return parsedLabels, nil
+}
+
 func initLogger() {
 	if viper.GetBool("verbose") {
 		log.SetLevel(log.INFO_LEVEL)
@@ -0,0 +1,44 @@
+package commandline
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/cleanup"
+)
+
+func cleanupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Removes stale uploads that were never committed",
+		Long:  `Removes staged uploads and bundle parts that were never committed as a version, and aborts incomplete multipart uploads, usually left behind by interrupted pushes`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+
+			olderThan, err := cmd.Flags().GetDuration("older-than")
+			if err != nil {
+				fatal(err)
+			}
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+			cleaner := cleanup.New(s3Client)
+
+			deleted, err := cleaner.Clean(fileName, time.Now().Add(-olderThan))
+			if err != nil {
+				fatal(err)
+			}
+
+			fmt.Printf("Deleted %d stale uploads\n", deleted)
+		},
+	}
+	cmd.Flags().Duration("older-than", 24*time.Hour, "Only remove uploads older than this, so running pushes are not affected")
+	return cmd
+}
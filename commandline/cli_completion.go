@@ -0,0 +1,157 @@
+package commandline
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tscolari/s3kup/completion"
+	"github.com/tscolari/s3kup/list"
+	"github.com/tscolari/s3kup/s3"
+)
+
+func completionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "completion bash|zsh|fish",
+		Short:     "Prints the shell completion script",
+		Long:      `Prints the shell completion script. e.g. 'source <(s3kup completion bash)'`,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			switch args[0] {
+			case "bash":
+				err = cmd.Root().GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				err = cmd.Root().GenZshCompletion(os.Stdout)
+			case "fish":
+				err = cmd.Root().GenFishCompletion(os.Stdout, true)
+			}
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func completeVersions(multiple bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 && !multiple {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		suggestions, err := versionSuggestions(cmd)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return suggestions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	}
+}
+
+func completeFileNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if index := strings.LastIndex(toComplete, "/"); index >= 0 {
+		prefix = toComplete[:index+1]
+	}
+
+	names, err := fileNameSuggestions(cmd, prefix)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func versionSuggestions(cmd *cobra.Command) ([]string, error) {
+	err := initCompletionConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.Join([]string{"versions", endpointURL, bucketName, viper.GetString("key-template"), viper.GetString("key-extension"), fileName}, "\x00")
+	return completionCache().Suggestions(key, func() ([]string, error) {
+		s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+		if err != nil {
+			return nil, err
+		}
+
+		versions, err := list.New(s3Client).ListWithLabels(fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		suggestions := []string{}
+		for i := len(versions) - 1; i >= 0; i-- {
+			suggestions = append(suggestions, versions[i].Version+"\t"+versionDescription(versions[i]))
+		}
+
+		return suggestions, nil
+	})
+}
+
+func fileNameSuggestions(cmd *cobra.Command, prefix string) ([]string, error) {
+	err := initCompletionConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey, secretKey, bucketName, endpointURL := viper.GetString("access-key"), viper.GetString("secret-key"), viper.GetString("bucket-name"), viper.GetString("endpoint-url")
+	if accessKey == "" || secretKey == "" || bucketName == "" {
+		return nil, errors.New("the access key, secret key and bucket name are needed to complete backup names")
+	}
+
+	key := strings.Join([]string{"names", endpointURL, bucketName, viper.GetString("key-template"), viper.GetString("key-extension"), prefix}, "\x00")
+	return completionCache().Suggestions(key, func() ([]string, error) {
+		s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+		if err != nil {
+			return nil, err
+		}
+
+		return s3Client.BackupNames(prefix)
+	})
+}
+
+func initCompletionConfig(cmd *cobra.Command) error {
+	bindSettingFlags(cmd)
+	err := applyFlagsEnv(cmd)
+	if err != nil {
+		return err
+	}
+
+	_, err = loadConfig(cmd)
+	return err
+}
+
+func completionCache() completion.Cache {
+	return completion.NewCache(completion.DefaultCacheDir(), completion.DefaultTTL)
+}
+
+func versionDescription(version s3.Version) string {
+	created := version.LastModified
+	if timestamp := s3.VersionIDTimestamp(version.Version); timestamp > 0 {
+		created = time.Unix(0, timestamp)
+	}
+
+	description := []string{created.UTC().Format(time.RFC3339)}
+	if version.Pinned {
+		description = append(description, "pinned")
+	}
+	if len(version.Labels) > 0 {
+		description = append(description, version.Labels.String())
+	}
+
+	return strings.Join(description, ", ")
+}
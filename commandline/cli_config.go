@@ -0,0 +1,85 @@
+package commandline
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tscolari/s3kup/config"
+)
+
+func configCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspects the configuration",
+	}
+
+	cmd.AddCommand(configShowCommand())
+	return cmd
+}
+
+func configShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Prints the effective settings and where each one came from",
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			settings, err := loadConfig(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
+			path, _ := cmd.Flags().GetString("config")
+			if path == "" {
+				path = config.DefaultPath()
+			}
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				path += " (not found)"
+			}
+
+			profile, _ := cmd.Flags().GetString("profile")
+			if profile == "" {
+				profile = "-"
+			}
+
+			fmt.Printf("Config file:  %s\n", path)
+			fmt.Printf("Profile:      %s\n\n", profile)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+			for _, key := range config.Keys {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", key, settingValue(key), settingSource(cmd, settings, key))
+			}
+			w.Flush()
+		},
+	}
+
+	return cmd
+}
+
+func settingValue(key string) string {
+	value := viper.GetString(key)
+	if value == "" {
+		return "-"
+	}
+
+	if key == "secret-key" {
+		return "********"
+	}
+
+	return value
+}
+
+func settingSource(cmd *cobra.Command, settings config.Settings, key string) string {
+	if flag := cmd.Flags().Lookup(key); flag != nil && flag.Changed {
+		return "flag --" + key
+	}
+
+	if setting, ok := settings[key]; ok {
+		return setting.Source
+	}
+
+	return "default"
+}
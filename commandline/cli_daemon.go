@@ -0,0 +1,132 @@
+package commandline
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/tscolari/s3kup/config"
+	"github.com/tscolari/s3kup/daemon"
+)
+
+func daemonCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Runs the jobs of a jobs file on their schedules",
+		Long:  `Runs the jobs of a jobs file on their schedules, with 'run'. Reloads the jobs file on SIGHUP`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			jobsPath, err := cmd.Flags().GetString("jobs")
+			if err != nil {
+				fatal(err)
+			}
+			statePath, err := cmd.Flags().GetString("state-file")
+			if err != nil {
+				fatal(err)
+			}
+
+			executable, err := os.Executable()
+			if err != nil {
+				fatal(err)
+			}
+
+			args, env := globalArgs(cmd)
+			executor := daemon.NewProcessExecutor(executable, args, env)
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+
+			err = daemon.New(jobsPath, statePath, executor).Run(signals)
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+	cmd.Flags().String("jobs", daemon.DefaultJobsPath(), "Jobs file")
+	cmd.PersistentFlags().String("state-file", daemon.DefaultStatePath(), "Where the state of the jobs is kept")
+
+	cmd.AddCommand(daemonStatusCommand())
+	return cmd
+}
+
+func daemonStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Prints the state of the jobs run by the daemon",
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			statePath, err := cmd.Flags().GetString("state-file")
+			if err != nil {
+				fatal(err)
+			}
+
+			state, err := daemon.LoadState(statePath)
+			if err != nil {
+				fatal(err)
+			}
+
+			names := []string{}
+			for name := range state {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(w, "JOB\tSTATUS\tLAST RUN\tLAST SUCCESS\tNEXT RUN\tRUNS\tFAILURES\tSKIPPED\tMISSED")
+			for _, name := range names {
+				jobState := state[name]
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\n",
+					name,
+					orDash(jobState.LastStatus),
+					orDash(formatTime(jobState.LastStart)),
+					orDash(formatTime(jobState.LastSuccess)),
+					orDash(formatTime(jobState.NextRun)),
+					jobState.Runs,
+					jobState.Failures,
+					jobState.Skipped,
+					jobState.Missed,
+				)
+			}
+			w.Flush()
+
+			for _, name := range names {
+				if jobState := state[name]; jobState.LastStatus == daemon.StatusFailed {
+					fmt.Printf("\n%s: %s\n", name, jobState.LastError)
+				}
+			}
+		},
+	}
+
+	return cmd
+}
+
+// globalArgs returns the credentials as env variables, as the command lines of
+// the runs can be read by any user of the host.
+func globalArgs(cmd *cobra.Command) ([]string, []string) {
+	args, env := []string{}, []string{}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if cmd.Root().PersistentFlags().Lookup(flag.Name) != flag {
+			return
+		}
+
+		if flag.Name == "access-key" || flag.Name == "secret-key" {
+			env = append(env, config.EnvVar(flag.Name)+"="+flag.Value.String())
+		} else {
+			args = append(args, "--"+flag.Name+"="+flag.Value.String())
+		}
+	})
+
+	return args, env
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
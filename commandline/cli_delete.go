@@ -0,0 +1,155 @@
+package commandline
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/fetch"
+	"github.com/tscolari/s3kup/remove"
+)
+
+func deleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete [version...]",
+		ValidArgsFunction: completeVersions(true),
+		Short:             "Deletes remote versions",
+		Long:              `Deletes the given remote versions, the versions older than --older-than, or the whole backup with --all. Pinned versions are only deleted by --all with --include-pinned`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+
+			all, _ := cmd.Flags().GetBool("all")
+			includePinned, _ := cmd.Flags().GetBool("include-pinned")
+			olderThan, err := cmd.Flags().GetDuration("older-than")
+			if err != nil {
+				fatal(err)
+			}
+
+			if all && (len(args) > 0 || olderThan > 0) {
+				fatal(invalidUsage(errors.New("--all can't be combined with versions or --older-than")))
+			}
+
+			if includePinned && !all {
+				fatal(invalidUsage(errors.New("--include-pinned can only be combined with --all")))
+			}
+
+			if !all && len(args) == 0 && olderThan <= 0 {
+				fatal(invalidUsage(errors.New("Specify the versions to delete, --older-than or --all")))
+			}
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+			remover := remove.New(s3Client)
+
+			var plan remove.Plan
+			if all {
+				plan, err = remover.PlanAll(fileName, includePinned)
+			} else {
+				var versionIDs []string
+				versionIDs, err = findVersionIDs(fetch.New(s3Client), fileName, args)
+				if err != nil {
+					fatal(err)
+				}
+
+				var before time.Time
+				if olderThan > 0 {
+					before = time.Now().Add(-olderThan)
+				}
+				plan, err = remover.PlanVersions(fileName, versionIDs, before)
+			}
+			if err != nil {
+				fatal(err)
+			}
+
+			if len(plan.Paths) == 0 {
+				fmt.Println("Nothing to delete")
+				return
+			}
+
+			printPlan(plan)
+
+			if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+				fmt.Println("Dry run, nothing was deleted")
+				return
+			}
+
+			if yes, _ := cmd.Flags().GetBool("yes"); !yes && !confirm(fmt.Sprintf("Delete %d versions (%d objects) of '%s'?", len(plan.Versions), len(plan.Paths), fileName)) {
+				fatal(errors.New("Aborted, nothing was deleted"))
+			}
+
+			err = remover.Remove(plan)
+			if err != nil {
+				fatal(err)
+			}
+
+			fmt.Printf("Deleted %d versions (%d objects)\n", len(plan.Versions), len(plan.Paths))
+		},
+	}
+	cmd.Flags().Bool("all", false, "Delete every unpinned version of the backup and its stale uploads")
+	cmd.Flags().Bool("include-pinned", false, "Also delete the pinned versions and their pins with --all")
+	cmd.Flags().Duration("older-than", 0, "Delete the unpinned versions older than this, e.g. 720h")
+	cmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation")
+	cmd.Flags().Bool("dry-run", false, "Only print what would be deleted")
+	return cmd
+}
+
+func findVersionIDs(fetcher fetch.Fetcher, fileName string, expressions []string) ([]string, error) {
+	versionIDs := []string{}
+	for _, expression := range expressions {
+		selector, err := fetch.ParseSelector(expression, time.Now())
+		if err != nil {
+			return nil, invalidUsage(err)
+		}
+
+		version, err := fetcher.Find(fileName, selector)
+		if err != nil {
+			return nil, err
+		}
+
+		versionIDs = append(versionIDs, version.Version)
+	}
+
+	return versionIDs, nil
+}
+
+func printPlan(plan remove.Plan) {
+	fmt.Printf("Versions to delete from '%s':\n", plan.BackupName)
+	for _, version := range plan.Versions {
+		pinned := ""
+		if version.Pinned {
+			pinned = "\tpinned"
+		}
+		fmt.Printf("* %s\t%10s\t%s%s\n", version.Version, bytefmt.ByteSize(version.Size), version.LastModified.Format(time.ANSIC), pinned)
+	}
+
+	if others := len(plan.Paths) - len(plan.Versions); others > 0 {
+		fmt.Printf("and %d other objects, like bundle parts and pins\n", others)
+	}
+}
+
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+
+	return false
+}
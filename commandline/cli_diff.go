@@ -0,0 +1,95 @@
+package commandline
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/diff"
+	"github.com/tscolari/s3kup/fetch"
+)
+
+// exitDiffers is the status of a diff of versions that differ, like diff(1)'s.
+const exitDiffers = 1
+
+func diffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "diff VERSION VERSION",
+		ValidArgsFunction: completeVersions(true),
+		Short:             "Compares two remote versions",
+		Long:              `Compares the sizes, checksums and metadata of two versions, e.g. 's3kup diff @-1 latest'. When the contents differ and are text of up to 16MiB, a unified diff of them is written, otherwise a summary of the byte ranges that differ. Compressed versions can be compared with --decompress. Exits with 0 when the versions are the same and 1 when they differ`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			if len(args) != 2 {
+				fatal(invalidUsage(errors.New("Give the two versions to compare, e.g. s3kup diff @-1 latest")))
+			}
+
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+			decompress, err := cmd.Flags().GetBool("decompress")
+			if err != nil {
+				fatal(err)
+			}
+			context, err := cmd.Flags().GetInt("context")
+			if err != nil {
+				fatal(err)
+			}
+			if context < 0 {
+				fatal(invalidUsage(errors.New("The context can't be negative")))
+			}
+			labels, err := fetchLabels(cmd)
+			if err != nil {
+				fatal(err)
+			}
+			part, _ := cmd.Flags().GetString("part")
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+			fetcher := fetch.New(s3Client)
+
+			targets := []diff.Target{}
+			for _, expression := range args {
+				selector, err := fetch.ParseSelector(expression, time.Now())
+				if err != nil {
+					fatal(invalidUsage(err))
+				}
+
+				version, err := fetcher.Find(fileName, selector.WithLabels(labels))
+				if err != nil {
+					fatal(err)
+				}
+
+				target := diff.Target{Label: version.Version, Path: version.Path}
+				if part != "" {
+					manifestPart, err := fetcher.Part(version, part)
+					if err != nil {
+						fatal(err)
+					}
+					target = diff.Target{Label: version.Version + ":" + part, Path: manifestPart.Path}
+				} else if len(version.PartPaths) > 0 {
+					fatal(invalidUsage(errors.New("Version '" + version.Version + "' is a bundle. Give the part to compare with --part")))
+				}
+				targets = append(targets, target)
+			}
+
+			differ := diff.New(s3Client, diff.Options{Decompress: decompress, Context: context, MaxTextSize: diff.DefaultMaxTextSize})
+			differs, err := differ.Diff(os.Stdout, targets[0], targets[1])
+			if err != nil {
+				fatal(err)
+			}
+			if differs {
+				os.Exit(exitDiffers)
+			}
+		},
+	}
+	cmd.Flags().StringSlice("label", []string{}, "Only select versions with the given label (key=value)")
+	cmd.Flags().String("part", "", "Compare only the given part of two bundles")
+	cmd.Flags().Bool("decompress", false, "Decompress gzip and bzip2 versions before comparing them")
+	cmd.Flags().Int("context", diff.DefaultContext, "Number of unchanged lines shown around each change")
+	return cmd
+}
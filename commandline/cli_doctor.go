@@ -0,0 +1,52 @@
+package commandline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/doctor"
+)
+
+func doctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Checks the endpoint, clock, credentials and bucket permissions",
+		Long:  `Checks that the endpoint can be reached, that the clock is close to s3's and that the credentials are accepted, then probes the list, put, get and delete permissions with a throwaway key under the file name. Exits with an error if any check fails`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+
+			checks := doctor.New(s3Client, time.Now).Diagnose(fileName)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+			for _, check := range checks {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", strings.ToUpper(string(check.Status)), check.Name, check.Message)
+			}
+			w.Flush()
+
+			failures := doctor.Failures(checks)
+			if len(failures) > 0 {
+				names := []string{}
+				for _, failure := range failures {
+					names = append(names, failure.Name)
+				}
+
+				fatal(fmt.Errorf("%d of %d checks failed (%s). The %s check failed: %w", len(failures), len(checks), strings.Join(names, ", "), failures[0].Name, failures[0].Err))
+			}
+		},
+	}
+
+	return cmd
+}
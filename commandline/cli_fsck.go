@@ -0,0 +1,67 @@
+package commandline
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/fsck"
+)
+
+func fsckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Checks the remote versions for problems",
+		Long:  `Reports foreign keys, empty versions, uncommitted uploads, orphan metadata and duplicated versions, and optionally repairs them`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+
+			repair, _ := cmd.Flags().GetBool("repair")
+			olderThan, err := cmd.Flags().GetDuration("older-than")
+			if err != nil {
+				fatal(err)
+			}
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+			checker := fsck.New(s3Client)
+
+			problems, err := checker.Check(fileName)
+			if err != nil {
+				fatal(err)
+			}
+
+			for _, problem := range problems {
+				fmt.Printf("%s\t%s\t%s\n", problem.Kind, problem.Path, problem.Description)
+			}
+
+			remaining := len(problems)
+			if repair {
+				repaired, err := checker.Repair(problems, time.Now().Add(-olderThan))
+				if err != nil {
+					fatal(err)
+				}
+
+				fmt.Printf("Repaired %d problems\n", len(repaired))
+				remaining -= len(repaired)
+			}
+
+			if remaining > 0 {
+				fatal(fmt.Errorf("%d problems found", remaining))
+			}
+
+			if len(problems) == 0 {
+				fmt.Println("No problems found")
+			}
+		},
+	}
+	cmd.Flags().Bool("repair", false, "Delete uncommitted uploads, orphan metadata and identical duplicates, and abort incomplete multipart uploads")
+	cmd.Flags().Duration("older-than", 24*time.Hour, "Only repair objects older than this, so running pushes are not affected")
+	return cmd
+}
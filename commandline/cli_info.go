@@ -0,0 +1,252 @@
+package commandline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/fetch"
+	"github.com/tscolari/s3kup/info"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type objectRecord struct {
+	Path            string            `json:"path"`
+	Size            uint64            `json:"size"`
+	LastModified    string            `json:"last_modified"`
+	ETag            string            `json:"etag"`
+	StorageClass    string            `json:"storage_class"`
+	ContentType     string            `json:"content_type"`
+	Encryption      string            `json:"encryption"`
+	KMSKeyID        string            `json:"kms_key_id"`
+	LockMode        string            `json:"lock_mode"`
+	LockRetainUntil string            `json:"lock_retain_until"`
+	LegalHold       bool              `json:"legal_hold"`
+	Checksum        string            `json:"checksum"`
+	TagCount        int               `json:"tag_count"`
+	Tags            map[string]string `json:"tags"`
+	Metadata        map[string]string `json:"metadata"`
+}
+
+type partRecord struct {
+	Name string `json:"name"`
+	objectRecord
+}
+
+type infoRecord struct {
+	Name      string            `json:"name"`
+	Version   string            `json:"version"`
+	CreatedAt string            `json:"created_at"`
+	Pinned    bool              `json:"pinned"`
+	Labels    map[string]string `json:"labels"`
+	FileMode  string            `json:"file_mode"`
+	FileTime  string            `json:"file_mtime"`
+	objectRecord
+	Parts []partRecord `json:"parts"`
+}
+
+func infoCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "info [version]",
+		ValidArgsFunction: completeVersions(false),
+		Short:             "Shows everything known about a remote version",
+		Long:              `Shows the key, size, timestamps, storage details, metadata and parts of a remote version`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+
+			format, _ := cmd.Flags().GetString("output")
+			if format != "text" && format != "json" {
+				fatal(invalidUsage(errors.New("Invalid output format '" + format + "'. It must be text or json")))
+			}
+
+			selector, _, err := fetchSelector(cmd, args)
+			if err != nil {
+				fatal(err)
+			}
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+
+			version, err := fetch.New(s3Client).Find(fileName, selector)
+			if err != nil {
+				fatal(err)
+			}
+
+			versionInfo, err := info.New(s3Client).Inspect(version)
+			if err != nil {
+				fatal(err)
+			}
+
+			record := newInfoRecord(versionInfo)
+			if format == "json" {
+				err = printInfoJSON(os.Stdout, record)
+			} else {
+				printInfoText(os.Stdout, record)
+			}
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addSelectorFlags(cmd)
+	cmd.Flags().String("output", "text", "Output format: text or json")
+	return cmd
+}
+
+func newObjectRecord(object s3.ObjectInfo) objectRecord {
+	record := objectRecord{
+		Path:         object.Path,
+		Size:         object.Size,
+		LastModified: formatTime(object.LastModified),
+		ETag:         object.ETag,
+		StorageClass: object.StorageClass,
+		ContentType:  object.ContentType,
+		Encryption:   object.Encryption,
+		KMSKeyID:     object.KMSKeyID,
+		LockMode:     object.LockMode,
+		LegalHold:    object.LegalHold,
+		Checksum:     object.Checksum,
+		TagCount:     object.TagCount,
+		Tags:         map[string]string{},
+		Metadata:     map[string]string{},
+	}
+
+	record.LockRetainUntil = formatTime(object.LockRetainUntil)
+	for key, value := range object.Tags {
+		record.Tags[key] = value
+	}
+	for key, value := range object.Metadata {
+		record.Metadata[key] = value
+	}
+
+	return record
+}
+
+func newInfoRecord(versionInfo info.VersionInfo) infoRecord {
+	version := versionInfo.Version
+	record := infoRecord{
+		Name:         version.BackupName,
+		Version:      version.Version,
+		CreatedAt:    newVersionRecord(version).CreatedAt,
+		Pinned:       version.Pinned,
+		Labels:       map[string]string{},
+		objectRecord: newObjectRecord(versionInfo.Object),
+		Parts:        []partRecord{},
+	}
+
+	for key, value := range versionInfo.Object.Labels {
+		record.Labels[key] = value
+	}
+
+	attributes := versionInfo.Object.Attributes
+	if attributes.Mode != 0 {
+		record.FileMode = fmt.Sprintf("%04o", uint32(attributes.Mode))
+	}
+	record.FileTime = formatTime(attributes.ModTime)
+
+	for _, part := range versionInfo.Parts {
+		record.Parts = append(record.Parts, partRecord{Name: path.Base(part.Path), objectRecord: newObjectRecord(part)})
+	}
+
+	return record
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func printInfoJSON(w io.Writer, record infoRecord) error {
+	content, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(content))
+	return err
+}
+
+func printInfoText(w io.Writer, record infoRecord) {
+	printInfoField(w, "Name", record.Name)
+	printInfoField(w, "Version", record.Version)
+	printInfoField(w, "Created", record.CreatedAt)
+	printInfoField(w, "Pinned", strconv.FormatBool(record.Pinned))
+	printInfoField(w, "Labels", s3.Labels(record.Labels).String())
+	printInfoField(w, "File mode", record.FileMode)
+	printInfoField(w, "File mtime", record.FileTime)
+	printObjectText(w, record.objectRecord)
+
+	for _, part := range record.Parts {
+		fmt.Fprintf(w, "\nPart %s\n", part.Name)
+		printObjectText(w, part.objectRecord)
+	}
+}
+
+func printObjectText(w io.Writer, record objectRecord) {
+	lock := record.LockMode
+	if record.LockRetainUntil != "" {
+		lock += " until " + record.LockRetainUntil
+	}
+	if record.LegalHold {
+		lock += " (legal hold)"
+	}
+
+	encryption := record.Encryption
+	if record.KMSKeyID != "" {
+		encryption += " (" + record.KMSKeyID + ")"
+	}
+
+	printInfoField(w, "Path", record.Path)
+	printInfoField(w, "Size", fmt.Sprintf("%d (%s)", record.Size, bytefmt.ByteSize(record.Size)))
+	printInfoField(w, "Last modified", record.LastModified)
+	printInfoField(w, "ETag", record.ETag)
+	printInfoField(w, "Storage class", record.StorageClass)
+	printInfoField(w, "Content type", record.ContentType)
+	printInfoField(w, "Encryption", encryption)
+	printInfoField(w, "Object lock", lock)
+	printInfoField(w, "Checksum", record.Checksum)
+	printInfoMap(w, "Tags", record.Tags)
+	printInfoMap(w, "Metadata", record.Metadata)
+}
+
+func printInfoMap(w io.Writer, name string, values map[string]string) {
+	if len(values) == 0 {
+		printInfoField(w, name, "")
+		return
+	}
+
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintln(w, name+":")
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", key, values[key])
+	}
+}
+
+func printInfoField(w io.Writer, name, value string) {
+	if value == "" {
+		value = "-"
+	}
+	fmt.Fprintf(w, "%-15s %s\n", name+":", value)
+}
@@ -1,14 +1,10 @@
 package commandline
 
 import (
-	"fmt"
-	"time"
-
-	"code.cloudfoundry.org/bytefmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/tscolari/s3kup/list"
-	"github.com/tscolari/s3kup/log"
 	"github.com/tscolari/s3kup/s3"
 )
 
@@ -21,26 +17,52 @@ func listCommand() *cobra.Command {
 			initLogger()
 			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
-			s3Client := s3.New(accessKey, secretKey, bucketName, endpointURL)
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
 			lister := list.New(s3Client)
 
-			versions, err := lister.List(fileName)
+			labels, err := fetchLabels(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
+			format, _ := cmd.Flags().GetString("output")
+			templateText, _ := cmd.Flags().GetString("template")
+			printVersions, err := newVersionsPrinter(format, templateText)
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
-			if len(versions) == 0 {
-				fmt.Println("No versions found")
+			// Every output but the text one has a labels field, which
+			// can't be left empty for versions that have labels.
+			showLabels, _ := cmd.Flags().GetBool("show-labels")
+			listVersions := lister.ListMatching
+			if showLabels || templateText != "" || (format != "" && format != "text") {
+				listVersions = func(path string, selector s3.Labels) (s3.Versions, error) {
+					versions, err := lister.ListWithLabels(path)
+					return versions.Matching(selector), err
+				}
 			}
 
-			for _, version := range versions {
-				size := bytefmt.ByteSize(version.Size)
-				fmt.Printf("* %d\t%10s\t%s\n", version.Version, size, version.LastModified.Format(time.ANSIC))
+			versions, err := listVersions(fileName, labels)
+			if err != nil {
+				fatal(err)
+			}
+
+			err = printVersions(os.Stdout, versions)
+			if err != nil {
+				fatal(err)
 			}
 		},
 	}
+	cmd.Flags().StringSlice("label", []string{}, "Only list versions with the given label (key=value)")
+	cmd.Flags().Bool("show-labels", false, "Fetch and show the labels of every version in the text output (one request per version)")
+	cmd.Flags().String("output", "text", "Output format: text, json, yaml, csv or tsv")
+	cmd.Flags().String("template", "", "Print each version with a Go template, e.g. '{{.Version}} {{.Size}}'")
 	return cmd
 }
@@ -0,0 +1,93 @@
+package commandline
+
+import (
+	"errors"
+	"fmt"
+
+	"code.cloudfoundry.org/bytefmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tscolari/s3kup/config"
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/migrate"
+)
+
+func migrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate --from PROFILE --to PROFILE [NAME...]",
+		Short: "Copies every version of backups to the bucket of another profile",
+		Long:  `Copies every version of the given backups from the bucket of one profile of the config file to the bucket of another, keeping the version IDs, labels and file attributes. Each copy is verified against the checksum of the source. Objects that were already copied are skipped, so running it again resumes an interrupted migration. Nothing is deleted`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			fromProfile, _ := cmd.Flags().GetString("from")
+			toProfile, _ := cmd.Flags().GetString("to")
+			if fromProfile == "" || toProfile == "" {
+				fatal(invalidUsage(errors.New("Give the profiles to migrate between, e.g. s3kup migrate --from aws --to minio my-pg-bkp")))
+			}
+
+			parallel, err := cmd.Flags().GetInt("parallel")
+			if err != nil {
+				fatal(err)
+			}
+			if parallel < 1 {
+				fatal(invalidUsage(fmt.Errorf("Invalid --parallel %d. It must be at least 1", parallel)))
+			}
+
+			from, fromSettings, err := profileS3Client(cmd, fromProfile)
+			if err != nil {
+				fatal(err)
+			}
+			to, toSettings, err := profileS3Client(cmd, toProfile)
+			if err != nil {
+				fatal(err)
+			}
+
+			if bucketLocation(fromSettings) == bucketLocation(toSettings) {
+				fatal(invalidUsage(fmt.Errorf("The profiles '%s' and '%s' point to the same bucket", fromProfile, toProfile)))
+			}
+
+			names := args
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				names, err = from.BackupNames("")
+				if err != nil {
+					fatal(err)
+				}
+			} else if len(names) == 0 {
+				if name := settingString(fromSettings, "file-name", ""); name != "" {
+					names = []string{name}
+				}
+			}
+
+			if len(names) == 0 {
+				fatal(invalidUsage(errors.New("Give the names of the backups to migrate, or --all")))
+			}
+
+			migrator := migrate.New(from, to, parallel)
+			var migrateErr error
+			for _, name := range names {
+				result, err := migrator.Migrate(name)
+				if err != nil {
+					log.Error(err)
+					if migrateErr == nil {
+						migrateErr = err
+					}
+				}
+
+				fmt.Printf("%s: %d versions, %d copied (%s), %d already migrated, %d failed\n", name, result.Versions, result.Copied, bytefmt.ByteSize(result.Bytes), result.Skipped, result.Failed)
+			}
+
+			if migrateErr != nil {
+				fatal(migrateErr)
+			}
+		},
+	}
+	cmd.Flags().String("from", "", "Profile of the config file to copy the backups from")
+	cmd.Flags().String("to", "", "Profile of the config file to copy the backups to")
+	cmd.Flags().Bool("all", false, "Migrate every backup of the source bucket")
+	cmd.Flags().Int("parallel", 4, "Number of versions copied at the same time")
+	return cmd
+}
+
+func bucketLocation(settings config.Settings) string {
+	return settingString(settings, "endpoint-url", viper.GetString("endpoint-url")) + "/" + settingString(settings, "bucket-name", "")
+}
@@ -0,0 +1,97 @@
+package commandline
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/fetch"
+	"github.com/tscolari/s3kup/pin"
+)
+
+func pinCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "pin <version>",
+		ValidArgsFunction: completeVersions(false),
+		Short:             "Protects a remote version from being deleted",
+		Long:              `Pins a remote version, so it's never deleted when old versions are cleaned up`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+
+			selector, selected, err := fetchSelector(cmd, args)
+			if err != nil {
+				fatal(err)
+			}
+
+			if !selected {
+				fatal(invalidUsage(errors.New("You must specify one version to pin")))
+			}
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+			fetcher := fetch.New(s3Client)
+			pinner := pin.New(s3Client)
+
+			version, err := fetcher.Find(fileName, selector)
+			if err != nil {
+				fatal(err)
+			}
+
+			err = pinner.Pin(fileName, version.Version)
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addSelectorFlags(cmd)
+	return cmd
+}
+
+func unpinCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "unpin <version>",
+		ValidArgsFunction: completeVersions(false),
+		Short:             "Removes the protection of a pinned version",
+		Long:              `Unpins a remote version, so it can be deleted again when old versions are cleaned up`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+
+			selector, selected, err := fetchSelector(cmd, args)
+			if err != nil {
+				fatal(err)
+			}
+
+			if !selected {
+				fatal(invalidUsage(errors.New("You must specify one version to unpin")))
+			}
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+			fetcher := fetch.New(s3Client)
+			pinner := pin.New(s3Client)
+
+			version, err := fetcher.Find(fileName, selector)
+			if err != nil {
+				fatal(err)
+			}
+
+			err = pinner.Unpin(fileName, version.Version)
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addSelectorFlags(cmd)
+	return cmd
+}
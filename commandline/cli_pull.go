@@ -3,52 +3,76 @@ package commandline
 import (
 	"encoding/binary"
 	"os"
-	"strconv"
 
 	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/download"
 	"github.com/tscolari/s3kup/fetch"
-	"github.com/tscolari/s3kup/log"
-	"github.com/tscolari/s3kup/s3"
 )
 
 func pullCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "pull",
-		Short: "Get remote version contents",
-		Long:  `Get remote version and print it's contents to STDOUT`,
+		Use:               "pull [version]",
+		ValidArgsFunction: completeVersions(false),
+		Short:             "Get remote version contents",
+		Long:              `Get remote version and print it's contents to STDOUT`,
 		Run: func(cmd *cobra.Command, args []string) {
 			initLogger()
 			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
-			s3Client := s3.New(accessKey, secretKey, bucketName, endpointURL)
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
 			fetcher := fetch.New(s3Client)
 
-			if len(args) > 1 {
-				log.Fatal("You can specify only one version to get")
+			selector, _, err := fetchSelector(cmd, args)
+			if err != nil {
+				fatal(err)
 			}
 
-			var content []byte
-			if len(args) == 1 {
-				var version int64
-				version, err = strconv.ParseInt(args[0], 10, 64)
+			part, _ := cmd.Flags().GetString("part")
+			if output, _ := cmd.Flags().GetString("output"); output != "" {
+				version, err := fetcher.Find(fileName, selector)
 				if err != nil {
-					log.Fatal("Invalid version format. It can only contain numbers")
+					fatal(err)
+				}
+
+				path := version.Path
+				if part != "" {
+					path, err = fetcher.PartPath(version, part)
+					if err != nil {
+						fatal(err)
+					}
 				}
 
-				content, err = fetcher.FetchVersion(fileName, version)
-			} else {
-				content, err = fetcher.FetchLatest(fileName)
+				stopProgress := startProgress(cmd, s3Client, "pull "+fileName)
+				err = download.New(s3Client).Download(path, output)
+				stopProgress()
+				if err != nil {
+					fatal(err)
+				}
+				return
 			}
 
+			stopProgress := startProgress(cmd, s3Client, "pull "+fileName)
+			content, err := fetcher.Fetch(fileName, selector)
+			if err == nil && part != "" {
+				content, err = fetcher.FetchPart(content, part)
+			}
+			stopProgress()
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
 			binary.Write(os.Stdout, binary.LittleEndian, content)
 		},
 	}
+	addSelectorFlags(cmd)
+	cmd.Flags().String("part", "", "Get only the given part of a bundle")
+	cmd.Flags().StringP("output", "o", "", "Write the content to this file instead of STDOUT. Interrupted downloads are resumed")
+	addProgressFlags(cmd)
 	return cmd
 }
@@ -2,67 +2,175 @@ package commandline
 
 import (
 	"errors"
-	"io/ioutil"
-	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/tscolari/s3kup/backup"
-	"github.com/tscolari/s3kup/log"
-	"github.com/tscolari/s3kup/s3"
 )
 
 func pushCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "push",
-		Short: "Pushes the piped input to s3",
-		Long:  `Pushes the pipped input to s3, as a versioned backup`,
+		Use:   "push [FILE...]",
+		Short: "Pushes the piped input or files to s3",
+		Long:  `Pushes the piped input, or the given files, to s3 as a versioned backup. Several files are concatenated`,
 		Run: func(cmd *cobra.Command, args []string) {
 			initLogger()
 			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 			versionsToKeep, err := fetchVersionsToKeep()
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
+			}
+			deletionLimits, err := fetchDeletionLimits(cmd)
+			if err != nil {
+				fatal(err)
+			}
+			labels, err := fetchLabels(cmd)
+			if err != nil {
+				fatal(err)
+			}
+			keepRules, err := fetchKeepRules(cmd)
+			if err != nil {
+				fatal(err)
 			}
 
-			s3Client := s3.New(accessKey, secretKey, bucketName, endpointURL)
-			backuper := backup.New(s3Client, versionsToKeep)
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+			backuper := backup.New(s3Client, versionsToKeep, deletionLimits).WithKeepRules(keepRules)
 
-			content, err := getInputContent()
+			partFlags, err := cmd.Flags().GetStringSlice("part")
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
-			err = backuper.Backup(fileName, content)
+			inputs, err := fetchInputs(cmd, args)
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
+			}
+
+			if len(partFlags) > 0 {
+				if len(inputs) > 0 {
+					fatal(invalidUsage(errors.New("Files can't be pushed together with --part. Give them as parts instead")))
+				}
+
+				parts, closeParts, err := openInputParts(partFlags)
+				if err != nil {
+					fatal(err)
+				}
+				defer closeParts()
+
+				stopProgress := startProgress(cmd, s3Client, "push "+fileName)
+				err = backuper.BackupBundle(fileName, parts, labels)
+				stopProgress()
+				if err != nil {
+					fatal(err)
+				}
+				return
+			}
+
+			input, err := openInput(inputs)
+			if err != nil {
+				fatal(err)
+			}
+			defer input.Close()
+
+			stopProgress := startProgress(cmd, s3Client, "push "+fileName)
+			err = backuper.BackupReader(fileName, input, input.size, labels, input.attributes)
+			stopProgress()
+			if err != nil {
+				fatal(err)
 			}
 		},
 	}
 	cmd.Flags().IntP("versions-to-keep", "k", 5, "Number of versions to keep")
+	cmd.Flags().Int("max-deletes", 2, "Maximum number of old versions that can be deleted in one run")
+	cmd.Flags().Bool("force", false, "Delete old versions even when the safety checks refuse to")
+	cmd.Flags().StringSlice("label", []string{}, "Label the version (key=value). Old versions are only pruned among versions with the same labels")
+	cmd.Flags().StringSlice("keep", []string{}, "Keep this many versions with the label, whatever the pushed labels (key=value=N)")
+	cmd.Flags().StringSlice("part", []string{}, "Push the file or FIFO as a named part of a bundle (name=path), instead of the piped input")
+	cmd.Flags().StringP("input", "i", "", "File to push, or '-' for stdin. Same as giving it as an argument")
+	addProgressFlags(cmd)
 	return cmd
 }
 
-func getInputContent() ([]byte, error) {
-	fi, err := os.Stdin.Stat()
+func fetchInputs(cmd *cobra.Command, args []string) ([]string, error) {
+	input, err := cmd.Flags().GetString("input")
 	if err != nil {
 		return nil, err
 	}
 
-	if fi.Mode()&os.ModeNamedPipe != 0 {
-		return ioutil.ReadAll(os.Stdin)
+	if input == "" {
+		return args, nil
 	}
 
-	return nil, errors.New("not using pipeline")
+	if len(args) > 0 {
+		return nil, invalidUsage(errors.New("Give the input either with --input or as arguments, not both"))
+	}
+
+	return []string{input}, nil
+}
+
+func openInputParts(partFlags []string) ([]backup.Part, func(), error) {
+	parts := []backup.Part{}
+	inputs := []*input{}
+	closeParts := func() {
+		for _, in := range inputs {
+			in.Close()
+		}
+	}
+
+	for _, partFlag := range partFlags {
+		nameAndPath := strings.SplitN(partFlag, "=", 2)
+		if len(nameAndPath) != 2 || nameAndPath[0] == "" || nameAndPath[1] == "" {
+			closeParts()
+			return nil, nil, invalidUsage(errors.New("Invalid part '" + partFlag + "'. It must be in the format name=path"))
+		}
+
+		in, err := openInput(nameAndPath[1:])
+		if err != nil {
+			closeParts()
+			return nil, nil, err
+		}
+		inputs = append(inputs, in)
+
+		parts = append(parts, backup.Part{Name: nameAndPath[0], Reader: in, Size: in.size})
+	}
+
+	return parts, closeParts, nil
 }
 
 func fetchVersionsToKeep() (versionsToKeep int, err error) {
 	if versionsToKeep = viper.GetInt("versions-to-keep"); versionsToKeep <= 0 {
-		err = errors.New("invalid versions to keep. Must be 1 or greater")
+		err = invalidUsage(errors.New("invalid versions to keep. Must be 1 or greater"))
 	}
 
 	return versionsToKeep, err
 }
+
+func fetchKeepRules(cmd *cobra.Command) ([]backup.KeepRule, error) {
+	rules, err := cmd.Flags().GetStringSlice("keep")
+	if err != nil {
+		return nil, err
+	}
+
+	keepRules, err := backup.ParseKeepRules(rules)
+	if err != nil {
+		return nil, invalidUsage(err)
+	}
+
+	return keepRules, nil
+}
+
+func fetchDeletionLimits(cmd *cobra.Command) (limits backup.DeletionLimits, err error) {
+	if limits.MaxDeletes = viper.GetInt("max-deletes"); limits.MaxDeletes <= 0 {
+		return limits, invalidUsage(errors.New("invalid max deletes. Must be 1 or greater"))
+	}
+
+	limits.Force, err = cmd.Flags().GetBool("force")
+	return limits, err
+}
@@ -0,0 +1,82 @@
+package commandline
+
+import (
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/fetch"
+	"github.com/tscolari/s3kup/restore"
+	"github.com/tscolari/s3kup/runner"
+)
+
+func restoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "restore [version] [flags] -- COMMAND [ARG...]",
+		ValidArgsFunction: completeVersions(false),
+		Short:             "Pipes a remote version into a command",
+		Long:              `Pipes a remote version into the STDIN of a command, e.g. 'psql mydb', and exits with 8 when the command fails. The version is downloaded to a private temporary file and its checksum is verified first: the command is only started once the whole version is known to be intact, so a corrupted version never reaches it`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			dash := cmd.ArgsLenAtDash()
+			if dash < 0 || dash == len(args) {
+				fatal(invalidUsage(errors.New("Give the command to restore into, e.g. s3kup restore -- psql mydb")))
+			}
+			selectorArgs, command := args[:dash], args[dash:]
+
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+			timeout, err := cmd.Flags().GetDuration("timeout")
+			if err != nil {
+				fatal(err)
+			}
+			tempDir, err := cmd.Flags().GetString("temp-dir")
+			if err != nil {
+				fatal(err)
+			}
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+			fetcher := fetch.New(s3Client)
+
+			selector, _, err := fetchSelector(cmd, selectorArgs)
+			if err != nil {
+				fatal(err)
+			}
+
+			version, err := fetcher.Find(fileName, selector)
+			if err != nil {
+				fatal(err)
+			}
+
+			path, size := version.Path, version.Size
+			if part, _ := cmd.Flags().GetString("part"); part != "" {
+				manifestPart, err := fetcher.Part(version, part)
+				if err != nil {
+					fatal(err)
+				}
+				path, size = manifestPart.Path, manifestPart.Size
+			} else if len(version.PartPaths) > 0 {
+				fatal(invalidUsage(errors.New("Version '" + version.Version + "' is a bundle. Give the part to restore with --part")))
+			}
+
+			restorer := restore.New(s3Client, runner.New(timeout, nil, os.Stderr), tempDir)
+			stopProgress := startProgress(cmd, s3Client, "restore "+fileName)
+			err = restorer.Restore(path, size, command, os.Stdout)
+			stopProgress()
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addSelectorFlags(cmd)
+	cmd.Flags().String("part", "", "Restore only the given part of a bundle")
+	cmd.Flags().Duration("timeout", 0, "Kill the command if the restore takes longer than this, e.g. '2h'")
+	cmd.Flags().String("temp-dir", "", "Where the version is downloaded and verified before the command is started (default: the system's temp directory)")
+	addProgressFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,83 @@
+package commandline
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/backup"
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/runner"
+	"github.com/tscolari/s3kup/s3"
+)
+
+func runCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run [flags] -- COMMAND [ARG...]",
+		Short: "Runs a command and pushes its output to s3 if it succeeds",
+		Long:  `Runs a command and pushes its output to s3 as a versioned backup. Nothing is pushed, and no old version is deleted, if the command fails, is killed or times out`,
+		Run: func(cmd *cobra.Command, args []string) {
+			initLogger()
+			if len(args) == 0 {
+				fatal(invalidUsage(errors.New("Give the command to run, e.g. s3kup run -- pg_dump mydb")))
+			}
+
+			accessKey, secretKey, bucketName, fileName, endpointURL, err := fetchAndValidateGlobalParams()
+			if err != nil {
+				fatal(err)
+			}
+			versionsToKeep, err := fetchVersionsToKeep()
+			if err != nil {
+				fatal(err)
+			}
+			deletionLimits, err := fetchDeletionLimits(cmd)
+			if err != nil {
+				fatal(err)
+			}
+			labels, err := fetchLabels(cmd)
+			if err != nil {
+				fatal(err)
+			}
+			keepRules, err := fetchKeepRules(cmd)
+			if err != nil {
+				fatal(err)
+			}
+			timeout, err := cmd.Flags().GetDuration("timeout")
+			if err != nil {
+				fatal(err)
+			}
+
+			s3Client, err := newS3Client(accessKey, secretKey, bucketName, endpointURL)
+			if err != nil {
+				fatal(err)
+			}
+			backuper := backup.New(s3Client, versionsToKeep, deletionLimits).WithKeepRules(keepRules)
+
+			log.Info("Running", args[0])
+			stopProgress := startProgress(cmd, s3Client, "push "+fileName)
+			err = runner.New(timeout, os.Stdin, os.Stderr).Run(args, func(stdout io.Reader) error {
+				return backuper.BackupReader(fileName, stdout, -1, labels, s3.Attributes{})
+			})
+			stopProgress()
+
+			var commandErr *runner.CommandError
+			if errors.As(err, &commandErr) {
+				fatal(fmt.Errorf("%w. Nothing was pushed", err))
+			}
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().IntP("versions-to-keep", "k", 5, "Number of versions to keep")
+	cmd.Flags().Int("max-deletes", 2, "Maximum number of old versions that can be deleted in one run")
+	cmd.Flags().Bool("force", false, "Delete old versions even when the safety checks refuse to")
+	cmd.Flags().StringSlice("label", []string{}, "Label the version (key=value). Old versions are only pruned among versions with the same labels")
+	cmd.Flags().StringSlice("keep", []string{}, "Keep this many versions with the label, whatever the pushed labels (key=value=N)")
+	cmd.Flags().Duration("timeout", 0, "Kill the command and push nothing if it runs for longer than this, e.g. '2h'")
+	addProgressFlags(cmd)
+	return cmd
+}
@@ -1,19 +1,105 @@
 package commandline
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"github.com/tscolari/s3kup/config"
+	"github.com/tscolari/s3kup/s3"
 )
 
-func initViperFlags(mainCmd, pushCmd *cobra.Command) {
+func initViperFlags(mainCmd *cobra.Command) {
 	viper.SetDefault("endpoint-url", "https://s3.amazonaws.com")
+	viper.SetDefault("key-template", s3.DefaultKeyTemplate)
+	viper.SetDefault("versions-to-keep", 5)
+	viper.SetDefault("max-deletes", 2)
 
 	viper.BindPFlag("endpoint-url", mainCmd.PersistentFlags().Lookup("endpoint-url"))
 	viper.BindPFlag("access-key", mainCmd.PersistentFlags().Lookup("access-key"))
 	viper.BindPFlag("secret-key", mainCmd.PersistentFlags().Lookup("secret-key"))
 	viper.BindPFlag("bucket-name", mainCmd.PersistentFlags().Lookup("bucket-name"))
 	viper.BindPFlag("file-name", mainCmd.PersistentFlags().Lookup("file-name"))
+	viper.BindPFlag("key-template", mainCmd.PersistentFlags().Lookup("key-template"))
+	viper.BindPFlag("key-extension", mainCmd.PersistentFlags().Lookup("key-extension"))
+	viper.BindPFlag("encryption", mainCmd.PersistentFlags().Lookup("encryption"))
 	viper.BindPFlag("verbose", mainCmd.PersistentFlags().Lookup("verbose"))
+}
+
+func bindSettingFlags(cmd *cobra.Command) {
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if config.IsKey(flag.Name) {
+			viper.BindPFlag(flag.Name, flag)
+		}
+	})
+}
+
+func loadConfig(cmd *cobra.Command) (config.Settings, error) {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, err
+	}
+
+	required := path != ""
+	if !required {
+		path = config.DefaultPath()
+	}
+
+	profile, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := config.Load(path, profile, required)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, viper.MergeConfigMap(settings.Values())
+}
+
+func profileS3Client(cmd *cobra.Command, profile string) (*s3.Client, config.Settings, error) {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if path == "" {
+		path = config.DefaultPath()
+	}
+
+	settings, err := config.Load(path, profile, true)
+	if err != nil {
+		return nil, nil, invalidUsage(err)
+	}
+
+	value := func(key, fallback string) string {
+		return settingString(settings, key, fallback)
+	}
+
+	for _, key := range []string{"access-key", "secret-key", "bucket-name"} {
+		if value(key, "") == "" {
+			return nil, nil, invalidUsage(fmt.Errorf("missing %s in profile '%s' of '%s'", key, profile, path))
+		}
+	}
+
+	client, err := configureS3Client(
+		value("access-key", ""),
+		value("secret-key", ""),
+		value("bucket-name", ""),
+		value("endpoint-url", viper.GetString("endpoint-url")),
+		value("key-template", s3.DefaultKeyTemplate),
+		value("key-extension", ""),
+		value("encryption", ""),
+	)
+	return client, settings, err
+}
+
+func settingString(settings config.Settings, key, fallback string) string {
+	if setting, ok := settings[key]; ok {
+		return fmt.Sprint(setting.Value)
+	}
 
-	viper.BindPFlag("versions-to-keep", pushCmd.Flags().Lookup("versions-to-keep"))
+	return fallback
 }
@@ -0,0 +1,52 @@
+package commandline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/tscolari/s3kup/config"
+)
+
+// commandLineOnly flags switch off safety checks, so they have to be given on
+// each invocation instead of being left behind in the environment.
+var commandLineOnly = map[string]bool{"help": true, "force": true}
+
+func flagEnvVar(cmd *cobra.Command, flag *pflag.Flag) string {
+	owner := cmd
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.PersistentFlags().Lookup(flag.Name) == flag {
+			owner = c
+		}
+	}
+
+	if config.IsKey(flag.Name) || !owner.HasParent() {
+		return config.EnvVar(flag.Name)
+	}
+
+	names := append(strings.Fields(owner.CommandPath())[1:], flag.Name)
+	return config.EnvVar(strings.Join(names, "-"))
+}
+
+func applyFlagsEnv(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || commandLineOnly[flag.Name] || config.IsKey(flag.Name) {
+			return
+		}
+
+		name := flagEnvVar(cmd, flag)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		if setErr := flag.Value.Set(value); setErr != nil {
+			err = fmt.Errorf("Invalid value '%s' in %s: %s", value, name, setErr)
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,87 @@
+package commandline
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/tscolari/s3kup/backup"
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/migrate"
+	"github.com/tscolari/s3kup/restore"
+	"github.com/tscolari/s3kup/runner"
+	"github.com/tscolari/s3kup/s3"
+)
+
+const (
+	exitGeneral       = 1
+	exitUsage         = 2
+	exitCredentials   = 3
+	exitNotFound      = 4
+	exitNetwork       = 5
+	exitEmptyInput    = 6
+	exitRetention     = 7
+	exitCommandFailed = 8
+	exitIntegrity     = 9
+)
+
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+func invalidUsage(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return usageError{err: err}
+}
+
+func errorClass(err error) (string, int) {
+	var usageErr usageError
+	var retentionErr *backup.RetentionError
+	var integrityErr *restore.IntegrityError
+	var checksumErr *migrate.ChecksumError
+	var commandErr *runner.CommandError
+
+	switch {
+	case errors.As(err, &usageErr):
+		return "usage", exitUsage
+	case errors.Is(err, backup.ErrEmptyInput):
+		return "empty-input", exitEmptyInput
+	case errors.As(err, &retentionErr):
+		return "retention", exitRetention
+	case errors.As(err, &integrityErr), errors.As(err, &checksumErr):
+		return "integrity", exitIntegrity
+	case errors.As(err, &commandErr):
+		return "command-failed", exitCommandFailed
+	case s3.IsNotFoundError(err):
+		return "not-found", exitNotFound
+	case s3.IsCredentialsError(err):
+		return "credentials", exitCredentials
+	case s3.IsNetworkError(err):
+		return "network", exitNetwork
+	default:
+		return "general", exitGeneral
+	}
+}
+
+func summaryLine(err error) string {
+	class, code := errorClass(err)
+	return fmt.Sprintf("s3kup: error class=%s exit=%d message=%q", class, code, err.Error())
+}
+
+func fatal(err error) {
+	_, code := errorClass(err)
+	log.Error(err)
+	fmt.Fprintln(os.Stderr, summaryLine(err))
+	os.Exit(code)
+}
@@ -0,0 +1,96 @@
+package commandline
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tscolari/s3kup/progress"
+	"github.com/tscolari/s3kup/s3"
+)
+
+const stdinInput = "-"
+
+type input struct {
+	io.Reader
+	size       int64
+	attributes s3.Attributes
+	files      []*os.File
+}
+
+func openInput(paths []string) (*input, error) {
+	if len(paths) == 0 {
+		paths = []string{stdinInput}
+	}
+
+	in := &input{attributes: s3.Attributes{}}
+	readers := []io.Reader{}
+	for _, path := range paths {
+		file, err := openInputFile(path)
+		if err != nil {
+			in.Close()
+			return nil, err
+		}
+		in.files = append(in.files, file)
+		readers = append(readers, file)
+
+		fi, err := file.Stat()
+		if err != nil {
+			in.Close()
+			return nil, err
+		}
+
+		size, err := inputSize(path, file, fi)
+		if err != nil {
+			in.Close()
+			return nil, err
+		}
+		if size < 0 || in.size < 0 {
+			in.size = -1
+		} else {
+			in.size += size
+		}
+
+		if len(paths) == 1 && fi.Mode().IsRegular() {
+			in.attributes = s3.FileAttributes(fi)
+		}
+	}
+
+	in.Reader = io.MultiReader(readers...)
+	return in, nil
+}
+
+func (i *input) Close() {
+	for _, file := range i.files {
+		if file != os.Stdin {
+			file.Close()
+		}
+	}
+}
+
+func openInputFile(path string) (*os.File, error) {
+	if path == stdinInput {
+		return os.Stdin, nil
+	}
+
+	return os.Open(path)
+}
+
+func inputSize(path string, file *os.File, fi os.FileInfo) (int64, error) {
+	mode := fi.Mode()
+	switch {
+	case mode.IsRegular():
+		return fi.Size(), nil
+	case mode&(os.ModeNamedPipe|os.ModeSocket) != 0:
+		return -1, nil
+	case path == stdinInput && progress.IsTerminal(file):
+		return 0, invalidUsage(errors.New("Refusing to read the backup from a terminal. Pipe or redirect the content to push, or give the files to push as arguments"))
+	case mode&os.ModeCharDevice != 0 && path == stdinInput:
+		return -1, nil
+	case mode.IsDir():
+		return 0, invalidUsage(fmt.Errorf("Invalid input '%s'. It is a directory", path))
+	default:
+		return 0, invalidUsage(fmt.Errorf("Invalid input '%s'. Only files, pipes and sockets can be pushed", path))
+	}
+}
@@ -0,0 +1,192 @@
+package commandline
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+	"gopkg.in/yaml.v2"
+
+	"github.com/tscolari/s3kup/s3"
+)
+
+type versionRecord struct {
+	Name         string            `json:"name" yaml:"name"`
+	Version      string            `json:"version" yaml:"version"`
+	Path         string            `json:"path" yaml:"path"`
+	Size         uint64            `json:"size" yaml:"size"`
+	CreatedAt    string            `json:"created_at" yaml:"created_at"`
+	LastModified string            `json:"last_modified" yaml:"last_modified"`
+	Pinned       bool              `json:"pinned" yaml:"pinned"`
+	Labels       map[string]string `json:"labels" yaml:"labels"`
+	Parts        []string          `json:"parts" yaml:"parts"`
+}
+
+var recordColumns = []string{"name", "version", "path", "size", "created_at", "last_modified", "pinned", "labels", "parts"}
+
+func newVersionRecord(version s3.Version) versionRecord {
+	record := versionRecord{
+		Name:         version.BackupName,
+		Version:      version.Version,
+		Path:         version.Path,
+		Size:         version.Size,
+		LastModified: version.LastModified.UTC().Format(time.RFC3339),
+		Pinned:       version.Pinned,
+		Labels:       map[string]string{},
+		Parts:        []string{},
+	}
+
+	if timestamp := s3.VersionIDTimestamp(version.Version); timestamp > 0 {
+		record.CreatedAt = time.Unix(0, timestamp).UTC().Format(time.RFC3339Nano)
+	}
+
+	for key, value := range version.Labels {
+		record.Labels[key] = value
+	}
+
+	for _, partPath := range version.PartPaths {
+		record.Parts = append(record.Parts, path.Base(partPath))
+	}
+
+	return record
+}
+
+func (r versionRecord) columns() []string {
+	return []string{
+		r.Name,
+		r.Version,
+		r.Path,
+		strconv.FormatUint(r.Size, 10),
+		r.CreatedAt,
+		r.LastModified,
+		strconv.FormatBool(r.Pinned),
+		s3.Labels(r.Labels).String(),
+		strings.Join(r.Parts, ","),
+	}
+}
+
+func newVersionsPrinter(format, templateText string) (func(io.Writer, s3.Versions) error, error) {
+	if templateText != "" {
+		if format != "" && format != "text" {
+			return nil, invalidUsage(errors.New("--template can't be used together with --output"))
+		}
+
+		tmpl, err := template.New("version").Option("missingkey=zero").Parse(templateText)
+		if err != nil {
+			return nil, invalidUsage(fmt.Errorf("Invalid template: %s", err))
+		}
+
+		return func(w io.Writer, versions s3.Versions) error {
+			return printTemplate(w, tmpl, versions)
+		}, nil
+	}
+
+	switch format {
+	case "", "text":
+		return printText, nil
+	case "json":
+		return printJSON, nil
+	case "yaml":
+		return printYAML, nil
+	case "csv":
+		return func(w io.Writer, versions s3.Versions) error {
+			return printSeparated(w, ',', versions)
+		}, nil
+	case "tsv":
+		return func(w io.Writer, versions s3.Versions) error {
+			return printSeparated(w, '\t', versions)
+		}, nil
+	}
+
+	return nil, invalidUsage(errors.New("Invalid output format '" + format + "'. It must be text, json, yaml, csv or tsv"))
+}
+
+func newVersionRecords(versions s3.Versions) []versionRecord {
+	records := []versionRecord{}
+	for _, version := range versions {
+		records = append(records, newVersionRecord(version))
+	}
+
+	return records
+}
+
+func printText(w io.Writer, versions s3.Versions) error {
+	if len(versions) == 0 {
+		fmt.Fprintln(w, "No versions found")
+	}
+
+	for _, version := range versions {
+		size := bytefmt.ByteSize(version.Size)
+		pinned := ""
+		if version.Pinned {
+			pinned = "\tpinned"
+		}
+		labels := ""
+		if len(version.Labels) > 0 {
+			labels = "\t" + version.Labels.String()
+		}
+		fmt.Fprintf(w, "* %s\t%10s\t%s%s%s\n", version.Version, size, version.LastModified.Format(time.ANSIC), pinned, labels)
+	}
+
+	return nil
+}
+
+func printJSON(w io.Writer, versions s3.Versions) error {
+	content, err := json.MarshalIndent(newVersionRecords(versions), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(content))
+	return err
+}
+
+func printYAML(w io.Writer, versions s3.Versions) error {
+	content, err := yaml.Marshal(newVersionRecords(versions))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(content)
+	return err
+}
+
+func printSeparated(w io.Writer, separator rune, versions s3.Versions) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = separator
+
+	err := writer.Write(recordColumns)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range newVersionRecords(versions) {
+		err = writer.Write(record.columns())
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func printTemplate(w io.Writer, tmpl *template.Template, versions s3.Versions) error {
+	for _, record := range newVersionRecords(versions) {
+		err := tmpl.Execute(w, record)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+
+	return nil
+}
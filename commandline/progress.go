@@ -0,0 +1,26 @@
+package commandline
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tscolari/s3kup/progress"
+	"github.com/tscolari/s3kup/s3"
+)
+
+func addProgressFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-progress", false, "Don't report the progress of the transfer on stderr")
+	cmd.Flags().Duration("progress-interval", progress.DefaultInterval, "How often the progress is logged when stderr is not a terminal")
+}
+
+func startProgress(cmd *cobra.Command, s3Client *s3.Client, label string) func() {
+	if noProgress, _ := cmd.Flags().GetBool("no-progress"); noProgress {
+		return func() {}
+	}
+	interval, _ := cmd.Flags().GetDuration("progress-interval")
+
+	display := progress.New(os.Stderr, label, progress.IsTerminal(os.Stderr), interval)
+	s3Client.SetProgress(display)
+	display.Start()
+	return display.Stop
+}
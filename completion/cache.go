@@ -0,0 +1,101 @@
+package completion
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const DefaultTTL = time.Minute
+
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+type cacheEntry struct {
+	CreatedAt   time.Time `json:"created_at"`
+	Suggestions []string  `json:"suggestions"`
+}
+
+func DefaultCacheDir() string {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		cacheHome = filepath.Join(home, ".cache")
+	}
+
+	return filepath.Join(cacheHome, "s3kup", "completion")
+}
+
+func NewCache(dir string, ttl time.Duration) Cache {
+	return Cache{
+		dir: dir,
+		ttl: ttl,
+	}
+}
+
+func (c Cache) Suggestions(key string, load func() ([]string, error)) ([]string, error) {
+	path := filepath.Join(c.dir, fmt.Sprintf("%x.json", sha1.Sum([]byte(key))))
+
+	if entry, ok := c.read(path); ok {
+		return entry.Suggestions, nil
+	}
+
+	suggestions, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.write(path, cacheEntry{CreatedAt: time.Now(), Suggestions: suggestions})
+	return suggestions, nil
+}
+
+func (c Cache) read(path string) (cacheEntry, bool) {
+	var entry cacheEntry
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return entry, false
+	}
+
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return entry, false
+	}
+
+	return entry, time.Since(entry.CreatedAt) < c.ttl
+}
+
+func (c Cache) write(path string, entry cacheEntry) {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+
+	tempFile, err := ioutil.TempFile(c.dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return
+	}
+
+	_, err = tempFile.Write(content)
+	tempFile.Close()
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return
+	}
+
+	if err := os.Rename(tempFile.Name(), path); err != nil {
+		os.Remove(tempFile.Name())
+	}
+}
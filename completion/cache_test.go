@@ -0,0 +1,91 @@
+package completion_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tscolari/s3kup/completion"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+	var dir string
+	var loads int
+
+	load := func(suggestions ...string) func() ([]string, error) {
+		return func() ([]string, error) {
+			loads++
+			return suggestions, nil
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "s3kup-completion")
+		Expect(err).ToNot(HaveOccurred())
+		loads = 0
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("loads the suggestions once while they are fresh", func() {
+		cache := completion.NewCache(filepath.Join(dir, "cache"), time.Hour)
+
+		suggestions, err := cache.Suggestions("versions my/backup", load("1", "2"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(suggestions).To(Equal([]string{"1", "2"}))
+
+		suggestions, err = cache.Suggestions("versions my/backup", load("3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(suggestions).To(Equal([]string{"1", "2"}))
+		Expect(loads).To(Equal(1))
+	})
+
+	It("keeps the suggestions of different keys apart", func() {
+		cache := completion.NewCache(dir, time.Hour)
+
+		cache.Suggestions("versions my/backup", load("1"))
+		suggestions, err := cache.Suggestions("versions other/backup", load("2"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(suggestions).To(Equal([]string{"2"}))
+	})
+
+	It("reloads the suggestions when they are stale", func() {
+		cache := completion.NewCache(dir, 0)
+
+		cache.Suggestions("versions my/backup", load("1"))
+		suggestions, err := cache.Suggestions("versions my/backup", load("2"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(suggestions).To(Equal([]string{"2"}))
+		Expect(loads).To(Equal(2))
+	})
+
+	It("doesn't cache failures", func() {
+		cache := completion.NewCache(dir, time.Hour)
+
+		_, err := cache.Suggestions("versions my/backup", func() ([]string, error) {
+			return nil, errors.New("failed to list")
+		})
+		Expect(err).To(MatchError("failed to list"))
+
+		suggestions, err := cache.Suggestions("versions my/backup", load("1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(suggestions).To(Equal([]string{"1"}))
+	})
+
+	It("works without a cache dir", func() {
+		cache := completion.NewCache(filepath.Join(dir, "file", "cache"), time.Hour)
+		Expect(ioutil.WriteFile(filepath.Join(dir, "file"), []byte{}, 0600)).To(Succeed())
+
+		suggestions, err := cache.Suggestions("versions my/backup", load("1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(suggestions).To(Equal([]string{"1"}))
+	})
+})
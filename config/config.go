@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const EnvPrefix = "S3KUP"
+
+var Keys = []string{
+	"endpoint-url",
+	"access-key",
+	"secret-key",
+	"credentials",
+	"credentials-file",
+	"bucket-name",
+	"file-name",
+	"key-template",
+	"key-extension",
+	"encryption",
+	"versions-to-keep",
+	"max-deletes",
+	"verbose",
+}
+
+type Setting struct {
+	Value  interface{}
+	Source string
+}
+
+type Settings map[string]Setting
+
+type configFile struct {
+	Settings map[string]interface{}            `yaml:",inline"`
+	Profiles map[string]map[string]interface{} `yaml:"profiles"`
+}
+
+func DefaultPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(configHome, "s3kup", "config.yaml")
+}
+
+func Load(path, profile string, required bool) (Settings, error) {
+	settings, err := loadFile(path, profile, required)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.mergeEnv()
+
+	err = settings.resolveCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func EnvVar(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.Replace(key, "-", "_", -1))
+}
+
+func loadFile(path, profile string, required bool) (Settings, error) {
+	settings := Settings{}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && !required && profile == "" {
+		return settings, nil
+	}
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("Config file '%s' does not exist", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file configFile
+	if err := yaml.UnmarshalStrict(content, &file); err != nil {
+		return nil, fmt.Errorf("Invalid config file '%s': %s", path, err)
+	}
+
+	err = settings.merge(file.Settings, "config file "+path)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile != "" {
+		profileSettings, ok := file.Profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("Could not find profile '%s' in '%s'. Known profiles: %s", profile, path, profileNames(file.Profiles))
+		}
+
+		err = settings.merge(profileSettings, fmt.Sprintf("profile '%s' in %s", profile, path))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return settings, nil
+}
+
+func (s Settings) Values() map[string]interface{} {
+	values := map[string]interface{}{}
+	for key, setting := range s {
+		values[key] = setting.Value
+	}
+
+	return values
+}
+
+func (s Settings) merge(values map[string]interface{}, source string) error {
+	for key, value := range values {
+		if !IsKey(key) {
+			return fmt.Errorf("Unknown setting '%s' in %s", key, source)
+		}
+
+		s[key] = Setting{Value: value, Source: source}
+	}
+
+	return nil
+}
+
+func (s Settings) mergeEnv() {
+	for _, key := range Keys {
+		name := EnvVar(key)
+		if value, ok := os.LookupEnv(name); ok {
+			s[key] = Setting{Value: value, Source: "env " + name}
+		}
+	}
+}
+
+func (s Settings) resolveCredentials() error {
+	reference, ok := s["credentials"]
+	if !ok {
+		return nil
+	}
+
+	name := fmt.Sprint(reference.Value)
+	path := DefaultCredentialsPath()
+	if file, ok := s["credentials-file"]; ok {
+		path = fmt.Sprint(file.Value)
+	}
+
+	credentials, err := ReadCredentials(path, name)
+	if err != nil {
+		return err
+	}
+
+	source := fmt.Sprintf("credentials '%s' in %s", name, path)
+	if _, ok := s["access-key"]; !ok {
+		s["access-key"] = Setting{Value: credentials.AccessKey, Source: source}
+	}
+	if _, ok := s["secret-key"]; !ok {
+		s["secret-key"] = Setting{Value: credentials.SecretKey, Source: source}
+	}
+
+	return nil
+}
+
+func IsKey(key string) bool {
+	for _, known := range Keys {
+		if key == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func profileNames(profiles map[string]map[string]interface{}) string {
+	if len(profiles) == 0 {
+		return "none"
+	}
+
+	names := []string{}
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,189 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/tscolari/s3kup/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config", func() {
+	var dir string
+	var configPath string
+	var credentialsPath string
+
+	writeFile := func(path, content string) {
+		Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "s3kup-config")
+		Expect(err).ToNot(HaveOccurred())
+
+		configPath = filepath.Join(dir, "config.yaml")
+		credentialsPath = filepath.Join(dir, "credentials")
+
+		writeFile(credentialsPath, `
+# shared credentials
+[default]
+aws_access_key_id = default-id
+aws_secret_access_key = default-secret
+
+[backups]
+aws_access_key_id=backups-id
+aws_secret_access_key=backups-secret
+`)
+
+		writeFile(configPath, `
+endpoint-url: https://s3.eu-west-1.amazonaws.com
+bucket-name: backups
+versions-to-keep: 7
+credentials-file: `+credentialsPath+`
+
+profiles:
+  pg-prod:
+    file-name: pg/prod
+    credentials: backups
+    versions-to-keep: 14
+    encryption: AES256
+  mysql:
+    file-name: mysql
+    bucket-name: other-backups
+    access-key: mysql-id
+    secret-key: mysql-secret
+`)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("#Load", func() {
+		It("reads the top level settings", func() {
+			settings, err := config.Load(configPath, "", true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(settings.Values()).To(Equal(map[string]interface{}{
+				"endpoint-url":     "https://s3.eu-west-1.amazonaws.com",
+				"bucket-name":      "backups",
+				"versions-to-keep": 7,
+				"credentials-file": credentialsPath,
+			}))
+			Expect(settings["bucket-name"].Source).To(Equal("config file " + configPath))
+		})
+
+		It("overrides the top level settings with the profile", func() {
+			settings, err := config.Load(configPath, "pg-prod", true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(settings["file-name"]).To(Equal(config.Setting{Value: "pg/prod", Source: "profile 'pg-prod' in " + configPath}))
+			Expect(settings["versions-to-keep"]).To(Equal(config.Setting{Value: 14, Source: "profile 'pg-prod' in " + configPath}))
+			Expect(settings["encryption"].Value).To(Equal("AES256"))
+			Expect(settings["bucket-name"]).To(Equal(config.Setting{Value: "backups", Source: "config file " + configPath}))
+		})
+
+		It("resolves the credentials reference", func() {
+			settings, err := config.Load(configPath, "pg-prod", true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(settings["access-key"]).To(Equal(config.Setting{Value: "backups-id", Source: "credentials 'backups' in " + credentialsPath}))
+			Expect(settings["secret-key"]).To(Equal(config.Setting{Value: "backups-secret", Source: "credentials 'backups' in " + credentialsPath}))
+		})
+
+		It("uses the keys given in the profile", func() {
+			settings, err := config.Load(configPath, "mysql", true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(settings["access-key"].Value).To(Equal("mysql-id"))
+			Expect(settings["secret-key"].Value).To(Equal("mysql-secret"))
+			Expect(settings["bucket-name"].Value).To(Equal("other-backups"))
+		})
+
+		Context("when the profile doesn't exist", func() {
+			It("returns an error listing the known profiles", func() {
+				_, err := config.Load(configPath, "unknown", true)
+				Expect(err).To(MatchError("Could not find profile 'unknown' in '" + configPath + "'. Known profiles: mysql, pg-prod"))
+			})
+		})
+
+		Context("when the config file doesn't exist", func() {
+			var missingPath string
+
+			BeforeEach(func() {
+				missingPath = filepath.Join(dir, "missing.yaml")
+			})
+
+			It("returns no settings when the file is optional", func() {
+				settings, err := config.Load(missingPath, "", false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(settings).To(BeEmpty())
+			})
+
+			It("returns an error when the file was given", func() {
+				_, err := config.Load(missingPath, "", true)
+				Expect(err).To(MatchError("Config file '" + missingPath + "' does not exist"))
+			})
+
+			It("returns an error when a profile was given", func() {
+				_, err := config.Load(missingPath, "pg-prod", false)
+				Expect(err).To(MatchError("Config file '" + missingPath + "' does not exist"))
+			})
+		})
+
+		Context("when the config has unknown settings", func() {
+			It("returns an error", func() {
+				writeFile(configPath, "profiles:\n  pg-prod:\n    bucket: backups\n")
+
+				_, err := config.Load(configPath, "pg-prod", true)
+				Expect(err).To(MatchError("Unknown setting 'bucket' in profile 'pg-prod' in " + configPath))
+			})
+
+			It("doesn't accept force, which is only a flag", func() {
+				writeFile(configPath, "max-deletes: 10\nforce: true\n")
+
+				_, err := config.Load(configPath, "", true)
+				Expect(err).To(MatchError("Unknown setting 'force' in config file " + configPath))
+			})
+		})
+
+		Context("when the config is not valid yaml", func() {
+			It("returns an error", func() {
+				writeFile(configPath, "bucket-name: [backups")
+
+				_, err := config.Load(configPath, "", true)
+				Expect(err).To(MatchError(HavePrefix("Invalid config file '" + configPath + "'")))
+			})
+		})
+
+		Context("when the credentials reference doesn't exist", func() {
+			It("returns an error", func() {
+				writeFile(configPath, "credentials: missing\ncredentials-file: "+credentialsPath+"\n")
+
+				_, err := config.Load(configPath, "", true)
+				Expect(err).To(MatchError("Could not find credentials 'missing' in '" + credentialsPath + "'"))
+			})
+		})
+	})
+
+	Describe("#ReadCredentials", func() {
+		It("reads the keys of the given section", func() {
+			credentials, err := config.ReadCredentials(credentialsPath, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(credentials).To(Equal(config.Credentials{AccessKey: "default-id", SecretKey: "default-secret"}))
+		})
+
+		Context("when the section is incomplete", func() {
+			It("returns an error", func() {
+				writeFile(credentialsPath, "[partial]\naws_access_key_id = id\n")
+
+				_, err := config.ReadCredentials(credentialsPath, "partial")
+				Expect(err).To(MatchError("Credentials 'partial' in '" + credentialsPath + "' must have aws_access_key_id and aws_secret_access_key"))
+			})
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Credentials struct {
+	AccessKey string
+	SecretKey string
+}
+
+func DefaultCredentialsPath() string {
+	if path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); path != "" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".aws", "credentials")
+}
+
+func ReadCredentials(path, name string) (Credentials, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("Could not read credentials '%s': %s", name, err)
+	}
+	defer file.Close()
+
+	var credentials Credentials
+	found := false
+	section := ""
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			found = found || section == name
+			continue
+		}
+
+		if section != name {
+			continue
+		}
+
+		keyAndValue := strings.SplitN(line, "=", 2)
+		if len(keyAndValue) != 2 {
+			continue
+		}
+
+		switch strings.TrimSpace(keyAndValue[0]) {
+		case "aws_access_key_id":
+			credentials.AccessKey = strings.TrimSpace(keyAndValue[1])
+		case "aws_secret_access_key":
+			credentials.SecretKey = strings.TrimSpace(keyAndValue[1])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Credentials{}, err
+	}
+
+	if !found {
+		return Credentials{}, fmt.Errorf("Could not find credentials '%s' in '%s'", name, path)
+	}
+
+	if credentials.AccessKey == "" || credentials.SecretKey == "" {
+		return Credentials{}, fmt.Errorf("Credentials '%s' in '%s' must have aws_access_key_id and aws_secret_access_key", name, path)
+	}
+
+	return credentials, nil
+}
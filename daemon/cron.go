@@ -0,0 +1,199 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Schedule struct {
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	domAny   bool
+	dowAny   bool
+	location *time.Location
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+func ParseSchedule(expression string) (Schedule, error) {
+	if macro, ok := cronMacros[strings.ToLower(strings.TrimSpace(expression))]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != len(cronFields) {
+		return Schedule{}, errors.New("it must have 5 fields: minute hour day-of-month month day-of-week")
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		var err error
+		bits[i], err = cronFields[i].parse(field)
+		if err != nil {
+			return Schedule{}, err
+		}
+	}
+
+	dow := bits[4]
+	if dow&(1<<7) != 0 {
+		dow |= 1
+	}
+
+	return Schedule{
+		minute:   bits[0],
+		hour:     bits[1],
+		dom:      bits[2],
+		month:    bits[3],
+		dow:      dow,
+		domAny:   strings.HasPrefix(fields[2], "*"),
+		dowAny:   strings.HasPrefix(fields[4], "*"),
+		location: time.Local,
+	}, nil
+}
+
+func (s Schedule) Next(after time.Time) time.Time {
+	t := after.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s Schedule) matchesDay(t time.Time) bool {
+	domMatches := s.dom&(1<<uint(t.Day())) != 0
+	dowMatches := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domAny || s.dowAny {
+		return domMatches && dowMatches
+	}
+
+	return domMatches || dowMatches
+}
+
+func (f cronField) parse(expression string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expression, ",") {
+		itemBits, err := f.parseItem(item)
+		if err != nil {
+			return 0, err
+		}
+		bits |= itemBits
+	}
+
+	return bits, nil
+}
+
+func (f cronField) parseItem(item string) (uint64, error) {
+	rangeAndStep := strings.SplitN(item, "/", 2)
+	start, end := f.min, f.max
+
+	if rangeAndStep[0] != "*" {
+		bounds := strings.SplitN(rangeAndStep[0], "-", 2)
+
+		var err error
+		start, err = f.value(bounds[0])
+		if err != nil {
+			return 0, err
+		}
+
+		end = start
+		if len(bounds) == 2 {
+			end, err = f.value(bounds[1])
+			if err != nil {
+				return 0, err
+			}
+		} else if len(rangeAndStep) == 2 {
+			end = f.max
+		}
+
+		if end < start {
+			return 0, fmt.Errorf("invalid %s range '%s'", f.name, rangeAndStep[0])
+		}
+	}
+
+	step := 1
+	if len(rangeAndStep) == 2 {
+		var err error
+		step, err = strconv.Atoi(rangeAndStep[1])
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid %s step '%s'", f.name, rangeAndStep[1])
+		}
+	}
+
+	var bits uint64
+	for value := start; value <= end; value += step {
+		bits |= 1 << uint(value)
+	}
+
+	return bits, nil
+}
+
+func (f cronField) value(expression string) (int, error) {
+	if value, ok := f.names[strings.ToLower(expression)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(expression)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid %s '%s'", f.name, expression)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,70 @@
+package daemon_test
+
+import (
+	"time"
+
+	"github.com/tscolari/s3kup/daemon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schedule", func() {
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+	}
+
+	next := func(expression string, after time.Time) time.Time {
+		schedule, err := daemon.ParseSchedule(expression)
+		Expect(err).ToNot(HaveOccurred())
+		return schedule.Next(after)
+	}
+
+	It("finds the next minute matching every field", func() {
+		Expect(next("30 3 * * *", at(2026, 10, 19, 2, 0))).To(Equal(at(2026, 10, 19, 3, 30)))
+		Expect(next("30 3 * * *", at(2026, 10, 19, 3, 30))).To(Equal(at(2026, 10, 20, 3, 30)))
+		Expect(next("* * * * *", at(2026, 10, 19, 3, 30).Add(10*time.Second))).To(Equal(at(2026, 10, 19, 3, 31)))
+	})
+
+	It("supports lists, ranges and steps", func() {
+		Expect(next("*/15 * * * *", at(2026, 10, 19, 3, 16))).To(Equal(at(2026, 10, 19, 3, 30)))
+		Expect(next("0 9-17/4 * * *", at(2026, 10, 19, 14, 0))).To(Equal(at(2026, 10, 19, 17, 0)))
+		Expect(next("0 1,13 * * *", at(2026, 10, 19, 2, 0))).To(Equal(at(2026, 10, 19, 13, 0)))
+		Expect(next("5/20 * * * *", at(2026, 10, 19, 3, 26))).To(Equal(at(2026, 10, 19, 3, 45)))
+	})
+
+	It("supports month and weekday names", func() {
+		Expect(next("0 0 1 jan *", at(2026, 10, 19, 0, 0))).To(Equal(at(2027, 1, 1, 0, 0)))
+		Expect(next("0 0 * * sat", at(2026, 10, 19, 0, 0))).To(Equal(at(2026, 10, 24, 0, 0)))
+		Expect(next("0 0 * * 7", at(2026, 10, 19, 0, 0))).To(Equal(at(2026, 10, 25, 0, 0)))
+	})
+
+	It("matches either the day of month or the day of week when both are given", func() {
+		Expect(next("0 0 1 * mon", at(2026, 10, 19, 12, 0))).To(Equal(at(2026, 10, 26, 0, 0)))
+		Expect(next("0 0 1 * mon", at(2026, 10, 27, 12, 0))).To(Equal(at(2026, 11, 1, 0, 0)))
+	})
+
+	It("supports the macros", func() {
+		Expect(next("@daily", at(2026, 10, 19, 12, 0))).To(Equal(at(2026, 10, 20, 0, 0)))
+		Expect(next("@hourly", at(2026, 10, 19, 12, 0))).To(Equal(at(2026, 10, 19, 13, 0)))
+		Expect(next("@weekly", at(2026, 10, 19, 12, 0))).To(Equal(at(2026, 10, 25, 0, 0)))
+		Expect(next("@monthly", at(2026, 10, 19, 12, 0))).To(Equal(at(2026, 11, 1, 0, 0)))
+	})
+
+	It("skips months without the day", func() {
+		Expect(next("0 0 31 * *", at(2026, 11, 1, 0, 0))).To(Equal(at(2026, 12, 31, 0, 0)))
+	})
+
+	DescribeTable("invalid expressions",
+		func(expression, message string) {
+			_, err := daemon.ParseSchedule(expression)
+			Expect(err).To(MatchError(message))
+		},
+		Entry("too few fields", "0 3 * *", "it must have 5 fields: minute hour day-of-month month day-of-week"),
+		Entry("out of range", "60 3 * * *", "invalid minute '60'"),
+		Entry("unknown name", "0 3 * foo *", "invalid month 'foo'"),
+		Entry("reversed range", "0 5-3 * * *", "invalid hour range '5-3'"),
+		Entry("invalid step", "*/0 * * * *", "invalid minute step '0'"),
+	)
+})
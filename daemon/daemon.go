@@ -0,0 +1,228 @@
+package daemon
+
+import (
+	"math/rand"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/tscolari/s3kup/log"
+)
+
+const maxMissedRuns = 1000
+
+type Executor interface {
+	Execute(job Job) error
+}
+
+type Daemon struct {
+	jobsPath  string
+	statePath string
+	executor  Executor
+
+	mutex   sync.Mutex
+	jobs    []Job
+	state   State
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+func New(jobsPath, statePath string, executor Executor) *Daemon {
+	return &Daemon{
+		jobsPath:  jobsPath,
+		statePath: statePath,
+		executor:  executor,
+		running:   map[string]bool{},
+	}
+}
+
+func (d *Daemon) Run(signals <-chan os.Signal) error {
+	err := d.Reload(time.Now())
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			d.Tick(now)
+
+		case signal := <-signals:
+			if signal == syscall.SIGHUP {
+				if err := d.Reload(time.Now()); err != nil {
+					log.Warn("Failed to reload the jobs, keeping the current ones:", err)
+				}
+				continue
+			}
+
+			log.Info("Stopping, waiting for the running jobs to finish")
+			d.Wait()
+			return nil
+		}
+	}
+}
+
+func (d *Daemon) Reload(now time.Time) error {
+	jobs, err := LoadJobs(d.jobsPath)
+	if err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.state == nil {
+		state, err := LoadState(d.statePath)
+		if err != nil {
+			return err
+		}
+		d.state = interruptedRuns(state)
+	}
+
+	previousJobs := map[string]Job{}
+	for _, job := range d.jobs {
+		previousJobs[job.Name] = job
+	}
+
+	for _, job := range jobs {
+		jobState := d.state[job.Name]
+		previousJob, known := previousJobs[job.Name]
+		if known && previousJob.sameSchedule(job) {
+			continue
+		}
+
+		if missed := missedRuns(job, jobState.NextRun, now); missed > 0 {
+			log.Warn("Missed", missed, "runs of", job.Name, "since", jobState.NextRun.Format(time.RFC3339))
+			jobState.Missed += missed
+		}
+
+		jobState.NextRun = nextRun(job, now)
+		d.state[job.Name] = jobState
+		log.Info("Scheduled", job.Name, "at", jobState.NextRun.Format(time.RFC3339))
+	}
+
+	d.jobs = jobs
+	d.save()
+	log.Info("Loaded", len(jobs), "jobs from", d.jobsPath)
+	return nil
+}
+
+func (d *Daemon) Tick(now time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	changed := false
+	for _, job := range d.jobs {
+		jobState := d.state[job.Name]
+		if jobState.NextRun.IsZero() || now.Before(jobState.NextRun) {
+			continue
+		}
+
+		changed = true
+		jobState.NextRun = nextRun(job, now)
+		if d.running[job.Name] {
+			log.Warn("Skipping a run of", job.Name, "as the previous run is still running")
+			jobState.Skipped++
+			d.state[job.Name] = jobState
+			continue
+		}
+
+		log.Info("Starting", job.Name)
+		jobState.LastStart = now
+		jobState.LastStatus = StatusRunning
+		jobState.Runs++
+		d.state[job.Name] = jobState
+
+		d.running[job.Name] = true
+		d.wg.Add(1)
+		go d.execute(job)
+	}
+
+	if changed {
+		d.save()
+	}
+}
+
+func (d *Daemon) Wait() {
+	d.wg.Wait()
+}
+
+func (d *Daemon) State() State {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	state := State{}
+	for name, jobState := range d.state {
+		state[name] = jobState
+	}
+
+	return state
+}
+
+func (d *Daemon) execute(job Job) {
+	defer d.wg.Done()
+	err := d.executor.Execute(job)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	jobState := d.state[job.Name]
+	jobState.LastEnd = time.Now()
+	if err != nil {
+		log.Warn("Job", job.Name, "failed:", err)
+		jobState.LastStatus = StatusFailed
+		jobState.LastError = err.Error()
+		jobState.Failures++
+	} else {
+		log.Info("Job", job.Name, "succeeded")
+		jobState.LastStatus = StatusSucceeded
+		jobState.LastError = ""
+		jobState.LastSuccess = jobState.LastEnd
+	}
+
+	d.state[job.Name] = jobState
+	delete(d.running, job.Name)
+	d.save()
+}
+
+func (d *Daemon) save() {
+	if err := d.state.Save(d.statePath); err != nil {
+		log.Warn("Failed to save the state to", d.statePath, err)
+	}
+}
+
+func interruptedRuns(state State) State {
+	for name, jobState := range state {
+		if jobState.LastStatus == StatusRunning {
+			jobState.LastStatus = StatusFailed
+			jobState.LastError = "The daemon stopped while the job was running"
+			jobState.Failures++
+			state[name] = jobState
+		}
+	}
+
+	return state
+}
+
+func missedRuns(job Job, scheduled, now time.Time) int {
+	missed := 0
+	for !scheduled.IsZero() && scheduled.Before(now) && missed < maxMissedRuns {
+		missed++
+		scheduled = job.schedule.Next(scheduled)
+	}
+
+	return missed
+}
+
+func nextRun(job Job, now time.Time) time.Time {
+	next := job.schedule.Next(now)
+	if job.Jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(job.Jitter))))
+	}
+
+	return next
+}
@@ -0,0 +1,200 @@
+package daemon_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tscolari/s3kup/daemon"
+	"github.com/tscolari/s3kup/daemon/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Daemon", func() {
+	var dir string
+	var jobsPath string
+	var statePath string
+	var executor *fakes.FakeExecutor
+	var d *daemon.Daemon
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.Local)
+	}
+
+	writeJobs := func(content string) {
+		Expect(ioutil.WriteFile(jobsPath, []byte(content), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "s3kup-daemon")
+		Expect(err).ToNot(HaveOccurred())
+
+		jobsPath = filepath.Join(dir, "jobs.yaml")
+		statePath = filepath.Join(dir, "state", "daemon.json")
+		writeJobs(`
+jobs:
+  - name: pg-prod
+    schedule: "0 3 * * *"
+    profile: pg-prod
+    command: [pg_dump, mydb]
+`)
+
+		executor = new(fakes.FakeExecutor)
+		d = daemon.New(jobsPath, statePath, executor)
+	})
+
+	AfterEach(func() {
+		d.Wait()
+		os.RemoveAll(dir)
+	})
+
+	It("schedules the jobs and saves the state", func() {
+		Expect(d.Reload(at(19, 2, 0))).To(Succeed())
+		Expect(d.State()["pg-prod"].NextRun).To(Equal(at(19, 3, 0)))
+
+		state, err := daemon.LoadState(statePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state["pg-prod"].NextRun).To(BeTemporally("==", at(19, 3, 0)))
+	})
+
+	It("runs the jobs when they are due", func() {
+		Expect(d.Reload(at(19, 2, 0))).To(Succeed())
+
+		d.Tick(at(19, 2, 59))
+		d.Wait()
+		Expect(executor.ExecuteCallCount()).To(Equal(0))
+
+		d.Tick(at(19, 3, 0))
+		d.Wait()
+		Expect(executor.ExecuteCallCount()).To(Equal(1))
+		Expect(executor.ExecuteArgsForCall(0).Name).To(Equal("pg-prod"))
+		Expect(executor.ExecuteArgsForCall(0).Command).To(Equal([]string{"pg_dump", "mydb"}))
+
+		jobState := d.State()["pg-prod"]
+		Expect(jobState.LastStart).To(Equal(at(19, 3, 0)))
+		Expect(jobState.LastStatus).To(Equal(daemon.StatusSucceeded))
+		Expect(jobState.LastSuccess).To(Equal(jobState.LastEnd))
+		Expect(jobState.Runs).To(Equal(1))
+		Expect(jobState.NextRun).To(Equal(at(20, 3, 0)))
+	})
+
+	It("records failed runs", func() {
+		executor.ExecuteReturns(errors.New("pg_dump failed"))
+		Expect(d.Reload(at(19, 2, 0))).To(Succeed())
+
+		d.Tick(at(19, 3, 0))
+		d.Wait()
+
+		jobState := d.State()["pg-prod"]
+		Expect(jobState.LastStatus).To(Equal(daemon.StatusFailed))
+		Expect(jobState.LastError).To(Equal("pg_dump failed"))
+		Expect(jobState.LastSuccess.IsZero()).To(BeTrue())
+		Expect(jobState.Failures).To(Equal(1))
+	})
+
+	It("doesn't overlap runs of the same job", func() {
+		release := make(chan struct{})
+		executor.ExecuteStub = func(job daemon.Job) error {
+			<-release
+			return nil
+		}
+		Expect(d.Reload(at(19, 2, 0))).To(Succeed())
+
+		d.Tick(at(19, 3, 0))
+		d.Tick(at(20, 3, 0))
+		Expect(d.State()["pg-prod"].Skipped).To(Equal(1))
+		Expect(d.State()["pg-prod"].LastStatus).To(Equal(daemon.StatusRunning))
+
+		close(release)
+		d.Wait()
+		Expect(executor.ExecuteCallCount()).To(Equal(1))
+		Expect(d.State()["pg-prod"].NextRun).To(Equal(at(21, 3, 0)))
+	})
+
+	It("adds the jitter to the scheduled time", func() {
+		writeJobs("jobs:\n  - name: pg-prod\n    schedule: '0 3 * * *'\n    command: [pg_dump]\n    jitter: 10m\n")
+		Expect(d.Reload(at(19, 2, 0))).To(Succeed())
+
+		nextRun := d.State()["pg-prod"].NextRun
+		Expect(nextRun).To(BeTemporally(">=", at(19, 3, 0)))
+		Expect(nextRun).To(BeTemporally("<", at(19, 3, 10)))
+	})
+
+	Context("when the daemon is restarted", func() {
+		BeforeEach(func() {
+			Expect(d.Reload(at(19, 2, 0))).To(Succeed())
+			d.Tick(at(19, 3, 0))
+			d.Wait()
+		})
+
+		It("keeps the state and counts the missed runs", func() {
+			restarted := daemon.New(jobsPath, statePath, executor)
+			Expect(restarted.Reload(at(22, 12, 0))).To(Succeed())
+
+			jobState := restarted.State()["pg-prod"]
+			Expect(jobState.Runs).To(Equal(1))
+			Expect(jobState.Missed).To(Equal(3))
+			Expect(jobState.NextRun).To(Equal(at(23, 3, 0)))
+		})
+
+		It("marks the interrupted runs as failed", func() {
+			state, err := daemon.LoadState(statePath)
+			Expect(err).ToNot(HaveOccurred())
+			jobState := state["pg-prod"]
+			jobState.LastStatus = daemon.StatusRunning
+			state["pg-prod"] = jobState
+			Expect(state.Save(statePath)).To(Succeed())
+
+			restarted := daemon.New(jobsPath, statePath, executor)
+			Expect(restarted.Reload(at(19, 12, 0))).To(Succeed())
+
+			Expect(restarted.State()["pg-prod"].LastStatus).To(Equal(daemon.StatusFailed))
+			Expect(restarted.State()["pg-prod"].LastError).To(Equal("The daemon stopped while the job was running"))
+			Expect(restarted.State()["pg-prod"].Failures).To(Equal(1))
+		})
+	})
+
+	Context("when the jobs are reloaded", func() {
+		BeforeEach(func() {
+			writeJobs("jobs:\n  - name: pg-prod\n    schedule: '0 3 * * *'\n    command: [pg_dump]\n    jitter: 10m\n")
+			Expect(d.Reload(at(19, 2, 0))).To(Succeed())
+		})
+
+		It("schedules the new and changed jobs and keeps the others", func() {
+			nextRun := d.State()["pg-prod"].NextRun
+
+			writeJobs(`
+jobs:
+  - name: pg-prod
+    schedule: "0 3 * * *"
+    command: [pg_dump, --verbose]
+    jitter: 10m
+  - name: mysql
+    schedule: "30 2 * * *"
+    command: [mysqldump]
+`)
+			Expect(d.Reload(at(19, 2, 10))).To(Succeed())
+			Expect(d.State()["pg-prod"].NextRun).To(Equal(nextRun))
+			Expect(d.State()["mysql"].NextRun).To(Equal(at(19, 2, 30)))
+
+			d.Tick(at(19, 3, 10))
+			d.Wait()
+			Expect(executor.ExecuteCallCount()).To(Equal(2))
+		})
+
+		It("keeps the current jobs when the file is invalid", func() {
+			writeJobs("jobs: [")
+
+			Expect(d.Reload(at(19, 2, 10))).To(MatchError(HavePrefix("Invalid jobs file")))
+
+			d.Tick(at(19, 3, 10))
+			d.Wait()
+			Expect(executor.ExecuteCallCount()).To(Equal(1))
+		})
+	})
+})
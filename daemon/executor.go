@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/tscolari/s3kup/runner"
+)
+
+type ProcessExecutor struct {
+	executable string
+	args       []string
+	env        []string
+}
+
+// NewProcessExecutor gives env to the runs on top of the daemon's own
+// environment, keeping secrets out of their command lines.
+func NewProcessExecutor(executable string, args []string, env []string) ProcessExecutor {
+	return ProcessExecutor{
+		executable: executable,
+		args:       args,
+		env:        env,
+	}
+}
+
+func (e ProcessExecutor) Execute(job Job) error {
+	return runner.New(0, nil, os.Stderr).WithEnv(e.env).Run(e.Command(job), func(stdout io.Reader) error {
+		_, err := io.Copy(ioutil.Discard, stdout)
+		return err
+	})
+}
+
+func (e ProcessExecutor) Command(job Job) []string {
+	command := append([]string{e.executable, "run"}, e.args...)
+	if job.Profile != "" {
+		command = append(command, "--profile", job.Profile)
+	}
+
+	for _, label := range job.Labels {
+		command = append(command, "--label", label)
+	}
+
+	if job.Timeout > 0 {
+		command = append(command, "--timeout", job.Timeout.String())
+	}
+
+	return append(append(command, "--"), job.Command...)
+}
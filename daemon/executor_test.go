@@ -0,0 +1,66 @@
+package daemon_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tscolari/s3kup/daemon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProcessExecutor", func() {
+	It("runs the job with the run command", func() {
+		executor := daemon.NewProcessExecutor("/usr/bin/s3kup", []string{"--config", "/etc/s3kup.yaml"}, nil)
+		job := daemon.Job{
+			Name:    "pg-prod",
+			Profile: "pg-prod",
+			Command: []string{"pg_dump", "--verbose", "mydb"},
+			Labels:  []string{"kind=nightly"},
+			Timeout: 2 * time.Hour,
+		}
+
+		Expect(executor.Command(job)).To(Equal([]string{
+			"/usr/bin/s3kup", "run", "--config", "/etc/s3kup.yaml",
+			"--profile", "pg-prod", "--label", "kind=nightly", "--timeout", "2h0m0s",
+			"--", "pg_dump", "--verbose", "mydb",
+		}))
+	})
+
+	It("leaves out the options the job doesn't have", func() {
+		executor := daemon.NewProcessExecutor("s3kup", nil, nil)
+
+		Expect(executor.Command(daemon.Job{Command: []string{"true"}})).To(Equal([]string{"s3kup", "run", "--", "true"}))
+	})
+
+	It("returns the error of the run", func() {
+		dir, err := ioutil.TempDir("", "s3kup-executor")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		executable := filepath.Join(dir, "s3kup")
+		script := "#!/bin/sh\necho \"push failed: $*\" >&2\nexit 1\n"
+		Expect(ioutil.WriteFile(executable, []byte(script), 0700)).To(Succeed())
+
+		executor := daemon.NewProcessExecutor(executable, nil, nil)
+		err = executor.Execute(daemon.Job{Command: []string{"true"}})
+		Expect(err).To(MatchError("Command '" + executable + "' exited with status 1: push failed: run -- true"))
+	})
+
+	It("gives the env to the run", func() {
+		dir, err := ioutil.TempDir("", "s3kup-executor")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		executable := filepath.Join(dir, "s3kup")
+		script := "#!/bin/sh\necho \"secret: $S3KUP_SECRET_KEY\" >&2\nexit 1\n"
+		Expect(ioutil.WriteFile(executable, []byte(script), 0700)).To(Succeed())
+
+		executor := daemon.NewProcessExecutor(executable, nil, []string{"S3KUP_SECRET_KEY=my-secret"})
+		err = executor.Execute(daemon.Job{Command: []string{"true"}})
+		Expect(err).To(MatchError("Command '" + executable + "' exited with status 1: secret: my-secret"))
+	})
+})
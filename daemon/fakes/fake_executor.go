@@ -0,0 +1,53 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/tscolari/s3kup/daemon"
+)
+
+type FakeExecutor struct {
+	ExecuteStub        func(job daemon.Job) error
+	executeMutex       sync.RWMutex
+	executeArgsForCall []struct {
+		job daemon.Job
+	}
+	executeReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeExecutor) Execute(job daemon.Job) error {
+	fake.executeMutex.Lock()
+	fake.executeArgsForCall = append(fake.executeArgsForCall, struct {
+		job daemon.Job
+	}{job})
+	fake.executeMutex.Unlock()
+	if fake.ExecuteStub != nil {
+		return fake.ExecuteStub(job)
+	} else {
+		return fake.executeReturns.result1
+	}
+}
+
+func (fake *FakeExecutor) ExecuteCallCount() int {
+	fake.executeMutex.RLock()
+	defer fake.executeMutex.RUnlock()
+	return len(fake.executeArgsForCall)
+}
+
+func (fake *FakeExecutor) ExecuteArgsForCall(i int) daemon.Job {
+	fake.executeMutex.RLock()
+	defer fake.executeMutex.RUnlock()
+	return fake.executeArgsForCall[i].job
+}
+
+func (fake *FakeExecutor) ExecuteReturns(result1 error) {
+	fake.ExecuteStub = nil
+	fake.executeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ daemon.Executor = new(FakeExecutor)
@@ -0,0 +1,98 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/tscolari/s3kup/config"
+	"github.com/tscolari/s3kup/s3"
+	"gopkg.in/yaml.v2"
+)
+
+var jobNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+type Job struct {
+	Name     string        `yaml:"name"`
+	Schedule string        `yaml:"schedule"`
+	Profile  string        `yaml:"profile"`
+	Command  []string      `yaml:"command"`
+	Labels   []string      `yaml:"labels"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Jitter   time.Duration `yaml:"jitter"`
+
+	schedule Schedule
+}
+
+type jobsFile struct {
+	Jobs []Job `yaml:"jobs"`
+}
+
+func DefaultJobsPath() string {
+	return filepath.Join(filepath.Dir(config.DefaultPath()), "jobs.yaml")
+}
+
+func LoadJobs(path string) ([]Job, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file jobsFile
+	if err := yaml.UnmarshalStrict(content, &file); err != nil {
+		return nil, fmt.Errorf("Invalid jobs file '%s': %s", path, err)
+	}
+
+	if len(file.Jobs) == 0 {
+		return nil, fmt.Errorf("The jobs file '%s' has no jobs", path)
+	}
+
+	names := map[string]bool{}
+	for i := range file.Jobs {
+		job := &file.Jobs[i]
+		err := job.validate()
+		if err != nil {
+			return nil, err
+		}
+
+		if names[job.Name] {
+			return nil, fmt.Errorf("The job '%s' is defined more than once", job.Name)
+		}
+		names[job.Name] = true
+	}
+
+	return file.Jobs, nil
+}
+
+func (j *Job) validate() error {
+	if !jobNamePattern.MatchString(j.Name) {
+		return fmt.Errorf("Invalid job name '%s'. It can only contain letters, numbers, '.', '_' and '-'", j.Name)
+	}
+
+	schedule, err := ParseSchedule(j.Schedule)
+	if err != nil {
+		return fmt.Errorf("Invalid schedule '%s' of job '%s': %s", j.Schedule, j.Name, err)
+	}
+	j.schedule = schedule
+
+	if len(j.Command) == 0 {
+		return errors.New("The job '" + j.Name + "' has no command")
+	}
+
+	if _, err := s3.ParseLabels(j.Labels); err != nil {
+		return fmt.Errorf("Job '%s': %s", j.Name, err)
+	}
+
+	if j.Timeout < 0 || j.Jitter < 0 {
+		return errors.New("The timeout and jitter of job '" + j.Name + "' can't be negative")
+	}
+
+	return nil
+}
+
+func (j Job) sameSchedule(other Job) bool {
+	return j.Schedule == other.Schedule && j.Jitter == other.Jitter
+}
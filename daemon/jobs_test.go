@@ -0,0 +1,84 @@
+package daemon_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tscolari/s3kup/daemon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Jobs", func() {
+	var dir string
+	var jobsPath string
+
+	writeJobs := func(content string) {
+		Expect(ioutil.WriteFile(jobsPath, []byte(content), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "s3kup-jobs")
+		Expect(err).ToNot(HaveOccurred())
+		jobsPath = filepath.Join(dir, "jobs.yaml")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("reads the jobs", func() {
+		writeJobs(`
+jobs:
+  - name: pg-prod
+    schedule: "0 3 * * *"
+    profile: pg-prod
+    command: [pg_dump, mydb]
+    labels: [kind=nightly]
+    timeout: 2h
+    jitter: 10m
+  - name: mysql
+    schedule: "@hourly"
+    command: [mysqldump, --all-databases]
+`)
+
+		jobs, err := daemon.LoadJobs(jobsPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(2))
+
+		Expect(jobs[0].Name).To(Equal("pg-prod"))
+		Expect(jobs[0].Schedule).To(Equal("0 3 * * *"))
+		Expect(jobs[0].Profile).To(Equal("pg-prod"))
+		Expect(jobs[0].Command).To(Equal([]string{"pg_dump", "mydb"}))
+		Expect(jobs[0].Labels).To(Equal([]string{"kind=nightly"}))
+		Expect(jobs[0].Timeout).To(Equal(2 * time.Hour))
+		Expect(jobs[0].Jitter).To(Equal(10 * time.Minute))
+		Expect(jobs[1].Name).To(Equal("mysql"))
+	})
+
+	It("fails when there are no jobs", func() {
+		writeJobs("jobs: []")
+
+		_, err := daemon.LoadJobs(jobsPath)
+		Expect(err).To(MatchError("The jobs file '" + jobsPath + "' has no jobs"))
+	})
+
+	DescribeTable("invalid jobs",
+		func(content, message string) {
+			writeJobs(content)
+
+			_, err := daemon.LoadJobs(jobsPath)
+			Expect(err).To(MatchError(message))
+		},
+		Entry("invalid name", "jobs:\n  - name: pg prod\n    schedule: '@daily'\n    command: [true]", "Invalid job name 'pg prod'. It can only contain letters, numbers, '.', '_' and '-'"),
+		Entry("invalid schedule", "jobs:\n  - name: pg\n    schedule: '0 25 * * *'\n    command: [true]", "Invalid schedule '0 25 * * *' of job 'pg': invalid hour '25'"),
+		Entry("no command", "jobs:\n  - name: pg\n    schedule: '@daily'", "The job 'pg' has no command"),
+		Entry("invalid labels", "jobs:\n  - name: pg\n    schedule: '@daily'\n    command: [true]\n    labels: [nightly]", "Job 'pg': Invalid label 'nightly'. It must be in the format key=value"),
+		Entry("duplicated names", "jobs:\n  - name: pg\n    schedule: '@daily'\n    command: [true]\n  - name: pg\n    schedule: '@daily'\n    command: [true]", "The job 'pg' is defined more than once"),
+	)
+})
@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+type JobState struct {
+	LastStart   time.Time `json:"last_start"`
+	LastEnd     time.Time `json:"last_end"`
+	LastSuccess time.Time `json:"last_success"`
+	LastStatus  string    `json:"last_status"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextRun     time.Time `json:"next_run"`
+	Runs        int       `json:"runs"`
+	Failures    int       `json:"failures"`
+	Skipped     int       `json:"skipped"`
+	Missed      int       `json:"missed"`
+}
+
+type State map[string]JobState
+
+func DefaultStatePath() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(stateHome, "s3kup", "daemon.json")
+}
+
+func LoadState(path string) (State, error) {
+	state := State{}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("Invalid state file '%s': %s", path, err)
+	}
+
+	return state, nil
+}
+
+func (s State) Save(path string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(append(content, '\n'))
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), path)
+}
@@ -0,0 +1,165 @@
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"unicode/utf8"
+)
+
+const chunkSize = 64 * 1024
+
+type Range struct {
+	Start uint64
+	End   uint64
+}
+
+func (r Range) Size() uint64 {
+	return r.End - r.Start
+}
+
+// Changes keeps the first ranges that differ between two contents, and only
+// counts the others.
+type Changes struct {
+	Ranges []Range
+	Count  int
+	Bytes  uint64
+	SizeA  uint64
+	SizeB  uint64
+}
+
+func (c *Changes) add(start, end uint64) {
+	c.Count++
+	c.Bytes += end - start
+	if len(c.Ranges) < maxListedRanges {
+		c.Ranges = append(c.Ranges, Range{Start: start, End: end})
+	}
+}
+
+// Decompress detects gzip and bzip2 from the first bytes of the content, and
+// returns other content as it is.
+func Decompress(r io.Reader) (io.Reader, string, error) {
+	buffered := bufio.NewReader(r)
+	magic, _ := buffered.Peek(3)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed to decompress the gzip content: %w", err)
+		}
+		return decompressReader{Reader: gzipReader, format: "gzip"}, "gzip", nil
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return decompressReader{Reader: bzip2.NewReader(buffered), format: "bzip2"}, "bzip2", nil
+	default:
+		return buffered, "", nil
+	}
+}
+
+type decompressReader struct {
+	io.Reader
+	format string
+}
+
+func (r decompressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("Failed to decompress the %s content: %w", r.format, err)
+	}
+	return n, err
+}
+
+func IsText(content []byte) bool {
+	return bytes.IndexByte(content, 0) < 0 && utf8.Valid(content)
+}
+
+// Compare reads both contents in chunks, so only two chunks are held in
+// memory however large they are.
+func Compare(a, b io.Reader) (Changes, error) {
+	changes := Changes{Ranges: []Range{}}
+	chunkA := make([]byte, chunkSize)
+	chunkB := make([]byte, chunkSize)
+
+	var offset, start uint64
+	open := false
+	for {
+		sizeA, err := readChunk(a, chunkA)
+		if err != nil {
+			return Changes{}, err
+		}
+
+		sizeB, err := readChunk(b, chunkB)
+		if err != nil {
+			return Changes{}, err
+		}
+
+		common := sizeA
+		if sizeB < common {
+			common = sizeB
+		}
+
+		if open || !bytes.Equal(chunkA[:common], chunkB[:common]) {
+			for i := 0; i < common; i++ {
+				if chunkA[i] != chunkB[i] {
+					if !open {
+						start, open = offset+uint64(i), true
+					}
+					continue
+				}
+
+				if open {
+					changes.add(start, offset+uint64(i))
+					open = false
+				}
+			}
+		}
+		offset += uint64(common)
+
+		if sizeA == chunkSize && sizeB == chunkSize {
+			continue
+		}
+
+		changes.SizeA = offset + uint64(sizeA-common)
+		changes.SizeB = offset + uint64(sizeB-common)
+		if sizeA == chunkSize {
+			rest, err := io.Copy(ioutil.Discard, a)
+			if err != nil {
+				return Changes{}, err
+			}
+			changes.SizeA += uint64(rest)
+		}
+		if sizeB == chunkSize {
+			rest, err := io.Copy(ioutil.Discard, b)
+			if err != nil {
+				return Changes{}, err
+			}
+			changes.SizeB += uint64(rest)
+		}
+		break
+	}
+
+	end := changes.SizeA
+	if changes.SizeB > end {
+		end = changes.SizeB
+	}
+
+	if open || offset < end {
+		if !open {
+			start = offset
+		}
+		changes.add(start, end)
+	}
+
+	return changes, nil
+}
+
+func readChunk(r io.Reader, chunk []byte) (int, error) {
+	n, err := io.ReadFull(r, chunk)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return n, err
+}
@@ -0,0 +1,141 @@
+package diff_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"testing/iotest"
+
+	"github.com/tscolari/s3kup/diff"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Content", func() {
+	Describe("Decompress", func() {
+		It("decompresses gzip content", func() {
+			compressed := new(bytes.Buffer)
+			writer := gzip.NewWriter(compressed)
+			writer.Write([]byte("my dump\n"))
+			Expect(writer.Close()).To(Succeed())
+
+			reader, format, err := diff.Decompress(bytes.NewReader(compressed.Bytes()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(format).To(Equal("gzip"))
+			Expect(ioutil.ReadAll(reader)).To(Equal([]byte("my dump\n")))
+		})
+
+		It("decompresses bzip2 content", func() {
+			// echo 'my dump' | bzip2 -c | base64
+			compressed, err := base64.StdEncoding.DecodeString("QlpoOTFBWSZTWS6gy2EAAAJRgAAQQAAEAkIgIAAiGmNQhgIMgLxdyRThQkC6gy2E")
+			Expect(err).ToNot(HaveOccurred())
+
+			reader, format, err := diff.Decompress(bytes.NewReader(compressed))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(format).To(Equal("bzip2"))
+			Expect(ioutil.ReadAll(reader)).To(Equal([]byte("my dump\n")))
+		})
+
+		It("returns other content as it is", func() {
+			reader, format, err := diff.Decompress(bytes.NewReader([]byte("my dump\n")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(format).To(BeEmpty())
+			Expect(ioutil.ReadAll(reader)).To(Equal([]byte("my dump\n")))
+		})
+
+		It("fails on a corrupted header", func() {
+			_, _, err := diff.Decompress(bytes.NewReader([]byte{0x1f, 0x8b, 0x08, 0x00, 0x01}))
+			Expect(err).To(MatchError(ContainSubstring("Failed to decompress the gzip content")))
+		})
+
+		It("fails on corrupted content while reading", func() {
+			compressed := new(bytes.Buffer)
+			writer := gzip.NewWriter(compressed)
+			writer.Write([]byte("my dump\n"))
+			Expect(writer.Close()).To(Succeed())
+
+			reader, _, err := diff.Decompress(bytes.NewReader(compressed.Bytes()[:15]))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = ioutil.ReadAll(reader)
+			Expect(err).To(MatchError(ContainSubstring("Failed to decompress the gzip content")))
+		})
+	})
+
+	Describe("IsText", func() {
+		It("accepts utf-8 content", func() {
+			Expect(diff.IsText([]byte("créme brûlée\n"))).To(BeTrue())
+			Expect(diff.IsText([]byte{})).To(BeTrue())
+		})
+
+		It("rejects content with NUL bytes or invalid utf-8", func() {
+			Expect(diff.IsText([]byte("my\x00dump"))).To(BeFalse())
+			Expect(diff.IsText([]byte{0xff, 0xfe, 'a'})).To(BeFalse())
+		})
+	})
+
+	Describe("Compare", func() {
+		compare := func(a, b []byte) []diff.Range {
+			changes, err := diff.Compare(bytes.NewReader(a), bytes.NewReader(b))
+			Expect(err).ToNot(HaveOccurred())
+			return changes.Ranges
+		}
+
+		It("returns the ranges that differ", func() {
+			changes, err := diff.Compare(bytes.NewReader([]byte("aaaaaaaaaa")), bytes.NewReader([]byte("abbaaaacaa")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(Equal(diff.Changes{
+				Ranges: []diff.Range{{Start: 1, End: 3}, {Start: 7, End: 8}},
+				Count:  2,
+				Bytes:  3,
+				SizeA:  10,
+				SizeB:  10,
+			}))
+			Expect(changes.Ranges[0].Size()).To(Equal(uint64(2)))
+		})
+
+		It("includes the tail of the longer content", func() {
+			Expect(compare([]byte("aaaa"), []byte("aaaabb"))).To(Equal([]diff.Range{{Start: 4, End: 6}}))
+			Expect(compare([]byte("aaab"), []byte("aa"))).To(Equal([]diff.Range{{Start: 2, End: 4}}))
+		})
+
+		It("joins a difference at the end of the common part with the tail", func() {
+			Expect(compare([]byte("aaab"), []byte("aaacbb"))).To(Equal([]diff.Range{{Start: 3, End: 6}}))
+		})
+
+		It("returns no ranges for equal contents", func() {
+			Expect(compare([]byte("aaaa"), []byte("aaaa"))).To(BeEmpty())
+		})
+
+		It("follows ranges across chunks", func() {
+			a := bytes.Repeat([]byte{0}, 3*64*1024)
+			b := bytes.Repeat([]byte{0}, 3*64*1024+10)
+			for i := 60 * 1024; i < 130*1024; i++ {
+				b[i] = 1
+			}
+
+			changes, err := diff.Compare(bytes.NewReader(a), bytes.NewReader(b))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes.Ranges).To(Equal([]diff.Range{
+				{Start: 60 * 1024, End: 130 * 1024},
+				{Start: 3 * 64 * 1024, End: 3*64*1024 + 10},
+			}))
+			Expect(changes.SizeB).To(Equal(uint64(3*64*1024 + 10)))
+		})
+
+		It("only keeps the first ranges, counting the others", func() {
+			changes, err := diff.Compare(bytes.NewReader(bytes.Repeat([]byte{0, 1}, 30)), bytes.NewReader(bytes.Repeat([]byte{0, 2}, 30)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes.Ranges).To(HaveLen(20))
+			Expect(changes.Count).To(Equal(30))
+			Expect(changes.Bytes).To(Equal(uint64(30)))
+		})
+
+		It("forwards the errors of the readers", func() {
+			_, err := diff.Compare(bytes.NewReader([]byte("aaaa")), iotest.ErrReader(errors.New("connection reset")))
+			Expect(err).To(MatchError("connection reset"))
+		})
+	})
+})
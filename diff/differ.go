@@ -0,0 +1,219 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+const (
+	DefaultMaxTextSize = 16 * 1024 * 1024
+	maxListedRanges    = 20
+)
+
+type Differ struct {
+	s3      S3Client
+	options Options
+}
+
+type S3Client interface {
+	Stat(path string) (info s3.ObjectInfo, err error)
+	GetFrom(path string, offset uint64) (body io.ReadCloser, start uint64, err error)
+}
+
+type Options struct {
+	Decompress  bool
+	Context     int
+	MaxTextSize uint64
+}
+
+type Target struct {
+	Label string
+	Path  string
+}
+
+func New(client S3Client, options Options) Differ {
+	return Differ{
+		s3:      client,
+		options: options,
+	}
+}
+
+func (d Differ) Diff(w io.Writer, a, b Target) (bool, error) {
+	infoA, err := d.s3.Stat(a.Path)
+	if err != nil {
+		return false, err
+	}
+
+	infoB, err := d.s3.Stat(b.Path)
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(w, "a: %s (%s, %d bytes)\n", a.Path, infoA.LastModified.Format(time.ANSIC), infoA.Size)
+	fmt.Fprintf(w, "b: %s (%s, %d bytes)\n", b.Path, infoB.LastModified.Format(time.ANSIC), infoB.Size)
+
+	changes := metadataChanges(infoA, infoB)
+	for _, change := range changes {
+		fmt.Fprintln(w, change)
+	}
+
+	if infoA.Size == infoB.Size && infoA.ETag != "" && strings.EqualFold(infoA.ETag, infoB.ETag) {
+		fmt.Fprintln(w, "The contents are identical")
+		return len(changes) > 0, nil
+	}
+
+	log.Info("Comparing", a.Path, "and", b.Path)
+	readerA, err := d.open(a.Path)
+	if err != nil {
+		return false, err
+	}
+	defer readerA.Close()
+
+	readerB, err := d.open(b.Path)
+	if err != nil {
+		return false, err
+	}
+	defer readerB.Close()
+
+	var contentA, contentB io.Reader = readerA, readerB
+	if infoA.Size > d.options.MaxTextSize || infoB.Size > d.options.MaxTextSize {
+		d.writeTooLarge(w)
+	} else {
+		textA, err := readText(readerA, d.options.MaxTextSize)
+		if err != nil {
+			return false, err
+		}
+
+		textB, err := readText(readerB, d.options.MaxTextSize)
+		if err != nil {
+			return false, err
+		}
+
+		if uint64(len(textA)) > d.options.MaxTextSize || uint64(len(textB)) > d.options.MaxTextSize {
+			d.writeTooLarge(w)
+		} else if !bytes.Equal(textA, textB) && IsText(textA) && IsText(textB) {
+			err = Unified(w, "a/"+a.Label, "b/"+b.Label, textA, textB, d.options.Context)
+			if err != ErrTooManyChanges {
+				return true, err
+			}
+
+			fmt.Fprintf(w, "%s. Showing the changed byte ranges instead\n", ErrTooManyChanges)
+		}
+
+		contentA = io.MultiReader(bytes.NewReader(textA), readerA)
+		contentB = io.MultiReader(bytes.NewReader(textB), readerB)
+	}
+
+	ranges, err := Compare(contentA, contentB)
+	if err != nil {
+		return false, err
+	}
+
+	if ranges.Count == 0 {
+		fmt.Fprintln(w, "The contents are identical")
+		return len(changes) > 0, nil
+	}
+
+	writeRanges(w, ranges)
+	return true, nil
+}
+
+func (d Differ) open(path string) (io.ReadCloser, error) {
+	body, _, err := d.s3.GetFrom(path, 0)
+	if err != nil || !d.options.Decompress {
+		return body, err
+	}
+
+	decompressed, format, err := Decompress(body)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("'%s': %w", path, err)
+	}
+
+	if format != "" {
+		log.Info("Decompressing", path, "from", format)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{decompressed, body}, nil
+}
+
+func (d Differ) writeTooLarge(w io.Writer) {
+	fmt.Fprintf(w, "Contents over %d bytes aren't compared line by line. Showing the changed byte ranges instead\n", d.options.MaxTextSize)
+}
+
+// readText stops one byte past maxSize, so the caller can tell the content
+// was too large and carry on from where it stopped.
+func readText(r io.Reader, maxSize uint64) ([]byte, error) {
+	return ioutil.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+}
+
+func metadataChanges(a, b s3.ObjectInfo) []string {
+	changes := []string{}
+	change := func(field, valueA, valueB string) {
+		if valueA != valueB {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", field, orNone(valueA), orNone(valueB)))
+		}
+	}
+
+	change("Size", fmt.Sprintf("%d bytes", a.Size), fmt.Sprintf("%d bytes", b.Size))
+	change("Checksum", strings.ToLower(a.ETag), strings.ToLower(b.ETag))
+	change("Labels", a.Labels.String(), b.Labels.String())
+	change("Mode", formatMode(a.Attributes), formatMode(b.Attributes))
+	change("Modification time", formatModTime(a.Attributes), formatModTime(b.Attributes))
+	change("Storage class", a.StorageClass, b.StorageClass)
+	change("Encryption", a.Encryption, b.Encryption)
+	change("Content type", a.ContentType, b.ContentType)
+	return changes
+}
+
+func writeRanges(w io.Writer, changes Changes) {
+	fmt.Fprintf(w, "The contents differ in %d byte ranges, %d bytes in total:\n", changes.Count, changes.Bytes)
+	for _, r := range changes.Ranges {
+		note := ""
+		switch {
+		case r.Start >= changes.SizeA:
+			note = " (only in b)"
+		case r.Start >= changes.SizeB:
+			note = " (only in a)"
+		}
+		fmt.Fprintf(w, "  bytes %d-%d%s\n", r.Start, r.End-1, note)
+	}
+
+	if changes.Count > len(changes.Ranges) {
+		fmt.Fprintf(w, "  ... and %d more ranges\n", changes.Count-len(changes.Ranges))
+	}
+}
+
+func formatMode(attributes s3.Attributes) string {
+	if attributes.Mode == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%#o", uint32(attributes.Mode))
+}
+
+func formatModTime(attributes s3.Attributes) string {
+	if attributes.ModTime.IsZero() {
+		return ""
+	}
+
+	return attributes.ModTime.UTC().Format(time.RFC3339)
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+
+	return value
+}
@@ -0,0 +1,235 @@
+package diff_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/tscolari/s3kup/diff"
+	"github.com/tscolari/s3kup/diff/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Differ", func() {
+	var differ diff.Differ
+	var s3Client *fakes.FakeS3Client
+	var output *bytes.Buffer
+	var infos map[string]s3.ObjectInfo
+	var contents map[string][]byte
+
+	a := diff.Target{Label: "1", Path: "my-backup/1"}
+	b := diff.Target{Label: "2", Path: "my-backup/2"}
+	lastModified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	gzipped := func(content string) []byte {
+		compressed := new(bytes.Buffer)
+		writer := gzip.NewWriter(compressed)
+		writer.Write([]byte(content))
+		Expect(writer.Close()).To(Succeed())
+		return compressed.Bytes()
+	}
+
+	store := func(path, etag string, content []byte) {
+		contents[path] = content
+		infos[path] = s3.ObjectInfo{Path: path, Size: uint64(len(content)), ETag: etag, LastModified: lastModified}
+	}
+
+	BeforeEach(func() {
+		output = new(bytes.Buffer)
+		infos = map[string]s3.ObjectInfo{}
+		contents = map[string][]byte{}
+
+		s3Client = new(fakes.FakeS3Client)
+		s3Client.StatStub = func(path string) (s3.ObjectInfo, error) {
+			return infos[path], nil
+		}
+		s3Client.GetFromStub = func(path string, offset uint64) (io.ReadCloser, uint64, error) {
+			return ioutil.NopCloser(bytes.NewReader(contents[path][offset:])), offset, nil
+		}
+
+		differ = diff.New(s3Client, diff.Options{Context: diff.DefaultContext, MaxTextSize: diff.DefaultMaxTextSize})
+	})
+
+	It("doesn't fetch the contents when their checksums are equal", func() {
+		store(a.Path, "etag", []byte("my dump\n"))
+		store(b.Path, "ETAG", []byte("my dump\n"))
+
+		differs, err := differ.Diff(output, a, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(differs).To(BeFalse())
+		Expect(s3Client.GetFromCallCount()).To(BeZero())
+		Expect(output.String()).To(Equal(`a: my-backup/1 (Thu Jan  2 03:04:05 2020, 8 bytes)
+b: my-backup/2 (Thu Jan  2 03:04:05 2020, 8 bytes)
+The contents are identical
+`))
+	})
+
+	It("lists the metadata that changed", func() {
+		store(a.Path, "etag", []byte("my dump\n"))
+		store(b.Path, "etag", []byte("my dump\n"))
+		infoB := infos[b.Path]
+		infoB.Labels = s3.Labels{"env": "prod"}
+		infoB.StorageClass = "GLACIER"
+		infoB.Attributes = s3.Attributes{Mode: 0640}
+		infos[b.Path] = infoB
+
+		differs, err := differ.Diff(output, a, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(differs).To(BeTrue())
+		Expect(output.String()).To(ContainSubstring("Labels: none -> env=prod\n"))
+		Expect(output.String()).To(ContainSubstring("Mode: none -> 0640\n"))
+		Expect(output.String()).To(ContainSubstring("Storage class: none -> GLACIER\n"))
+		Expect(output.String()).ToNot(ContainSubstring("Size:"))
+		Expect(output.String()).To(HaveSuffix("The contents are identical\n"))
+	})
+
+	It("writes a unified diff of text contents", func() {
+		store(a.Path, "etag-a", []byte("one\ntwo\n"))
+		store(b.Path, "etag-b", []byte("one\ntwo\nthree\n"))
+
+		differs, err := differ.Diff(output, a, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(differs).To(BeTrue())
+		path, offset := s3Client.GetFromArgsForCall(0)
+		Expect(path).To(Equal(a.Path))
+		Expect(offset).To(BeZero())
+		path, offset = s3Client.GetFromArgsForCall(1)
+		Expect(path).To(Equal(b.Path))
+		Expect(offset).To(BeZero())
+		Expect(output.String()).To(ContainSubstring("Size: 8 bytes -> 14 bytes\n"))
+		Expect(output.String()).To(ContainSubstring("Checksum: etag-a -> etag-b\n"))
+		Expect(output.String()).To(HaveSuffix(`--- a/1
++++ b/2
+@@ -1,2 +1,3 @@
+ one
+ two
++three
+`))
+	})
+
+	It("reports equal contents with different checksums as identical", func() {
+		store(a.Path, "etag-a", []byte("my dump\n"))
+		store(b.Path, "etag-b-2", []byte("my dump\n"))
+
+		differs, err := differ.Diff(output, a, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(differs).To(BeTrue())
+		Expect(output.String()).To(HaveSuffix("The contents are identical\n"))
+	})
+
+	It("summarizes the byte ranges of binary contents", func() {
+		store(a.Path, "etag-a", []byte("\x00\x01\x02\x03\x04\x05"))
+		store(b.Path, "etag-b", []byte("\x00\xff\x02\x03\x04\x05\x06\x07"))
+
+		differs, err := differ.Diff(output, a, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(differs).To(BeTrue())
+		Expect(output.String()).To(HaveSuffix(`The contents differ in 2 byte ranges, 3 bytes in total:
+  bytes 1-1
+  bytes 6-7 (only in b)
+`))
+	})
+
+	It("lists at most 20 byte ranges", func() {
+		store(a.Path, "etag-a", bytes.Repeat([]byte{0, 1}, 30))
+		store(b.Path, "etag-b", bytes.Repeat([]byte{0, 2}, 30))
+
+		_, err := differ.Diff(output, a, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(ContainSubstring("The contents differ in 30 byte ranges, 30 bytes in total:\n"))
+		Expect(strings.Count(output.String(), "  bytes ")).To(Equal(20))
+		Expect(output.String()).To(HaveSuffix("  ... and 10 more ranges\n"))
+	})
+
+	It("compares contents larger than a chunk", func() {
+		contentA := bytes.Repeat([]byte{0}, 200*1024)
+		contentB := bytes.Repeat([]byte{0}, 150*1024)
+		contentB[64*1024-1], contentB[64*1024] = 1, 1
+		store(a.Path, "etag-a", contentA)
+		store(b.Path, "etag-b", contentB)
+
+		_, err := differ.Diff(output, a, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(HaveSuffix(`The contents differ in 2 byte ranges, 51202 bytes in total:
+  bytes 65535-65536
+  bytes 153600-204799 (only in a)
+`))
+	})
+
+	Context("when the contents are larger than the max text size", func() {
+		BeforeEach(func() {
+			store(a.Path, "etag-a", []byte("one\ntwo\n"))
+			store(b.Path, "etag-b", []byte("one\n2\n"))
+			differ = diff.New(s3Client, diff.Options{Context: diff.DefaultContext, MaxTextSize: 7})
+		})
+
+		It("compares the byte ranges instead of the lines", func() {
+			_, err := differ.Diff(output, a, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("Contents over 7 bytes aren't compared line by line. Showing the changed byte ranges instead\n"))
+			Expect(output.String()).To(HaveSuffix(`The contents differ in 1 byte ranges, 4 bytes in total:
+  bytes 4-7
+`))
+		})
+
+		It("carries on from the decompressed bytes that went past the size", func() {
+			store(a.Path, "etag-a", gzipped(strings.Repeat("one\n", 100)+"two\n"))
+			store(b.Path, "etag-b", gzipped(strings.Repeat("one\n", 100)+"2\n"))
+			differ = diff.New(s3Client, diff.Options{Decompress: true, Context: diff.DefaultContext, MaxTextSize: 100})
+
+			_, err := differ.Diff(output, a, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s3Client.GetFromCallCount()).To(Equal(2))
+			Expect(output.String()).To(ContainSubstring("Contents over 100 bytes aren't compared line by line"))
+			Expect(output.String()).To(HaveSuffix(`The contents differ in 1 byte ranges, 4 bytes in total:
+  bytes 400-403
+`))
+		})
+	})
+
+	Context("when decompressing", func() {
+		BeforeEach(func() {
+			store(a.Path, "etag-a", gzipped("one\ntwo\n"))
+			store(b.Path, "etag-b", gzipped("one\n2\n"))
+		})
+
+		It("diffs the decompressed contents", func() {
+			differ = diff.New(s3Client, diff.Options{Decompress: true, Context: diff.DefaultContext, MaxTextSize: diff.DefaultMaxTextSize})
+
+			_, err := differ.Diff(output, a, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.String()).To(HaveSuffix("@@ -1,2 +1,2 @@\n one\n-two\n+2\n"))
+		})
+
+		It("diffs the compressed bytes without the option", func() {
+			_, err := differ.Diff(output, a, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("byte ranges"))
+		})
+	})
+
+	It("forwards the errors of stat", func() {
+		s3Client.StatStub = nil
+		s3Client.StatReturns(s3.ObjectInfo{}, errors.New("NoSuchKey"))
+
+		_, err := differ.Diff(output, a, b)
+		Expect(err).To(MatchError("NoSuchKey"))
+	})
+
+	It("forwards the errors of get", func() {
+		store(a.Path, "etag-a", []byte("one\n"))
+		store(b.Path, "etag-b", []byte("two\n"))
+		s3Client.GetFromStub = nil
+		s3Client.GetFromReturns(nil, 0, errors.New("AccessDenied"))
+
+		_, err := differ.Diff(output, a, b)
+		Expect(err).To(MatchError("AccessDenied"))
+	})
+})
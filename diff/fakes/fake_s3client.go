@@ -0,0 +1,103 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"io"
+	"sync"
+
+	"github.com/tscolari/s3kup/diff"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type FakeS3Client struct {
+	StatStub        func(path string) (info s3.ObjectInfo, err error)
+	statMutex       sync.RWMutex
+	statArgsForCall []struct {
+		path string
+	}
+	statReturns struct {
+		result1 s3.ObjectInfo
+		result2 error
+	}
+	GetFromStub        func(path string, offset uint64) (body io.ReadCloser, start uint64, err error)
+	getFromMutex       sync.RWMutex
+	getFromArgsForCall []struct {
+		path   string
+		offset uint64
+	}
+	getFromReturns struct {
+		result1 io.ReadCloser
+		result2 uint64
+		result3 error
+	}
+}
+
+func (fake *FakeS3Client) Stat(path string) (info s3.ObjectInfo, err error) {
+	fake.statMutex.Lock()
+	fake.statArgsForCall = append(fake.statArgsForCall, struct {
+		path string
+	}{path})
+	fake.statMutex.Unlock()
+	if fake.StatStub != nil {
+		return fake.StatStub(path)
+	} else {
+		return fake.statReturns.result1, fake.statReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) StatCallCount() int {
+	fake.statMutex.RLock()
+	defer fake.statMutex.RUnlock()
+	return len(fake.statArgsForCall)
+}
+
+func (fake *FakeS3Client) StatArgsForCall(i int) string {
+	fake.statMutex.RLock()
+	defer fake.statMutex.RUnlock()
+	return fake.statArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) StatReturns(result1 s3.ObjectInfo, result2 error) {
+	fake.StatStub = nil
+	fake.statReturns = struct {
+		result1 s3.ObjectInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) GetFrom(path string, offset uint64) (body io.ReadCloser, start uint64, err error) {
+	fake.getFromMutex.Lock()
+	fake.getFromArgsForCall = append(fake.getFromArgsForCall, struct {
+		path   string
+		offset uint64
+	}{path, offset})
+	fake.getFromMutex.Unlock()
+	if fake.GetFromStub != nil {
+		return fake.GetFromStub(path, offset)
+	} else {
+		return fake.getFromReturns.result1, fake.getFromReturns.result2, fake.getFromReturns.result3
+	}
+}
+
+func (fake *FakeS3Client) GetFromCallCount() int {
+	fake.getFromMutex.RLock()
+	defer fake.getFromMutex.RUnlock()
+	return len(fake.getFromArgsForCall)
+}
+
+func (fake *FakeS3Client) GetFromArgsForCall(i int) (string, uint64) {
+	fake.getFromMutex.RLock()
+	defer fake.getFromMutex.RUnlock()
+	return fake.getFromArgsForCall[i].path, fake.getFromArgsForCall[i].offset
+}
+
+func (fake *FakeS3Client) GetFromReturns(result1 io.ReadCloser, result2 uint64, result3 error) {
+	fake.GetFromStub = nil
+	fake.getFromReturns = struct {
+		result1 io.ReadCloser
+		result2 uint64
+		result3 error
+	}{result1, result2, result3}
+}
+
+var _ diff.S3Client = new(FakeS3Client)
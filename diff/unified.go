@@ -0,0 +1,251 @@
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	DefaultContext = 3
+	maxLineEdits   = 3000
+)
+
+var ErrTooManyChanges = errors.New("Too many changed lines for a line diff")
+
+type editKind byte
+
+const (
+	editEqual  editKind = ' '
+	editDelete editKind = '-'
+	editInsert editKind = '+'
+)
+
+type edit struct {
+	kind editKind
+	a    int
+	b    int
+}
+
+func Unified(w io.Writer, nameA, nameB string, a, b []byte, context int) error {
+	linesA, linesB := splitLines(a), splitLines(b)
+	edits, ok := lineEdits(linesA, linesB)
+	if !ok {
+		return ErrTooManyChanges
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", nameA, nameB)
+	for _, hunk := range hunks(edits, context) {
+		writeHunk(out, hunk, linesA, linesB)
+	}
+
+	return out.Flush()
+}
+
+func splitLines(content []byte) []string {
+	lines := []string{}
+	for len(content) > 0 {
+		end := bytes.IndexByte(content, '\n') + 1
+		if end == 0 {
+			end = len(content)
+		}
+
+		lines = append(lines, string(content[:end]))
+		content = content[end:]
+	}
+
+	return lines
+}
+
+func lineEdits(a, b []string) ([]edit, bool) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ids := map[string]int{}
+	lineIDs := func(lines []string) []int {
+		result := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			result[i] = id
+		}
+		return result
+	}
+
+	middle, ok := myers(lineIDs(a[prefix:len(a)-suffix]), lineIDs(b[prefix:len(b)-suffix]))
+	if !ok {
+		return nil, false
+	}
+
+	edits := make([]edit, 0, prefix+len(middle)+suffix)
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, edit{kind: editEqual, a: i, b: i})
+	}
+	for _, e := range middle {
+		edits = append(edits, edit{kind: e.kind, a: e.a + prefix, b: e.b + prefix})
+	}
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, edit{kind: editEqual, a: len(a) - i, b: len(b) - i})
+	}
+
+	return edits, true
+}
+
+func myers(a, b []int) ([]edit, bool) {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxLineEdits {
+		maxD = maxLineEdits
+	}
+
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	trace := [][]int{}
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int{}, v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, n, m), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func backtrack(trace [][]int, n, m int) []edit {
+	edits := []edit{}
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{kind: editEqual, a: x, b: y})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{kind: editInsert, a: x, b: prevY})
+			} else {
+				edits = append(edits, edit{kind: editDelete, a: prevX, b: y})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
+
+func hunks(edits []edit, context int) [][]edit {
+	result := [][]edit{}
+	start, end := -1, -1
+	for i, e := range edits {
+		if e.kind == editEqual {
+			continue
+		}
+
+		from := i - context
+		if from < 0 {
+			from = 0
+		}
+		if start >= 0 && from > end {
+			result = append(result, edits[start:end])
+			start = -1
+		}
+		if start < 0 {
+			start = from
+		}
+
+		end = i + context + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+	}
+
+	if start >= 0 {
+		result = append(result, edits[start:end])
+	}
+
+	return result
+}
+
+func writeHunk(w io.Writer, hunk []edit, a, b []string) {
+	countA, countB := 0, 0
+	for _, e := range hunk {
+		if e.kind != editInsert {
+			countA++
+		}
+		if e.kind != editDelete {
+			countB++
+		}
+	}
+
+	fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(hunk[0].a, countA), hunkRange(hunk[0].b, countB))
+	for _, e := range hunk {
+		var line string
+		if e.kind == editInsert {
+			line = b[e.b]
+		} else {
+			line = a[e.a]
+		}
+
+		fmt.Fprintf(w, "%c%s", e.kind, line)
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			fmt.Fprint(w, "\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,109 @@
+package diff_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/tscolari/s3kup/diff"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unified", func() {
+	var output *bytes.Buffer
+
+	numbered := func(from, to int) string {
+		lines := []string{}
+		for i := from; i <= to; i++ {
+			lines = append(lines, fmt.Sprintf("line %d\n", i))
+		}
+		return strings.Join(lines, "")
+	}
+
+	BeforeEach(func() {
+		output = new(bytes.Buffer)
+	})
+
+	It("writes the changed lines with their context", func() {
+		a := "one\ntwo\nthree\n"
+		b := "one\n2\nthree\nfour\n"
+
+		err := diff.Unified(output, "a/1", "b/2", []byte(a), []byte(b), diff.DefaultContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(Equal(`--- a/1
++++ b/2
+@@ -1,3 +1,4 @@
+ one
+-two
++2
+ three
++four
+`))
+	})
+
+	It("limits the context and splits distant changes into hunks", func() {
+		a := numbered(1, 20)
+		b := strings.Replace(strings.Replace(a, "line 2\n", "line two\n", 1), "line 18\n", "", 1)
+
+		err := diff.Unified(output, "a", "b", []byte(a), []byte(b), 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(Equal(`--- a
++++ b
+@@ -1,3 +1,3 @@
+ line 1
+-line 2
++line two
+ line 3
+@@ -17,3 +17,2 @@
+ line 17
+-line 18
+ line 19
+`))
+	})
+
+	It("merges the hunks when their context overlaps", func() {
+		a := numbered(1, 10)
+		b := strings.Replace(strings.Replace(a, "line 3\n", "line three\n", 1), "line 7\n", "line seven\n", 1)
+
+		err := diff.Unified(output, "a", "b", []byte(a), []byte(b), 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(output.String(), "@@ -")).To(Equal(1))
+		Expect(output.String()).To(ContainSubstring("@@ -1,9 +1,9 @@\n"))
+	})
+
+	It("writes only the header of an insertion into an empty content", func() {
+		err := diff.Unified(output, "a", "b", []byte{}, []byte("new\n"), diff.DefaultContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(Equal("--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n"))
+	})
+
+	It("marks the lines without a trailing newline", func() {
+		err := diff.Unified(output, "a", "b", []byte("same\nold"), []byte("same\nnew\n"), diff.DefaultContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(Equal(`--- a
++++ b
+@@ -1,2 +1,2 @@
+ same
+-old
+\ No newline at end of file
++new
+`))
+	})
+
+	It("writes only the header when the contents are equal", func() {
+		err := diff.Unified(output, "a", "b", []byte(numbered(1, 5)), []byte(numbered(1, 5)), diff.DefaultContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(Equal("--- a\n+++ b\n"))
+	})
+
+	It("gives up when there are too many changed lines", func() {
+		a := numbered(1, 2000)
+		b := strings.Replace(a, "line", "row", -1)
+
+		err := diff.Unified(output, "a", "b", []byte(a), []byte(b), diff.DefaultContext)
+		Expect(err).To(Equal(diff.ErrTooManyChanges))
+		Expect(output.Len()).To(BeZero())
+	})
+})
@@ -0,0 +1,203 @@
+package doctor
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+const MaxClockSkew = 15 * time.Minute
+
+type Status string
+
+const (
+	OK      Status = "ok"
+	Warning Status = "warning"
+	Failed  Status = "failed"
+	Skipped Status = "skipped"
+)
+
+var rejectedCredentialsCodes = map[string]bool{
+	"InvalidAccessKeyId":    true,
+	"SignatureDoesNotMatch": true,
+	"ExpiredToken":          true,
+	"InvalidToken":          true,
+	"TokenRefreshRequired":  true,
+}
+
+type Check struct {
+	Name    string
+	Status  Status
+	Message string
+	Err     error
+}
+
+type Doctor struct {
+	s3  S3Client
+	now func() time.Time
+}
+
+type S3Client interface {
+	Endpoint() string
+	EndpointTime() (time.Time, error)
+	Inventory(path string) (inventory s3.Inventory, err error)
+	Store(path string, content []byte) error
+	Get(path string) ([]byte, error)
+	Delete(path string) error
+}
+
+func New(client S3Client, now func() time.Time) Doctor {
+	return Doctor{
+		s3:  client,
+		now: now,
+	}
+}
+
+func (d Doctor) Diagnose(backupName string) []Check {
+	checks := []Check{}
+	add := func(check Check) Check {
+		log.Info("Checked", check.Name+":", check.Status, check.Message)
+		checks = append(checks, check)
+		return check
+	}
+
+	endpoint := add(d.checkEndpoint())
+	if endpoint.Status == Failed {
+		for _, name := range []string{"clock", "credentials", "list", "put", "get", "delete"} {
+			add(skipped(name, "the endpoint can't be reached"))
+		}
+		return checks
+	}
+
+	add(d.checkClock())
+
+	_, listErr := d.s3.Inventory(backupName)
+	credentials := add(checkCredentials(listErr))
+	if credentials.Status == Failed {
+		for _, name := range []string{"list", "put", "get", "delete"} {
+			add(skipped(name, "the credentials were rejected"))
+		}
+		return checks
+	}
+
+	add(result("list", listErr, fmt.Sprintf("listed '%s/'", backupName)))
+
+	path := s3.StagingPath(backupName, "doctor-"+randomSuffix())
+	content := []byte("s3kup doctor probe " + path)
+
+	put := add(result("put", d.s3.Store(path, content), "stored "+path))
+	if put.Status == Failed {
+		add(skipped("get", "the put failed"))
+		add(skipped("delete", "the put failed"))
+		return checks
+	}
+
+	add(d.checkGet(path, content))
+	add(result("delete", d.s3.Delete(path), "deleted "+path))
+	return checks
+}
+
+func Failures(checks []Check) []Check {
+	failures := []Check{}
+	for _, check := range checks {
+		if check.Status == Failed {
+			failures = append(failures, check)
+		}
+	}
+
+	return failures
+}
+
+func (d Doctor) checkEndpoint() Check {
+	start := d.now()
+	_, err := d.s3.EndpointTime()
+	if err != nil {
+		return failed("endpoint", fmt.Errorf("can't reach %s: %w", d.s3.Endpoint(), err))
+	}
+
+	return Check{Name: "endpoint", Status: OK, Message: fmt.Sprintf("%s answered in %s", d.s3.Endpoint(), d.now().Sub(start).Round(time.Millisecond))}
+}
+
+func (d Doctor) checkClock() Check {
+	before := d.now()
+	endpointTime, err := d.s3.EndpointTime()
+	if err != nil {
+		return failed("clock", err)
+	}
+	after := d.now()
+
+	skew := before.Add(after.Sub(before) / 2).Sub(endpointTime)
+	direction := "ahead of"
+	if skew < 0 {
+		skew, direction = -skew, "behind"
+	}
+	message := fmt.Sprintf("%s %s s3", skew.Round(time.Second), direction)
+
+	switch {
+	case skew >= MaxClockSkew:
+		return failed("clock", fmt.Errorf("%s. s3 rejects requests signed more than %s away from its time", message, MaxClockSkew))
+	case skew >= time.Minute:
+		return Check{Name: "clock", Status: Warning, Message: message + ". Sync the clock, s3 rejects requests past " + MaxClockSkew.String()}
+	}
+
+	return Check{Name: "clock", Status: OK, Message: message}
+}
+
+func checkCredentials(err error) Check {
+	code := s3.ErrorCode(err)
+	switch {
+	case rejectedCredentialsCodes[code]:
+		return failed("credentials", err)
+	case code == "RequestTimeTooSkewed":
+		return failed("credentials", fmt.Errorf("the clock is too far from s3's: %w", err))
+	case err != nil && !s3.IsCredentialsError(err) && !s3.IsNotFoundError(err):
+		return skipped("credentials", "the list request failed before s3 checked them")
+	}
+
+	return Check{Name: "credentials", Status: OK, Message: "accepted by s3"}
+}
+
+func (d Doctor) checkGet(path string, content []byte) Check {
+	stored, err := d.s3.Get(path)
+	if err != nil {
+		return failed("get", err)
+	}
+
+	if !bytes.Equal(stored, content) {
+		return failed("get", fmt.Errorf("got %d bytes back from %s, but %d were stored", len(stored), path, len(content)))
+	}
+
+	return Check{Name: "get", Status: OK, Message: "read back " + path}
+}
+
+func result(name string, err error, message string) Check {
+	if err != nil {
+		return failed(name, err)
+	}
+
+	return Check{Name: name, Status: OK, Message: message}
+}
+
+func failed(name string, err error) Check {
+	message := err.Error()
+	if code := s3.ErrorCode(err); code != "" {
+		message = code + ": " + message
+	}
+
+	return Check{Name: name, Status: Failed, Message: message, Err: err}
+}
+
+func skipped(name, reason string) Check {
+	return Check{Name: name, Status: Skipped, Message: reason}
+}
+
+func randomSuffix() string {
+	random := make([]byte, 4)
+	rand.Read(random)
+	return hex.EncodeToString(random)
+}
@@ -0,0 +1,188 @@
+package doctor_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	goamzs3 "github.com/mitchellh/goamz/s3"
+	"github.com/tscolari/s3kup/doctor"
+	"github.com/tscolari/s3kup/doctor/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Doctor", func() {
+	var d doctor.Doctor
+	var client *fakes.FakeS3Client
+	var now time.Time
+	var stored map[string][]byte
+
+	statuses := func(checks []doctor.Check) map[string]doctor.Status {
+		result := map[string]doctor.Status{}
+		for _, check := range checks {
+			result[check.Name] = check.Status
+		}
+		return result
+	}
+
+	find := func(checks []doctor.Check, name string) doctor.Check {
+		for _, check := range checks {
+			if check.Name == name {
+				return check
+			}
+		}
+		Fail("no check named " + name)
+		return doctor.Check{}
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+		stored = map[string][]byte{}
+
+		client = new(fakes.FakeS3Client)
+		client.EndpointReturns("https://s3.example.com")
+		client.EndpointTimeReturns(now.Add(-2*time.Second), nil)
+		client.StoreStub = func(path string, content []byte) error {
+			stored[path] = content
+			return nil
+		}
+		client.GetStub = func(path string) ([]byte, error) {
+			return stored[path], nil
+		}
+		client.DeleteStub = func(path string) error {
+			delete(stored, path)
+			return nil
+		}
+
+		d = doctor.New(client, func() time.Time { return now })
+	})
+
+	It("passes every check on a healthy setup", func() {
+		checks := d.Diagnose("my-backup")
+		Expect(doctor.Failures(checks)).To(BeEmpty())
+
+		names := []string{}
+		for _, check := range checks {
+			names = append(names, check.Name)
+			Expect(check.Status).To(Equal(doctor.OK), check.Name+": "+check.Message)
+		}
+		Expect(names).To(Equal([]string{"endpoint", "clock", "credentials", "list", "put", "get", "delete"}))
+
+		Expect(find(checks, "clock").Message).To(Equal("2s ahead of s3"))
+		Expect(client.InventoryArgsForCall(0)).To(Equal("my-backup"))
+	})
+
+	It("probes with a throwaway key in the staging area of the backup, and deletes it", func() {
+		d.Diagnose("my-backup")
+
+		Expect(client.StoreCallCount()).To(Equal(1))
+		path, _ := client.StoreArgsForCall(0)
+		Expect(path).To(HavePrefix("my-backup/.s3kup/staging/doctor-"))
+		Expect(client.GetArgsForCall(0)).To(Equal(path))
+		Expect(client.DeleteArgsForCall(0)).To(Equal(path))
+		Expect(stored).To(BeEmpty())
+	})
+
+	Context("when the endpoint can't be reached", func() {
+		It("fails and skips the other checks", func() {
+			client.EndpointTimeReturns(time.Time{}, &net.OpError{Op: "dial", Err: errors.New("connection refused")})
+
+			checks := d.Diagnose("my-backup")
+			Expect(find(checks, "endpoint").Status).To(Equal(doctor.Failed))
+			Expect(find(checks, "endpoint").Message).To(HavePrefix("can't reach https://s3.example.com: dial: connection refused"))
+			Expect(statuses(checks)).To(Equal(map[string]doctor.Status{
+				"endpoint":    doctor.Failed,
+				"clock":       doctor.Skipped,
+				"credentials": doctor.Skipped,
+				"list":        doctor.Skipped,
+				"put":         doctor.Skipped,
+				"get":         doctor.Skipped,
+				"delete":      doctor.Skipped,
+			}))
+			Expect(client.StoreCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the clock is off", func() {
+		It("warns from a minute", func() {
+			client.EndpointTimeReturns(now.Add(3*time.Minute), nil)
+
+			check := find(d.Diagnose("my-backup"), "clock")
+			Expect(check.Status).To(Equal(doctor.Warning))
+			Expect(check.Message).To(HavePrefix("3m0s behind s3"))
+		})
+
+		It("fails past the skew s3 accepts", func() {
+			client.EndpointTimeReturns(now.Add(-20*time.Minute), nil)
+
+			checks := d.Diagnose("my-backup")
+			Expect(find(checks, "clock").Status).To(Equal(doctor.Failed))
+			Expect(find(checks, "clock").Message).To(HavePrefix("20m0s ahead of s3"))
+		})
+	})
+
+	Context("when the credentials are rejected", func() {
+		It("fails and skips the permission checks", func() {
+			client.InventoryReturns(s3.Inventory{}, &goamzs3.Error{StatusCode: 403, Code: "InvalidAccessKeyId", Message: "The AWS Access Key Id you provided does not exist in our records."})
+
+			checks := d.Diagnose("my-backup")
+			Expect(find(checks, "credentials").Status).To(Equal(doctor.Failed))
+			Expect(find(checks, "credentials").Message).To(Equal("InvalidAccessKeyId: The AWS Access Key Id you provided does not exist in our records."))
+			Expect(find(checks, "list").Status).To(Equal(doctor.Skipped))
+			Expect(find(checks, "put").Status).To(Equal(doctor.Skipped))
+			Expect(client.StoreCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when an operation is denied", func() {
+		It("reports which one and why", func() {
+			denied := &goamzs3.Error{StatusCode: 403, Code: "AccessDenied", Message: "Access Denied"}
+			client.InventoryReturns(s3.Inventory{}, denied)
+			client.DeleteReturns(denied)
+
+			checks := d.Diagnose("my-backup")
+			Expect(find(checks, "credentials").Status).To(Equal(doctor.OK))
+			Expect(statuses(checks)).To(Equal(map[string]doctor.Status{
+				"endpoint":    doctor.OK,
+				"clock":       doctor.OK,
+				"credentials": doctor.OK,
+				"list":        doctor.Failed,
+				"put":         doctor.OK,
+				"get":         doctor.OK,
+				"delete":      doctor.Failed,
+			}))
+
+			failures := doctor.Failures(checks)
+			Expect(failures).To(HaveLen(2))
+			Expect(failures[0].Message).To(Equal("AccessDenied: Access Denied"))
+			Expect(failures[0].Err).To(Equal(denied))
+		})
+
+		It("skips get and delete when the put fails", func() {
+			client.StoreStub = nil
+			client.StoreReturns(&goamzs3.Error{StatusCode: 403, Code: "AccessDenied", Message: "Access Denied"})
+
+			checks := d.Diagnose("my-backup")
+			Expect(find(checks, "put").Status).To(Equal(doctor.Failed))
+			Expect(find(checks, "get").Status).To(Equal(doctor.Skipped))
+			Expect(find(checks, "delete").Status).To(Equal(doctor.Skipped))
+			Expect(client.DeleteCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the content read back differs", func() {
+		It("fails the get check", func() {
+			client.GetStub = func(path string) ([]byte, error) {
+				return []byte("other"), nil
+			}
+
+			check := find(d.Diagnose("my-backup"), "get")
+			Expect(check.Status).To(Equal(doctor.Failed))
+			Expect(strings.HasPrefix(check.Message, "got 5 bytes back from my-backup/.s3kup/staging/doctor-")).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,247 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tscolari/s3kup/doctor"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type FakeS3Client struct {
+	EndpointStub        func() string
+	endpointMutex       sync.RWMutex
+	endpointArgsForCall []struct {
+	}
+	endpointReturns struct {
+		result1 string
+	}
+	EndpointTimeStub        func() (time.Time, error)
+	endpointTimeMutex       sync.RWMutex
+	endpointTimeArgsForCall []struct {
+	}
+	endpointTimeReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	InventoryStub        func(path string) (inventory s3.Inventory, err error)
+	inventoryMutex       sync.RWMutex
+	inventoryArgsForCall []struct {
+		path string
+	}
+	inventoryReturns struct {
+		result1 s3.Inventory
+		result2 error
+	}
+	StoreStub        func(path string, content []byte) error
+	storeMutex       sync.RWMutex
+	storeArgsForCall []struct {
+		path    string
+		content []byte
+	}
+	storeReturns struct {
+		result1 error
+	}
+	GetStub        func(path string) ([]byte, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		path string
+	}
+	getReturns struct {
+		result1 []byte
+		result2 error
+	}
+	DeleteStub        func(path string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		path string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeS3Client) Endpoint() string {
+	fake.endpointMutex.Lock()
+	fake.endpointArgsForCall = append(fake.endpointArgsForCall, struct {
+	}{})
+	fake.endpointMutex.Unlock()
+	if fake.EndpointStub != nil {
+		return fake.EndpointStub()
+	} else {
+		return fake.endpointReturns.result1
+	}
+}
+
+func (fake *FakeS3Client) EndpointCallCount() int {
+	fake.endpointMutex.RLock()
+	defer fake.endpointMutex.RUnlock()
+	return len(fake.endpointArgsForCall)
+}
+
+func (fake *FakeS3Client) EndpointReturns(result1 string) {
+	fake.EndpointStub = nil
+	fake.endpointReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeS3Client) EndpointTime() (time.Time, error) {
+	fake.endpointTimeMutex.Lock()
+	fake.endpointTimeArgsForCall = append(fake.endpointTimeArgsForCall, struct {
+	}{})
+	fake.endpointTimeMutex.Unlock()
+	if fake.EndpointTimeStub != nil {
+		return fake.EndpointTimeStub()
+	} else {
+		return fake.endpointTimeReturns.result1, fake.endpointTimeReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) EndpointTimeCallCount() int {
+	fake.endpointTimeMutex.RLock()
+	defer fake.endpointTimeMutex.RUnlock()
+	return len(fake.endpointTimeArgsForCall)
+}
+
+func (fake *FakeS3Client) EndpointTimeReturns(result1 time.Time, result2 error) {
+	fake.EndpointTimeStub = nil
+	fake.endpointTimeReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) Inventory(path string) (inventory s3.Inventory, err error) {
+	fake.inventoryMutex.Lock()
+	fake.inventoryArgsForCall = append(fake.inventoryArgsForCall, struct {
+		path string
+	}{path})
+	fake.inventoryMutex.Unlock()
+	if fake.InventoryStub != nil {
+		return fake.InventoryStub(path)
+	} else {
+		return fake.inventoryReturns.result1, fake.inventoryReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) InventoryCallCount() int {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	return len(fake.inventoryArgsForCall)
+}
+
+func (fake *FakeS3Client) InventoryArgsForCall(i int) string {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	return fake.inventoryArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) InventoryReturns(result1 s3.Inventory, result2 error) {
+	fake.InventoryStub = nil
+	fake.inventoryReturns = struct {
+		result1 s3.Inventory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) Store(path string, content []byte) error {
+	fake.storeMutex.Lock()
+	fake.storeArgsForCall = append(fake.storeArgsForCall, struct {
+		path    string
+		content []byte
+	}{path, content})
+	fake.storeMutex.Unlock()
+	if fake.StoreStub != nil {
+		return fake.StoreStub(path, content)
+	} else {
+		return fake.storeReturns.result1
+	}
+}
+
+func (fake *FakeS3Client) StoreCallCount() int {
+	fake.storeMutex.RLock()
+	defer fake.storeMutex.RUnlock()
+	return len(fake.storeArgsForCall)
+}
+
+func (fake *FakeS3Client) StoreArgsForCall(i int) (string, []byte) {
+	fake.storeMutex.RLock()
+	defer fake.storeMutex.RUnlock()
+	return fake.storeArgsForCall[i].path, fake.storeArgsForCall[i].content
+}
+
+func (fake *FakeS3Client) StoreReturns(result1 error) {
+	fake.StoreStub = nil
+	fake.storeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeS3Client) Get(path string) ([]byte, error) {
+	fake.getMutex.Lock()
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		path string
+	}{path})
+	fake.getMutex.Unlock()
+	if fake.GetStub != nil {
+		return fake.GetStub(path)
+	} else {
+		return fake.getReturns.result1, fake.getReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeS3Client) GetArgsForCall(i int) string {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return fake.getArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) GetReturns(result1 []byte, result2 error) {
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) Delete(path string) error {
+	fake.deleteMutex.Lock()
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		path string
+	}{path})
+	fake.deleteMutex.Unlock()
+	if fake.DeleteStub != nil {
+		return fake.DeleteStub(path)
+	} else {
+		return fake.deleteReturns.result1
+	}
+}
+
+func (fake *FakeS3Client) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeS3Client) DeleteArgsForCall(i int) string {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return fake.deleteArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) DeleteReturns(result1 error) {
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ doctor.S3Client = new(FakeS3Client)
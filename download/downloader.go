@@ -0,0 +1,179 @@
+package download
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+const maxAttempts = 3
+
+type Downloader struct {
+	s3 S3Client
+}
+
+type S3Client interface {
+	Stat(path string) (info s3.ObjectInfo, err error)
+	GetFromIfMatch(path string, offset uint64, etag string) (body io.ReadCloser, start uint64, err error)
+}
+
+func New(client S3Client) Downloader {
+	return Downloader{
+		s3: client,
+	}
+}
+
+func PartialPath(outputPath, path, etag string) string {
+	return fmt.Sprintf("%s.s3kup-%x.partial", outputPath, sha1.Sum([]byte(path+"\x00"+etag)))
+}
+
+func (d Downloader) Download(path, outputPath string) error {
+	log.Info("Downloading", path, "to", outputPath)
+	object, err := d.s3.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	partialPath := PartialPath(outputPath, path, object.ETag)
+	removeStalePartials(outputPath, partialPath)
+	file, err := os.OpenFile(partialPath, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = d.fill(file, path, object.Size, object.ETag)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if s3.IsChangedError(err) {
+		os.Remove(partialPath)
+		return fmt.Errorf("'%s' was replaced during the download: %w. Run the pull again to download the new content", path, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = applyAttributes(partialPath, object.Attributes)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(partialPath, outputPath)
+}
+
+func (d Downloader) fill(file *os.File, path string, size uint64, etag string) error {
+	fi, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	offset := uint64(fi.Size())
+	if offset > size {
+		err = file.Truncate(0)
+		if err != nil {
+			return err
+		}
+		offset = 0
+	}
+	if offset > 0 {
+		log.Info(" -- resuming the download at byte", offset)
+	}
+
+	var lastErr error
+	for attempt := 1; offset < size; attempt++ {
+		if attempt > maxAttempts {
+			return fmt.Errorf("Failed to download '%s' after %d attempts: %w. Run the pull again to resume it", path, maxAttempts, lastErr)
+		}
+
+		offset, lastErr = d.downloadFrom(file, path, offset, etag)
+		if s3.IsChangedError(lastErr) {
+			return lastErr
+		}
+		if lastErr != nil {
+			log.Warn(" -- download interrupted at byte", offset, ":", lastErr)
+		} else if offset < size {
+			lastErr = fmt.Errorf("the download ended at byte %d of %d: %w", offset, size, io.ErrUnexpectedEOF)
+		}
+	}
+
+	if offset != size {
+		return fmt.Errorf("Downloaded object '%s' has %d bytes, expected %d", path, offset, size)
+	}
+
+	return nil
+}
+
+func (d Downloader) downloadFrom(file *os.File, path string, offset uint64, etag string) (uint64, error) {
+	body, start, err := d.s3.GetFromIfMatch(path, offset, etag)
+	if err != nil {
+		return offset, err
+	}
+	defer body.Close()
+
+	if start != offset {
+		err = file.Truncate(int64(start))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = file.Seek(int64(start), io.SeekStart)
+	if err != nil {
+		return start, err
+	}
+
+	written, err := io.Copy(file, body)
+	return start + uint64(written), err
+}
+
+// removeStalePartials removes the other partial downloads to outputPath, like
+// the ones of contents that were replaced since. They can't be resumed anymore.
+func removeStalePartials(outputPath, partialPath string) {
+	dir, name := filepath.Split(outputPath)
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	partialName := filepath.Base(partialPath)
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entryName == partialName || !strings.HasPrefix(entryName, name+".s3kup-") || !strings.HasSuffix(entryName, ".partial") {
+			continue
+		}
+
+		log.Info(" -- removing a stale partial download:", entryName)
+		if err := os.Remove(filepath.Join(dir, entryName)); err != nil {
+			log.Warn(" -- failed to remove the stale partial download:", entryName, err)
+		}
+	}
+}
+
+func applyAttributes(path string, attributes s3.Attributes) error {
+	if attributes.Mode != 0 {
+		err := os.Chmod(path, attributes.Mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !attributes.ModTime.IsZero() {
+		return os.Chtimes(path, attributes.ModTime, attributes.ModTime)
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package download_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tscolari/s3kup/download"
+	"github.com/tscolari/s3kup/download/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	goamzs3 "github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type failingReader struct {
+	content string
+	read    bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.read {
+		return 0, errors.New("connection reset")
+	}
+
+	r.read = true
+	return copy(p, r.content), nil
+}
+
+var _ = Describe("Downloader", func() {
+	const content = "my backup content"
+	const path = "my-backup/1"
+	const etag = "5f4dcc3b5aa765d61d8327deb882cf99"
+
+	var downloader download.Downloader
+	var s3Client *fakes.FakeS3Client
+	var outputDir string
+	var outputPath string
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("", "s3kup-download")
+		Expect(err).ToNot(HaveOccurred())
+		outputPath = filepath.Join(outputDir, "restored")
+
+		s3Client = new(fakes.FakeS3Client)
+		s3Client.StatReturns(s3.ObjectInfo{Size: uint64(len(content)), ETag: etag}, nil)
+		s3Client.GetFromIfMatchStub = func(path string, offset uint64, etag string) (io.ReadCloser, uint64, error) {
+			return ioutil.NopCloser(strings.NewReader(content[offset:])), offset, nil
+		}
+
+		downloader = download.New(s3Client)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outputDir)
+	})
+
+	It("writes the content to the output path", func() {
+		err := downloader.Download(path, outputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ioutil.ReadFile(outputPath)).To(Equal([]byte(content)))
+		Expect(s3Client.StatArgsForCall(0)).To(Equal(path))
+		Expect(s3Client.GetFromIfMatchCallCount()).To(Equal(1))
+
+		_, err = os.Stat(download.PartialPath(outputPath, path, etag))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("only downloads the content with the ETag it started with", func() {
+		err := downloader.Download(path, outputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, requestedETag := s3Client.GetFromIfMatchArgsForCall(0)
+		Expect(requestedETag).To(Equal(etag))
+	})
+
+	It("keeps the file private when no mode was recorded", func() {
+		err := downloader.Download(path, outputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		fi, err := os.Stat(outputPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("replaces an existing file", func() {
+		Expect(ioutil.WriteFile(outputPath, []byte("old content that is longer"), 0600)).To(Succeed())
+
+		err := downloader.Download(path, outputPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.ReadFile(outputPath)).To(Equal([]byte(content)))
+	})
+
+	It("restores the recorded mode and modification time", func() {
+		modTime := time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)
+		s3Client.StatReturns(s3.ObjectInfo{Size: uint64(len(content)), ETag: etag, Attributes: s3.Attributes{Mode: 0640, ModTime: modTime}}, nil)
+
+		err := downloader.Download(path, outputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		fi, err := os.Stat(outputPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0640)))
+		Expect(fi.ModTime().Equal(modTime)).To(BeTrue())
+	})
+
+	Context("when a previous download was interrupted", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(download.PartialPath(outputPath, path, etag), []byte(content[:5]), 0600)).To(Succeed())
+		})
+
+		It("resumes it", func() {
+			err := downloader.Download(path, outputPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ioutil.ReadFile(outputPath)).To(Equal([]byte(content)))
+
+			_, offset, requestedETag := s3Client.GetFromIfMatchArgsForCall(0)
+			Expect(offset).To(Equal(uint64(5)))
+			Expect(requestedETag).To(Equal(etag))
+		})
+
+		It("starts over when the object was replaced since", func() {
+			s3Client.StatReturns(s3.ObjectInfo{Size: uint64(len(content)), ETag: "0cc175b9c0f1b6a831c399e269772661"}, nil)
+
+			err := downloader.Download(path, outputPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ioutil.ReadFile(outputPath)).To(Equal([]byte(content)))
+
+			_, offset, _ := s3Client.GetFromIfMatchArgsForCall(0)
+			Expect(offset).To(Equal(uint64(0)))
+
+			_, err = os.Stat(download.PartialPath(outputPath, path, etag))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("leaves the files of other outputs alone", func() {
+			otherPartial := download.PartialPath(filepath.Join(outputDir, "other"), path, etag)
+			Expect(ioutil.WriteFile(otherPartial, []byte(content[:5]), 0600)).To(Succeed())
+			s3Client.StatReturns(s3.ObjectInfo{Size: uint64(len(content)), ETag: "0cc175b9c0f1b6a831c399e269772661"}, nil)
+
+			err := downloader.Download(path, outputPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(otherPartial).To(BeAnExistingFile())
+		})
+
+		It("starts over when the server ignores the range", func() {
+			s3Client.GetFromIfMatchStub = func(path string, offset uint64, etag string) (io.ReadCloser, uint64, error) {
+				return ioutil.NopCloser(strings.NewReader(content)), 0, nil
+			}
+
+			err := downloader.Download(path, outputPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ioutil.ReadFile(outputPath)).To(Equal([]byte(content)))
+		})
+	})
+
+	Context("when the connection drops", func() {
+		It("resumes from where it stopped", func() {
+			s3Client.GetFromIfMatchStub = func(path string, offset uint64, etag string) (io.ReadCloser, uint64, error) {
+				if offset == 0 {
+					return ioutil.NopCloser(&failingReader{content: content[:5]}), 0, nil
+				}
+				return ioutil.NopCloser(strings.NewReader(content[offset:])), offset, nil
+			}
+
+			err := downloader.Download(path, outputPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ioutil.ReadFile(outputPath)).To(Equal([]byte(content)))
+
+			Expect(s3Client.GetFromIfMatchCallCount()).To(Equal(2))
+			_, offset, _ := s3Client.GetFromIfMatchArgsForCall(1)
+			Expect(offset).To(Equal(uint64(5)))
+		})
+
+		It("stops and removes the partial download when the object is replaced meanwhile", func() {
+			s3Client.GetFromIfMatchStub = func(path string, offset uint64, etag string) (io.ReadCloser, uint64, error) {
+				if offset == 0 {
+					return ioutil.NopCloser(&failingReader{content: content[:5]}), 0, nil
+				}
+				return nil, 0, &goamzs3.Error{StatusCode: 412, Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+			}
+
+			err := downloader.Download(path, outputPath)
+			Expect(err).To(MatchError("'my-backup/1' was replaced during the download: At least one of the pre-conditions you specified did not hold. Run the pull again to download the new content"))
+			Expect(s3Client.GetFromIfMatchCallCount()).To(Equal(2))
+
+			files, err := ioutil.ReadDir(outputDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(BeEmpty())
+		})
+
+		It("keeps the partial download when it keeps failing", func() {
+			s3Client.GetFromIfMatchStub = func(path string, offset uint64, etag string) (io.ReadCloser, uint64, error) {
+				if offset == 0 {
+					return ioutil.NopCloser(&failingReader{content: content[:5]}), 0, nil
+				}
+				return nil, 0, errors.New("connection refused")
+			}
+
+			err := downloader.Download(path, outputPath)
+			Expect(err).To(MatchError("Failed to download 'my-backup/1' after 3 attempts: connection refused. Run the pull again to resume it"))
+
+			_, err = os.Stat(outputPath)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+			partialPath := download.PartialPath(outputPath, path, etag)
+			Expect(ioutil.ReadFile(partialPath)).To(Equal([]byte(content[:5])))
+			fi, err := os.Stat(partialPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+	})
+
+	Context("when the object can't be found", func() {
+		It("forwards the error", func() {
+			s3Client.StatReturns(s3.ObjectInfo{}, errors.New("not found"))
+
+			err := downloader.Download(path, outputPath)
+			Expect(err).To(MatchError("not found"))
+
+			_, err = os.Stat(outputPath)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
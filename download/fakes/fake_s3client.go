@@ -0,0 +1,105 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"io"
+	"sync"
+
+	"github.com/tscolari/s3kup/download"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type FakeS3Client struct {
+	StatStub        func(path string) (info s3.ObjectInfo, err error)
+	statMutex       sync.RWMutex
+	statArgsForCall []struct {
+		path string
+	}
+	statReturns struct {
+		result1 s3.ObjectInfo
+		result2 error
+	}
+	GetFromIfMatchStub        func(path string, offset uint64, etag string) (body io.ReadCloser, start uint64, err error)
+	getFromIfMatchMutex       sync.RWMutex
+	getFromIfMatchArgsForCall []struct {
+		path   string
+		offset uint64
+		etag   string
+	}
+	getFromIfMatchReturns struct {
+		result1 io.ReadCloser
+		result2 uint64
+		result3 error
+	}
+}
+
+func (fake *FakeS3Client) Stat(path string) (info s3.ObjectInfo, err error) {
+	fake.statMutex.Lock()
+	fake.statArgsForCall = append(fake.statArgsForCall, struct {
+		path string
+	}{path})
+	fake.statMutex.Unlock()
+	if fake.StatStub != nil {
+		return fake.StatStub(path)
+	} else {
+		return fake.statReturns.result1, fake.statReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) StatCallCount() int {
+	fake.statMutex.RLock()
+	defer fake.statMutex.RUnlock()
+	return len(fake.statArgsForCall)
+}
+
+func (fake *FakeS3Client) StatArgsForCall(i int) string {
+	fake.statMutex.RLock()
+	defer fake.statMutex.RUnlock()
+	return fake.statArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) StatReturns(result1 s3.ObjectInfo, result2 error) {
+	fake.StatStub = nil
+	fake.statReturns = struct {
+		result1 s3.ObjectInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) GetFromIfMatch(path string, offset uint64, etag string) (body io.ReadCloser, start uint64, err error) {
+	fake.getFromIfMatchMutex.Lock()
+	fake.getFromIfMatchArgsForCall = append(fake.getFromIfMatchArgsForCall, struct {
+		path   string
+		offset uint64
+		etag   string
+	}{path, offset, etag})
+	fake.getFromIfMatchMutex.Unlock()
+	if fake.GetFromIfMatchStub != nil {
+		return fake.GetFromIfMatchStub(path, offset, etag)
+	} else {
+		return fake.getFromIfMatchReturns.result1, fake.getFromIfMatchReturns.result2, fake.getFromIfMatchReturns.result3
+	}
+}
+
+func (fake *FakeS3Client) GetFromIfMatchCallCount() int {
+	fake.getFromIfMatchMutex.RLock()
+	defer fake.getFromIfMatchMutex.RUnlock()
+	return len(fake.getFromIfMatchArgsForCall)
+}
+
+func (fake *FakeS3Client) GetFromIfMatchArgsForCall(i int) (string, uint64, string) {
+	fake.getFromIfMatchMutex.RLock()
+	defer fake.getFromIfMatchMutex.RUnlock()
+	return fake.getFromIfMatchArgsForCall[i].path, fake.getFromIfMatchArgsForCall[i].offset, fake.getFromIfMatchArgsForCall[i].etag
+}
+
+func (fake *FakeS3Client) GetFromIfMatchReturns(result1 io.ReadCloser, result2 uint64, result3 error) {
+	fake.GetFromIfMatchStub = nil
+	fake.getFromIfMatchReturns = struct {
+		result1 io.ReadCloser
+		result2 uint64
+		result3 error
+	}{result1, result2, result3}
+}
+
+var _ download.S3Client = new(FakeS3Client)
@@ -18,6 +18,15 @@ type FakeS3Client struct {
 		result1 s3.Versions
 		result2 error
 	}
+	ListWithLabelsStub        func(path string) (versions s3.Versions, err error)
+	listWithLabelsMutex       sync.RWMutex
+	listWithLabelsArgsForCall []struct {
+		path string
+	}
+	listWithLabelsReturns struct {
+		result1 s3.Versions
+		result2 error
+	}
 	GetStub        func(path string) ([]byte, error)
 	getMutex       sync.RWMutex
 	getArgsForCall []struct {
@@ -62,6 +71,39 @@ func (fake *FakeS3Client) ListReturns(result1 s3.Versions, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeS3Client) ListWithLabels(path string) (versions s3.Versions, err error) {
+	fake.listWithLabelsMutex.Lock()
+	fake.listWithLabelsArgsForCall = append(fake.listWithLabelsArgsForCall, struct {
+		path string
+	}{path})
+	fake.listWithLabelsMutex.Unlock()
+	if fake.ListWithLabelsStub != nil {
+		return fake.ListWithLabelsStub(path)
+	} else {
+		return fake.listWithLabelsReturns.result1, fake.listWithLabelsReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) ListWithLabelsCallCount() int {
+	fake.listWithLabelsMutex.RLock()
+	defer fake.listWithLabelsMutex.RUnlock()
+	return len(fake.listWithLabelsArgsForCall)
+}
+
+func (fake *FakeS3Client) ListWithLabelsArgsForCall(i int) string {
+	fake.listWithLabelsMutex.RLock()
+	defer fake.listWithLabelsMutex.RUnlock()
+	return fake.listWithLabelsArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) ListWithLabelsReturns(result1 s3.Versions, result2 error) {
+	fake.ListWithLabelsStub = nil
+	fake.listWithLabelsReturns = struct {
+		result1 s3.Versions
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeS3Client) Get(path string) ([]byte, error) {
 	fake.getMutex.Lock()
 	fake.getArgsForCall = append(fake.getArgsForCall, struct {
@@ -1,7 +1,6 @@
 package fetch
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/tscolari/s3kup/s3"
@@ -13,6 +12,7 @@ type Fetcher struct {
 
 type S3Client interface {
 	List(path string) (versions s3.Versions, err error)
+	ListWithLabels(path string) (versions s3.Versions, err error)
 	Get(path string) ([]byte, error)
 }
 
@@ -23,28 +23,85 @@ func New(client S3Client) Fetcher {
 }
 
 func (f Fetcher) FetchLatest(backupName string) ([]byte, error) {
-	versions, err := f.s3.List(backupName)
+	return f.Fetch(backupName, Selector{})
+}
+
+func (f Fetcher) FetchLatestMatching(backupName string, labels s3.Labels) ([]byte, error) {
+	return f.Fetch(backupName, Selector{}.WithLabels(labels))
+}
+
+func (f Fetcher) FetchVersion(backupName string, version string) ([]byte, error) {
+	return f.Fetch(backupName, Selector{expression: version, kind: exactSelector, version: version})
+}
+
+func (f Fetcher) Fetch(backupName string, selector Selector) ([]byte, error) {
+	version, err := f.Find(backupName, selector)
 	if err != nil {
 		return nil, err
 	}
 
+	return f.s3.Get(version.Path)
+}
+
+func (f Fetcher) Find(backupName string, selector Selector) (s3.Version, error) {
+	versions, err := f.list(backupName, selector.labels)
+	if err != nil {
+		return s3.Version{}, err
+	}
+
 	if len(versions) == 0 {
 		message := fmt.Sprintf("There's no backup named '%s' on this bucket", backupName)
-		return nil, errors.New(message)
+		return s3.Version{}, &s3.NotFoundError{Message: message}
+	}
+
+	if len(versions.Matching(selector.labels)) == 0 {
+		message := fmt.Sprintf("There's no version of '%s' with the labels '%s'", backupName, selector.labels.String())
+		return s3.Version{}, &s3.NotFoundError{Message: message}
 	}
 
-	lastVersion := versions[len(versions)-1]
-	return f.s3.Get(lastVersion.Path)
+	return selector.Select(versions)
 }
 
-func (f Fetcher) FetchVersion(backupName string, version int64) ([]byte, error) {
-	versionPath := fmt.Sprintf("%s/%d", backupName, version)
+func (f Fetcher) FetchPart(versionContent []byte, partName string) ([]byte, error) {
+	part, err := findPart(versionContent, partName)
+	if err != nil {
+		return nil, err
+	}
 
-	content, err := f.s3.Get(versionPath)
-	if err != nil && err.Error() == "The specified key does not exist." {
-		message := fmt.Sprintf("Could not find version '%d'", version)
-		err = errors.New(message)
+	return f.s3.Get(part.Path)
+}
+
+func (f Fetcher) PartPath(version s3.Version, partName string) (string, error) {
+	part, err := f.Part(version, partName)
+	if err != nil {
+		return "", err
+	}
+
+	return part.Path, nil
+}
+
+func (f Fetcher) Part(version s3.Version, partName string) (s3.ManifestPart, error) {
+	versionContent, err := f.s3.Get(version.Path)
+	if err != nil {
+		return s3.ManifestPart{}, err
+	}
+
+	return findPart(versionContent, partName)
+}
+
+func (f Fetcher) list(backupName string, labels s3.Labels) (s3.Versions, error) {
+	if len(labels) > 0 {
+		return f.s3.ListWithLabels(backupName)
+	}
+
+	return f.s3.List(backupName)
+}
+
+func findPart(versionContent []byte, partName string) (s3.ManifestPart, error) {
+	manifest, err := s3.ParseManifest(versionContent)
+	if err != nil {
+		return s3.ManifestPart{}, err
 	}
 
-	return content, err
+	return manifest.Part(partName)
 }
@@ -2,6 +2,7 @@ package fetch_test
 
 import (
 	"errors"
+	"time"
 
 	"github.com/tscolari/s3kup/fetch"
 	"github.com/tscolari/s3kup/fetch/fakes"
@@ -19,9 +20,9 @@ var _ = Describe("Fetcher", func() {
 		client = new(fakes.FakeS3Client)
 
 		versions := s3.Versions{
-			s3.Version{Path: "my-backup/0", Version: 0},
-			s3.Version{Path: "my-backup/1", Version: 1},
-			s3.Version{Path: "my-backup/2", Version: 2},
+			s3.Version{Path: "my-backup/0", Version: "0"},
+			s3.Version{Path: "my-backup/1", Version: "1"},
+			s3.Version{Path: "my-backup/2", Version: "2"},
 		}
 		client.ListReturns(versions, nil)
 
@@ -44,6 +45,7 @@ var _ = Describe("Fetcher", func() {
 
 				_, err := fetcher.FetchLatest("dontexist")
 				Expect(err).To(MatchError("There's no backup named 'dontexist' on this bucket"))
+				Expect(s3.IsNotFoundError(err)).To(BeTrue())
 			})
 		})
 
@@ -59,6 +61,51 @@ var _ = Describe("Fetcher", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(content).To(Equal([]byte("correct version")))
 		})
+
+		It("uses the version ordering instead of the listing order", func() {
+			client.ListReturns(s3.Versions{
+				s3.Version{Path: "my-backup/1427571015905296950-9c1f0a2b5e3d7f10", Version: "1427571015905296950-9c1f0a2b5e3d7f10"},
+				s3.Version{Path: "my-backup/1427554100187348642", Version: "1427554100187348642"},
+				s3.Version{Path: "my-backup/1427571015905296950-0c1f0a2b5e3d7f10", Version: "1427571015905296950-0c1f0a2b5e3d7f10"},
+			}, nil)
+
+			_, err := fetcher.FetchLatest("my-backup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.GetArgsForCall(0)).To(Equal("my-backup/1427571015905296950-9c1f0a2b5e3d7f10"))
+		})
+	})
+
+	Describe("#FetchLatestMatching", func() {
+		BeforeEach(func() {
+			client.ListWithLabelsReturns(s3.Versions{
+				s3.Version{Path: "my-backup/0", Version: "0", Labels: s3.Labels{"kind": "nightly"}},
+				s3.Version{Path: "my-backup/2", Version: "2", Labels: s3.Labels{"kind": "manual"}},
+				s3.Version{Path: "my-backup/1", Version: "1", Labels: s3.Labels{"kind": "nightly", "env": "prod"}},
+			}, nil)
+		})
+
+		It("returns the content of the latest version with the labels", func() {
+			_, err := fetcher.FetchLatestMatching("my-backup", s3.Labels{"kind": "nightly"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.GetArgsForCall(0)).To(Equal("my-backup/1"))
+		})
+
+		Context("when no version has the labels", func() {
+			It("returns an error", func() {
+				_, err := fetcher.FetchLatestMatching("my-backup", s3.Labels{"kind": "pre-deploy"})
+				Expect(err).To(MatchError("There's no version of 'my-backup' with the labels 'kind=pre-deploy'"))
+				Expect(client.GetCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when listing the versions fails", func() {
+			It("forwards the error", func() {
+				client.ListWithLabelsReturns(nil, errors.New("failed to list"))
+
+				_, err := fetcher.FetchLatestMatching("my-backup", s3.Labels{"kind": "nightly"})
+				Expect(err).To(MatchError("failed to list"))
+			})
+		})
 	})
 
 	Describe("#FetchVersion", func() {
@@ -66,7 +113,7 @@ var _ = Describe("Fetcher", func() {
 			It("forwards the error", func() {
 				client.GetReturns(nil, errors.New("some error"))
 
-				_, err := fetcher.FetchVersion("my-backup", 1)
+				_, err := fetcher.FetchVersion("my-backup", "1")
 				Expect(err).To(MatchError("some error"))
 			})
 		})
@@ -79,9 +126,159 @@ var _ = Describe("Fetcher", func() {
 				return []byte("another version content"), nil
 			}
 
-			content, err := fetcher.FetchVersion("my-backup", 1)
+			content, err := fetcher.FetchVersion("my-backup", "1")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(content).To(Equal([]byte("version 1 content")))
 		})
+
+		It("uses the path of the stored version", func() {
+			client.ListReturns(s3.Versions{
+				s3.Version{Path: "my-backup/2015/03/29/1.sql", Version: "1"},
+			}, nil)
+
+			_, err := fetcher.FetchVersion("my-backup", "1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.GetArgsForCall(0)).To(Equal("my-backup/2015/03/29/1.sql"))
+		})
+
+		Context("when the version doesn't exist", func() {
+			It("returns an error", func() {
+				_, err := fetcher.FetchVersion("my-backup", "3")
+				Expect(err).To(MatchError("Could not find version '3'"))
+				Expect(client.GetCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when listing the versions fails", func() {
+			It("forwards the error", func() {
+				client.ListReturns(nil, errors.New("failed to list"))
+
+				_, err := fetcher.FetchVersion("my-backup", "1")
+				Expect(err).To(MatchError("failed to list"))
+			})
+		})
+	})
+
+	Describe("#Find", func() {
+		It("returns the version chosen by the selector", func() {
+			selector, err := fetch.ParseSelector("@-1", time.Now())
+			Expect(err).ToNot(HaveOccurred())
+
+			version, err := fetcher.Find("my-backup", selector)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version.Path).To(Equal("my-backup/1"))
+			Expect(client.ListWithLabelsCallCount()).To(Equal(0))
+		})
+
+		It("lists the labels when the selector has labels", func() {
+			client.ListWithLabelsReturns(s3.Versions{
+				s3.Version{Path: "my-backup/0", Version: "0", Labels: s3.Labels{"kind": "nightly"}},
+				s3.Version{Path: "my-backup/1", Version: "1"},
+			}, nil)
+
+			selector, err := fetch.ParseSelector("latest", time.Now())
+			Expect(err).ToNot(HaveOccurred())
+
+			version, err := fetcher.Find("my-backup", selector.WithLabels(s3.Labels{"kind": "nightly"}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version.Path).To(Equal("my-backup/0"))
+		})
+
+		Context("when no version matches", func() {
+			It("returns an error", func() {
+				selector, err := fetch.ParseSelector("@-3", time.Now())
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = fetcher.Find("my-backup", selector)
+				Expect(err).To(MatchError("No version matches '@-3'"))
+			})
+		})
+	})
+
+	Describe("#FetchPart", func() {
+		var manifest []byte
+
+		BeforeEach(func() {
+			var err error
+			manifest, err = s3.NewManifest([]s3.ManifestPart{
+				{Name: "db", Path: "my-backup/.s3kup/parts/1/db"},
+				{Name: "uploads", Path: "my-backup/.s3kup/parts/1/uploads"},
+			}).Encode()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns the content of the part", func() {
+			client.GetReturns([]byte("db content"), nil)
+
+			content, err := fetcher.FetchPart(manifest, "db")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(Equal([]byte("db content")))
+			Expect(client.GetArgsForCall(0)).To(Equal("my-backup/.s3kup/parts/1/db"))
+		})
+
+		Context("when the part doesn't exist", func() {
+			It("returns an error", func() {
+				_, err := fetcher.FetchPart(manifest, "config")
+				Expect(err).To(MatchError("The bundle has no part 'config'. Its parts are: db, uploads"))
+				Expect(client.GetCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the version is not a bundle", func() {
+			It("returns an error", func() {
+				_, err := fetcher.FetchPart([]byte("pg_dump output"), "db")
+				Expect(err).To(MatchError("The version is not a bundle"))
+			})
+		})
+	})
+
+	Describe("#PartPath", func() {
+		It("returns the path of the part from the version manifest", func() {
+			manifest, err := s3.NewManifest([]s3.ManifestPart{
+				{Name: "db", Path: "my-backup/.s3kup/parts/1/db"},
+			}).Encode()
+			Expect(err).ToNot(HaveOccurred())
+			client.GetReturns(manifest, nil)
+
+			path, err := fetcher.PartPath(s3.Version{Path: "my-backup/1"}, "db")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(path).To(Equal("my-backup/.s3kup/parts/1/db"))
+			Expect(client.GetArgsForCall(0)).To(Equal("my-backup/1"))
+		})
+
+		Context("when getting the manifest fails", func() {
+			It("forwards the error", func() {
+				client.GetReturns(nil, errors.New("failed to get"))
+
+				_, err := fetcher.PartPath(s3.Version{Path: "my-backup/1"}, "db")
+				Expect(err).To(MatchError("failed to get"))
+			})
+		})
+	})
+	Describe("#Part", func() {
+		It("returns the part from the version manifest", func() {
+			manifest, err := s3.NewManifest([]s3.ManifestPart{
+				{Name: "db", Path: "my-backup/.s3kup/parts/1/db", Size: 1024},
+			}).Encode()
+			Expect(err).ToNot(HaveOccurred())
+			client.GetReturns(manifest, nil)
+
+			part, err := fetcher.Part(s3.Version{Path: "my-backup/1"}, "db")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(part).To(Equal(s3.ManifestPart{Name: "db", Path: "my-backup/.s3kup/parts/1/db", Size: 1024}))
+		})
+
+		Context("when the version has no such part", func() {
+			It("returns a not found error", func() {
+				manifest, err := s3.NewManifest([]s3.ManifestPart{
+					{Name: "db", Path: "my-backup/.s3kup/parts/1/db"},
+				}).Encode()
+				Expect(err).ToNot(HaveOccurred())
+				client.GetReturns(manifest, nil)
+
+				_, err = fetcher.Part(s3.Version{Path: "my-backup/1"}, "files")
+				Expect(s3.IsNotFoundError(err)).To(BeTrue())
+			})
+		})
 	})
 })
@@ -0,0 +1,177 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tscolari/s3kup/s3"
+)
+
+type selectorKind int
+
+const (
+	latestSelector selectorKind = iota
+	oldestSelector
+	relativeSelector
+	beforeSelector
+	exactSelector
+)
+
+var relativeSelectorRegexp = regexp.MustCompile("^@(?:-(\\d+))?$")
+
+var timeFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+type Selector struct {
+	expression string
+	kind       selectorKind
+	version    string
+	offset     int
+	after      time.Time
+	before     time.Time
+	labels     s3.Labels
+}
+
+func ParseSelector(expression string, now time.Time) (Selector, error) {
+	selector := Selector{expression: expression}
+
+	switch {
+	case expression == "" || expression == "latest":
+		selector.kind = latestSelector
+	case expression == "oldest":
+		selector.kind = oldestSelector
+	case relativeSelectorRegexp.MatchString(expression):
+		selector.kind = relativeSelector
+		if offset := relativeSelectorRegexp.FindStringSubmatch(expression)[1]; offset != "" {
+			var err error
+			selector.offset, err = strconv.Atoi(offset)
+			if err != nil {
+				return Selector{}, errors.New("Invalid offset in '" + expression + "'. It's too large")
+			}
+		}
+	case strings.HasPrefix(expression, "before:"):
+		before, err := parseTime(strings.TrimPrefix(expression, "before:"), now)
+		if err != nil {
+			return Selector{}, err
+		}
+		selector.kind = beforeSelector
+		selector.before = before
+	case strings.HasPrefix(expression, "at:"):
+		day, err := parseDay(strings.TrimPrefix(expression, "at:"), now)
+		if err != nil {
+			return Selector{}, err
+		}
+		selector.kind = beforeSelector
+		selector.after = day
+		selector.before = day.AddDate(0, 0, 1)
+	case s3.ValidVersionID(expression):
+		selector.kind = exactSelector
+		selector.version = expression
+	default:
+		return Selector{}, errors.New("Invalid version '" + expression + "'. It must be a version as shown by `list`, 'latest', 'oldest', '@-N', 'before:<time>' or 'at:<day>'")
+	}
+
+	return selector, nil
+}
+
+func (s Selector) WithLabels(labels s3.Labels) Selector {
+	s.labels = labels
+	return s
+}
+
+func (s Selector) Select(versions s3.Versions) (s3.Version, error) {
+	candidates := append(s3.Versions{}, versions...).Matching(s.labels)
+	sort.Sort(candidates)
+
+	switch s.kind {
+	case exactSelector:
+		for _, version := range candidates {
+			if version.Version == s.version {
+				return version, nil
+			}
+		}
+
+		message := fmt.Sprintf("Could not find version '%s'", s.version)
+		return s3.Version{}, &s3.NotFoundError{Message: message}
+	case oldestSelector:
+		if len(candidates) > 0 {
+			return candidates[0], nil
+		}
+	case latestSelector, relativeSelector:
+		if s.offset < len(candidates) {
+			return candidates[len(candidates)-1-s.offset], nil
+		}
+	case beforeSelector:
+		for i := len(candidates) - 1; i >= 0; i-- {
+			timestamp := s3.VersionIDTimestamp(candidates[i].Version)
+			if timestamp >= s.before.UnixNano() {
+				continue
+			}
+
+			if s.after.IsZero() || timestamp >= s.after.UnixNano() {
+				return candidates[i], nil
+			}
+		}
+	}
+
+	message := fmt.Sprintf("No version matches '%s'", s.String())
+	return s3.Version{}, &s3.NotFoundError{Message: message}
+}
+
+func (s Selector) String() string {
+	expression := s.expression
+	if expression == "" {
+		expression = "latest"
+	}
+
+	if len(s.labels) > 0 {
+		expression += " with the labels '" + s.labels.String() + "'"
+	}
+
+	return expression
+}
+
+func parseTime(value string, now time.Time) (time.Time, error) {
+	switch value {
+	case "now":
+		return now, nil
+	case "today", "yesterday":
+		return parseDay(value, now)
+	}
+
+	for _, format := range timeFormats {
+		if parsedTime, err := time.ParseInLocation(format, value, now.Location()); err == nil {
+			return parsedTime, nil
+		}
+	}
+
+	return time.Time{}, errors.New("Invalid time '" + value + "'. Use 'now', 'today', 'yesterday', 'YYYY-MM-DD' or 'YYYY-MM-DD HH:MM'")
+}
+
+func parseDay(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch value {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return time.Time{}, errors.New("Invalid day '" + value + "'. Use 'today', 'yesterday' or 'YYYY-MM-DD'")
+	}
+
+	return day, nil
+}
@@ -0,0 +1,109 @@
+package fetch_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tscolari/s3kup/fetch"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Selector", func() {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	versionAt := func(date string) s3.Version {
+		timestamp, err := time.Parse("2006-01-02 15:04", date)
+		Expect(err).ToNot(HaveOccurred())
+
+		id := fmt.Sprintf("%019d-0000000000000000", timestamp.UnixNano())
+		return s3.Version{Version: id, Path: date}
+	}
+
+	versions := func() s3.Versions {
+		return s3.Versions{
+			versionAt("2026-10-18 03:00"),
+			versionAt("2026-09-30 03:00"),
+			versionAt("2026-10-19 03:00"),
+			versionAt("2026-10-01 03:00"),
+			versionAt("2026-10-18 23:59"),
+		}
+	}
+
+	DescribeTable("selecting a version",
+		func(expression string, expectedPath string) {
+			selector, err := fetch.ParseSelector(expression, now)
+			Expect(err).ToNot(HaveOccurred())
+
+			version, err := selector.Select(versions())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version.Path).To(Equal(expectedPath))
+		},
+		Entry("empty is the latest", "", "2026-10-19 03:00"),
+		Entry("latest", "latest", "2026-10-19 03:00"),
+		Entry("@ is the latest", "@", "2026-10-19 03:00"),
+		Entry("@-0 is the latest", "@-0", "2026-10-19 03:00"),
+		Entry("@-1 is the one before the latest", "@-1", "2026-10-18 23:59"),
+		Entry("@-4", "@-4", "2026-09-30 03:00"),
+		Entry("oldest", "oldest", "2026-09-30 03:00"),
+		Entry("before a time", "before:2026-10-01 03:00", "2026-09-30 03:00"),
+		Entry("before a time with seconds", "before:2026-10-01 03:00:01", "2026-10-01 03:00"),
+		Entry("before an RFC3339 time", "before:2026-10-18T05:00:00+01:00", "2026-10-18 03:00"),
+		Entry("before a day", "before:2026-10-19", "2026-10-18 23:59"),
+		Entry("before today", "before:today", "2026-10-18 23:59"),
+		Entry("before now", "before:now", "2026-10-19 03:00"),
+		Entry("at yesterday", "at:yesterday", "2026-10-18 23:59"),
+		Entry("at today", "at:today", "2026-10-19 03:00"),
+		Entry("at a day", "at:2026-10-01", "2026-10-01 03:00"),
+		Entry("an exact version", fmt.Sprintf("%019d-0000000000000000", time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC).UnixNano()), "2026-10-01 03:00"),
+	)
+
+	DescribeTable("when no version matches",
+		func(expression string, expectedError string) {
+			selector, err := fetch.ParseSelector(expression, now)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = selector.Select(versions())
+			Expect(err).To(MatchError(expectedError))
+			Expect(s3.IsNotFoundError(err)).To(BeTrue())
+		},
+		Entry("too far back", "@-5", "No version matches '@-5'"),
+		Entry("before every version", "before:2026-09-01", "No version matches 'before:2026-09-01'"),
+		Entry("a day without versions", "at:2026-10-02", "No version matches 'at:2026-10-02'"),
+		Entry("an unknown version", "1427571015905296950", "Could not find version '1427571015905296950'"),
+	)
+
+	DescribeTable("invalid expressions",
+		func(expression string, expectedError string) {
+			_, err := fetch.ParseSelector(expression, now)
+			Expect(err).To(MatchError(expectedError))
+		},
+		Entry("unknown words", "newest", "Invalid version 'newest'. It must be a version as shown by `list`, 'latest', 'oldest', '@-N', 'before:<time>' or 'at:<day>'"),
+		Entry("overflowing offsets", "@-99999999999999999999", "Invalid offset in '@-99999999999999999999'. It's too large"),
+		Entry("positive offsets", "@+1", "Invalid version '@+1'. It must be a version as shown by `list`, 'latest', 'oldest', '@-N', 'before:<time>' or 'at:<day>'"),
+		Entry("invalid times", "before:last week", "Invalid time 'last week'. Use 'now', 'today', 'yesterday', 'YYYY-MM-DD' or 'YYYY-MM-DD HH:MM'"),
+		Entry("times for at", "at:2026-10-01 03:00", "Invalid day '2026-10-01 03:00'. Use 'today', 'yesterday' or 'YYYY-MM-DD'"),
+	)
+
+	It("only selects versions with the labels", func() {
+		labeled := versions()
+		labeled[0].Labels = s3.Labels{"kind": "pre-deploy"}
+		labeled[3].Labels = s3.Labels{"kind": "pre-deploy"}
+
+		selector, err := fetch.ParseSelector("latest", now)
+		Expect(err).ToNot(HaveOccurred())
+
+		version, err := selector.WithLabels(s3.Labels{"kind": "pre-deploy"}).Select(labeled)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version.Path).To(Equal("2026-10-18 03:00"))
+
+		selector, err = fetch.ParseSelector("@-2", now)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = selector.WithLabels(s3.Labels{"kind": "pre-deploy"}).Select(labeled)
+		Expect(err).To(MatchError("No version matches '@-2 with the labels 'kind=pre-deploy''"))
+	})
+})
@@ -0,0 +1,197 @@
+package fsck
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type ProblemKind string
+
+const (
+	ForeignKey       ProblemKind = "foreign"
+	StagedUpload     ProblemKind = "staging"
+	IncompleteUpload ProblemKind = "incomplete-upload"
+	OrphanPart       ProblemKind = "orphan-part"
+	OrphanPin        ProblemKind = "orphan-pin"
+	EmptyVersion     ProblemKind = "zero-byte"
+	DuplicateVersion ProblemKind = "duplicate"
+)
+
+type Problem struct {
+	Kind         ProblemKind
+	Path         string
+	UploadID     string
+	Description  string
+	LastModified time.Time
+	Repairable   bool
+}
+
+type Checker struct {
+	s3 S3Client
+}
+
+type S3Client interface {
+	Inventory(path string) (inventory s3.Inventory, err error)
+	ListIncompleteUploads(path string) (uploads []s3.Upload, err error)
+	VersionPath(backupName, version string) (string, error)
+	Delete(path string) error
+	AbortUpload(path, uploadID string) error
+}
+
+func New(client S3Client) Checker {
+	return Checker{
+		s3: client,
+	}
+}
+
+func (c Checker) Check(backupName string) ([]Problem, error) {
+	log.Info("Checking", backupName)
+	inventory, err := c.s3.Inventory(backupName)
+	if err != nil {
+		return nil, err
+	}
+
+	uploads, err := c.s3.ListIncompleteUploads(backupName)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []Problem{}
+	for _, object := range inventory.Foreign {
+		problems = append(problems, objectProblem(ForeignKey, object, "not a version nor s3kup metadata", false))
+	}
+
+	for _, object := range inventory.Staging {
+		problems = append(problems, objectProblem(StagedUpload, object, "staged upload that was never committed", true))
+	}
+
+	for _, upload := range uploads {
+		problems = append(problems, Problem{
+			Kind:         IncompleteUpload,
+			Path:         upload.Path,
+			UploadID:     upload.UploadID,
+			Description:  "multipart upload that was never completed",
+			LastModified: upload.Initiated,
+			Repairable:   true,
+		})
+	}
+
+	for _, object := range inventory.OrphanParts {
+		problems = append(problems, objectProblem(OrphanPart, object, "bundle part without a committed manifest", true))
+	}
+
+	for _, object := range inventory.OrphanPins {
+		problems = append(problems, objectProblem(OrphanPin, object, "pin of a version that doesn't exist", true))
+	}
+
+	for _, version := range inventory.Versions {
+		if version.Size == 0 {
+			problems = append(problems, Problem{
+				Kind:         EmptyVersion,
+				Path:         version.Path,
+				Description:  fmt.Sprintf("version '%s' is empty", version.Version),
+				LastModified: version.LastModified,
+			})
+		}
+	}
+
+	return append(problems, c.duplicates(backupName, inventory.Versions)...), nil
+}
+
+func (c Checker) Repair(problems []Problem, olderThan time.Time) ([]Problem, error) {
+	repaired := []Problem{}
+	for _, problem := range problems {
+		if !problem.Repairable {
+			continue
+		}
+
+		if !problem.LastModified.Before(olderThan) {
+			log.Info(" -- not repairing recent object:", problem.Path)
+			continue
+		}
+
+		if problem.Kind == IncompleteUpload {
+			err := c.s3.AbortUpload(problem.Path, problem.UploadID)
+			if err != nil {
+				return repaired, err
+			}
+			log.Info(" -- aborted:", problem.Path)
+			repaired = append(repaired, problem)
+			continue
+		}
+
+		err := c.s3.Delete(problem.Path)
+		if err != nil {
+			return repaired, err
+		}
+		log.Info(" -- deleted:", problem.Path)
+		repaired = append(repaired, problem)
+	}
+
+	return repaired, nil
+}
+
+func (c Checker) duplicates(backupName string, versions s3.Versions) []Problem {
+	copies := map[string]s3.Versions{}
+	ids := []string{}
+	for _, version := range versions {
+		if len(copies[version.Version]) == 0 {
+			ids = append(ids, version.Version)
+		}
+		copies[version.Version] = append(copies[version.Version], version)
+	}
+
+	problems := []Problem{}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if len(copies[id]) < 2 {
+			continue
+		}
+
+		kept := c.keptCopy(backupName, copies[id])
+		for _, version := range copies[id] {
+			if version.Path == kept.Path {
+				continue
+			}
+
+			problems = append(problems, Problem{
+				Kind:         DuplicateVersion,
+				Path:         version.Path,
+				Description:  fmt.Sprintf("version '%s' is also stored at '%s'", id, kept.Path),
+				LastModified: version.LastModified,
+				Repairable:   version.Size == kept.Size,
+			})
+		}
+	}
+
+	return problems
+}
+
+func (c Checker) keptCopy(backupName string, copies s3.Versions) s3.Version {
+	kept := copies[0]
+	for _, version := range copies {
+		if path, err := c.s3.VersionPath(backupName, version.Version); err == nil && version.Path == path {
+			return version
+		}
+
+		if version.Path < kept.Path {
+			kept = version
+		}
+	}
+
+	return kept
+}
+
+func objectProblem(kind ProblemKind, object s3.Object, description string, repairable bool) Problem {
+	return Problem{
+		Kind:         kind,
+		Path:         object.Path,
+		Description:  description,
+		LastModified: object.LastModified,
+		Repairable:   repairable,
+	}
+}
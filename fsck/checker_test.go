@@ -0,0 +1,140 @@
+package fsck_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tscolari/s3kup/fsck"
+	"github.com/tscolari/s3kup/fsck/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checker", func() {
+	var checker fsck.Checker
+	var s3Client *fakes.FakeS3Client
+	var old time.Time
+
+	BeforeEach(func() {
+		old = time.Now().Add(-48 * time.Hour)
+		s3Client = new(fakes.FakeS3Client)
+		s3Client.VersionPathStub = func(backupName, version string) (string, error) {
+			return backupName + "/" + version, nil
+		}
+		s3Client.InventoryReturns(s3.Inventory{
+			Versions: s3.Versions{
+				s3.Version{Version: "1", Path: "my-backup/1", Size: 10, LastModified: old},
+				s3.Version{Version: "2", Path: "my-backup/2", Size: 0, LastModified: old},
+				s3.Version{Version: "3", Path: "my-backup/2015/3", Size: 10, LastModified: old},
+				s3.Version{Version: "3", Path: "my-backup/3", Size: 10, LastModified: old},
+				s3.Version{Version: "4", Path: "my-backup/2015/4", Size: 10, LastModified: old},
+				s3.Version{Version: "4", Path: "my-backup/2016/4", Size: 20, LastModified: old},
+			},
+			Staging:     []s3.Object{{Path: "my-backup/.s3kup/staging/5", LastModified: old}},
+			OrphanParts: []s3.Object{{Path: "my-backup/.s3kup/parts/6/db", LastModified: time.Now()}},
+			OrphanPins:  []s3.Object{{Path: "my-backup/.s3kup/pins/7", LastModified: old}},
+			Foreign:     []s3.Object{{Path: "my-backup/README", LastModified: old}},
+		}, nil)
+		s3Client.ListIncompleteUploadsReturns([]s3.Upload{
+			{Path: "my-backup/.s3kup/staging/8", UploadID: "upload-8", Initiated: old},
+			{Path: "my-backup/.s3kup/staging/9", UploadID: "upload-9", Initiated: time.Now()},
+		}, nil)
+
+		checker = fsck.New(s3Client)
+	})
+
+	Describe("#Check", func() {
+		It("reports every problem found", func() {
+			problems, err := checker.Check("my-backup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s3Client.InventoryArgsForCall(0)).To(Equal("my-backup"))
+			Expect(s3Client.ListIncompleteUploadsArgsForCall(0)).To(Equal("my-backup"))
+
+			summary := [][]interface{}{}
+			for _, problem := range problems {
+				summary = append(summary, []interface{}{problem.Kind, problem.Path, problem.Description, problem.Repairable})
+			}
+
+			Expect(summary).To(Equal([][]interface{}{
+				{fsck.ForeignKey, "my-backup/README", "not a version nor s3kup metadata", false},
+				{fsck.StagedUpload, "my-backup/.s3kup/staging/5", "staged upload that was never committed", true},
+				{fsck.IncompleteUpload, "my-backup/.s3kup/staging/8", "multipart upload that was never completed", true},
+				{fsck.IncompleteUpload, "my-backup/.s3kup/staging/9", "multipart upload that was never completed", true},
+				{fsck.OrphanPart, "my-backup/.s3kup/parts/6/db", "bundle part without a committed manifest", true},
+				{fsck.OrphanPin, "my-backup/.s3kup/pins/7", "pin of a version that doesn't exist", true},
+				{fsck.EmptyVersion, "my-backup/2", "version '2' is empty", false},
+				{fsck.DuplicateVersion, "my-backup/2015/3", "version '3' is also stored at 'my-backup/3'", true},
+				{fsck.DuplicateVersion, "my-backup/2016/4", "version '4' is also stored at 'my-backup/2015/4'", false},
+			}))
+		})
+
+		It("reports nothing for a healthy backup", func() {
+			s3Client.InventoryReturns(s3.Inventory{
+				Versions: s3.Versions{s3.Version{Version: "1", Path: "my-backup/1", Size: 10}},
+			}, nil)
+			s3Client.ListIncompleteUploadsReturns(nil, nil)
+
+			problems, err := checker.Check("my-backup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+		})
+
+		Context("when listing fails", func() {
+			It("forwards the error", func() {
+				s3Client.InventoryReturns(s3.Inventory{}, errors.New("failed to list"))
+
+				_, err := checker.Check("my-backup")
+				Expect(err).To(MatchError("failed to list"))
+			})
+
+			It("forwards the error of listing the uploads", func() {
+				s3Client.ListIncompleteUploadsReturns(nil, errors.New("failed to list uploads"))
+
+				_, err := checker.Check("my-backup")
+				Expect(err).To(MatchError("failed to list uploads"))
+			})
+		})
+	})
+
+	Describe("#Repair", func() {
+		var problems []fsck.Problem
+
+		BeforeEach(func() {
+			var err error
+			problems, err = checker.Check("my-backup")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("deletes the repairable objects older than the given time", func() {
+			repaired, err := checker.Repair(problems, time.Now().Add(-24*time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(repaired)).To(Equal(4))
+
+			Expect(s3Client.DeleteCallCount()).To(Equal(3))
+			Expect(s3Client.DeleteArgsForCall(0)).To(Equal("my-backup/.s3kup/staging/5"))
+			Expect(s3Client.DeleteArgsForCall(1)).To(Equal("my-backup/.s3kup/pins/7"))
+			Expect(s3Client.DeleteArgsForCall(2)).To(Equal("my-backup/2015/3"))
+		})
+
+		It("aborts the incomplete uploads older than the given time", func() {
+			_, err := checker.Repair(problems, time.Now().Add(-24*time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(s3Client.AbortUploadCallCount()).To(Equal(1))
+			path, uploadID := s3Client.AbortUploadArgsForCall(0)
+			Expect(path).To(Equal("my-backup/.s3kup/staging/8"))
+			Expect(uploadID).To(Equal("upload-8"))
+		})
+
+		Context("when deleting fails", func() {
+			It("forwards the error", func() {
+				s3Client.DeleteReturns(errors.New("failed to delete"))
+
+				_, err := checker.Repair(problems, time.Now())
+				Expect(err).To(MatchError("failed to delete"))
+			})
+		})
+	})
+})
@@ -0,0 +1,224 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/tscolari/s3kup/fsck"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type FakeS3Client struct {
+	InventoryStub        func(path string) (inventory s3.Inventory, err error)
+	inventoryMutex       sync.RWMutex
+	inventoryArgsForCall []struct {
+		path string
+	}
+	inventoryReturns struct {
+		result1 s3.Inventory
+		result2 error
+	}
+	ListIncompleteUploadsStub        func(path string) (uploads []s3.Upload, err error)
+	listIncompleteUploadsMutex       sync.RWMutex
+	listIncompleteUploadsArgsForCall []struct {
+		path string
+	}
+	listIncompleteUploadsReturns struct {
+		result1 []s3.Upload
+		result2 error
+	}
+	VersionPathStub        func(backupName, version string) (string, error)
+	versionPathMutex       sync.RWMutex
+	versionPathArgsForCall []struct {
+		backupName string
+		version    string
+	}
+	versionPathReturns struct {
+		result1 string
+		result2 error
+	}
+	DeleteStub        func(path string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		path string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+	AbortUploadStub        func(path, uploadID string) error
+	abortUploadMutex       sync.RWMutex
+	abortUploadArgsForCall []struct {
+		path     string
+		uploadID string
+	}
+	abortUploadReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeS3Client) Inventory(path string) (inventory s3.Inventory, err error) {
+	fake.inventoryMutex.Lock()
+	fake.inventoryArgsForCall = append(fake.inventoryArgsForCall, struct {
+		path string
+	}{path})
+	fake.inventoryMutex.Unlock()
+	if fake.InventoryStub != nil {
+		return fake.InventoryStub(path)
+	} else {
+		return fake.inventoryReturns.result1, fake.inventoryReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) InventoryCallCount() int {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	return len(fake.inventoryArgsForCall)
+}
+
+func (fake *FakeS3Client) InventoryArgsForCall(i int) string {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	return fake.inventoryArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) InventoryReturns(result1 s3.Inventory, result2 error) {
+	fake.InventoryStub = nil
+	fake.inventoryReturns = struct {
+		result1 s3.Inventory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) ListIncompleteUploads(path string) (uploads []s3.Upload, err error) {
+	fake.listIncompleteUploadsMutex.Lock()
+	fake.listIncompleteUploadsArgsForCall = append(fake.listIncompleteUploadsArgsForCall, struct {
+		path string
+	}{path})
+	fake.listIncompleteUploadsMutex.Unlock()
+	if fake.ListIncompleteUploadsStub != nil {
+		return fake.ListIncompleteUploadsStub(path)
+	} else {
+		return fake.listIncompleteUploadsReturns.result1, fake.listIncompleteUploadsReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) ListIncompleteUploadsCallCount() int {
+	fake.listIncompleteUploadsMutex.RLock()
+	defer fake.listIncompleteUploadsMutex.RUnlock()
+	return len(fake.listIncompleteUploadsArgsForCall)
+}
+
+func (fake *FakeS3Client) ListIncompleteUploadsArgsForCall(i int) string {
+	fake.listIncompleteUploadsMutex.RLock()
+	defer fake.listIncompleteUploadsMutex.RUnlock()
+	return fake.listIncompleteUploadsArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) ListIncompleteUploadsReturns(result1 []s3.Upload, result2 error) {
+	fake.ListIncompleteUploadsStub = nil
+	fake.listIncompleteUploadsReturns = struct {
+		result1 []s3.Upload
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) VersionPath(backupName, version string) (string, error) {
+	fake.versionPathMutex.Lock()
+	fake.versionPathArgsForCall = append(fake.versionPathArgsForCall, struct {
+		backupName string
+		version    string
+	}{backupName, version})
+	fake.versionPathMutex.Unlock()
+	if fake.VersionPathStub != nil {
+		return fake.VersionPathStub(backupName, version)
+	} else {
+		return fake.versionPathReturns.result1, fake.versionPathReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) VersionPathCallCount() int {
+	fake.versionPathMutex.RLock()
+	defer fake.versionPathMutex.RUnlock()
+	return len(fake.versionPathArgsForCall)
+}
+
+func (fake *FakeS3Client) VersionPathArgsForCall(i int) (string, string) {
+	fake.versionPathMutex.RLock()
+	defer fake.versionPathMutex.RUnlock()
+	return fake.versionPathArgsForCall[i].backupName, fake.versionPathArgsForCall[i].version
+}
+
+func (fake *FakeS3Client) VersionPathReturns(result1 string, result2 error) {
+	fake.VersionPathStub = nil
+	fake.versionPathReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) Delete(path string) error {
+	fake.deleteMutex.Lock()
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		path string
+	}{path})
+	fake.deleteMutex.Unlock()
+	if fake.DeleteStub != nil {
+		return fake.DeleteStub(path)
+	} else {
+		return fake.deleteReturns.result1
+	}
+}
+
+func (fake *FakeS3Client) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeS3Client) DeleteArgsForCall(i int) string {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return fake.deleteArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) DeleteReturns(result1 error) {
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeS3Client) AbortUpload(path, uploadID string) error {
+	fake.abortUploadMutex.Lock()
+	fake.abortUploadArgsForCall = append(fake.abortUploadArgsForCall, struct {
+		path     string
+		uploadID string
+	}{path, uploadID})
+	fake.abortUploadMutex.Unlock()
+	if fake.AbortUploadStub != nil {
+		return fake.AbortUploadStub(path, uploadID)
+	} else {
+		return fake.abortUploadReturns.result1
+	}
+}
+
+func (fake *FakeS3Client) AbortUploadCallCount() int {
+	fake.abortUploadMutex.RLock()
+	defer fake.abortUploadMutex.RUnlock()
+	return len(fake.abortUploadArgsForCall)
+}
+
+func (fake *FakeS3Client) AbortUploadArgsForCall(i int) (string, string) {
+	fake.abortUploadMutex.RLock()
+	defer fake.abortUploadMutex.RUnlock()
+	return fake.abortUploadArgsForCall[i].path, fake.abortUploadArgsForCall[i].uploadID
+}
+
+func (fake *FakeS3Client) AbortUploadReturns(result1 error) {
+	fake.AbortUploadStub = nil
+	fake.abortUploadReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ fsck.S3Client = new(FakeS3Client)
@@ -0,0 +1,98 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/tscolari/s3kup/info"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type FakeS3Client struct {
+	StatStub        func(path string) (info s3.ObjectInfo, err error)
+	statMutex       sync.RWMutex
+	statArgsForCall []struct {
+		path string
+	}
+	statReturns struct {
+		result1 s3.ObjectInfo
+		result2 error
+	}
+	TagsStub        func(path string) (map[string]string, error)
+	tagsMutex       sync.RWMutex
+	tagsArgsForCall []struct {
+		path string
+	}
+	tagsReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+}
+
+func (fake *FakeS3Client) Stat(path string) (info s3.ObjectInfo, err error) {
+	fake.statMutex.Lock()
+	fake.statArgsForCall = append(fake.statArgsForCall, struct {
+		path string
+	}{path})
+	fake.statMutex.Unlock()
+	if fake.StatStub != nil {
+		return fake.StatStub(path)
+	} else {
+		return fake.statReturns.result1, fake.statReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) StatCallCount() int {
+	fake.statMutex.RLock()
+	defer fake.statMutex.RUnlock()
+	return len(fake.statArgsForCall)
+}
+
+func (fake *FakeS3Client) StatArgsForCall(i int) string {
+	fake.statMutex.RLock()
+	defer fake.statMutex.RUnlock()
+	return fake.statArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) StatReturns(result1 s3.ObjectInfo, result2 error) {
+	fake.StatStub = nil
+	fake.statReturns = struct {
+		result1 s3.ObjectInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) Tags(path string) (map[string]string, error) {
+	fake.tagsMutex.Lock()
+	fake.tagsArgsForCall = append(fake.tagsArgsForCall, struct {
+		path string
+	}{path})
+	fake.tagsMutex.Unlock()
+	if fake.TagsStub != nil {
+		return fake.TagsStub(path)
+	} else {
+		return fake.tagsReturns.result1, fake.tagsReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) TagsCallCount() int {
+	fake.tagsMutex.RLock()
+	defer fake.tagsMutex.RUnlock()
+	return len(fake.tagsArgsForCall)
+}
+
+func (fake *FakeS3Client) TagsArgsForCall(i int) string {
+	fake.tagsMutex.RLock()
+	defer fake.tagsMutex.RUnlock()
+	return fake.tagsArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) TagsReturns(result1 map[string]string, result2 error) {
+	fake.TagsStub = nil
+	fake.tagsReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+var _ info.S3Client = new(FakeS3Client)
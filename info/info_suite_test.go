@@ -0,0 +1,13 @@
+package info_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestInfo(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Info Suite")
+}
@@ -0,0 +1,62 @@
+package info
+
+import (
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type Inspector struct {
+	s3 S3Client
+}
+
+type S3Client interface {
+	Stat(path string) (info s3.ObjectInfo, err error)
+	Tags(path string) (map[string]string, error)
+}
+
+type VersionInfo struct {
+	Version s3.Version
+	Object  s3.ObjectInfo
+	Parts   []s3.ObjectInfo
+}
+
+func New(client S3Client) Inspector {
+	return Inspector{
+		s3: client,
+	}
+}
+
+func (i Inspector) Inspect(version s3.Version) (VersionInfo, error) {
+	log.Info("Inspecting version", version.Version, "of", version.BackupName)
+	object, err := i.stat(version.Path)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+
+	versionInfo := VersionInfo{
+		Version: version,
+		Object:  object,
+		Parts:   []s3.ObjectInfo{},
+	}
+
+	for _, partPath := range version.PartPaths {
+		part, err := i.stat(partPath)
+		if err != nil {
+			return VersionInfo{}, err
+		}
+
+		versionInfo.Parts = append(versionInfo.Parts, part)
+	}
+
+	return versionInfo, nil
+}
+
+func (i Inspector) stat(path string) (s3.ObjectInfo, error) {
+	object, err := i.s3.Stat(path)
+	if err != nil || object.TagCount == 0 {
+		return object, err
+	}
+
+	object.Tags, err = i.s3.Tags(path)
+	return object, err
+}
@@ -0,0 +1,84 @@
+package info_test
+
+import (
+	"errors"
+
+	"github.com/tscolari/s3kup/info"
+	"github.com/tscolari/s3kup/info/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inspector", func() {
+	var inspector info.Inspector
+	var s3Client *fakes.FakeS3Client
+
+	BeforeEach(func() {
+		s3Client = new(fakes.FakeS3Client)
+		s3Client.StatStub = func(path string) (s3.ObjectInfo, error) {
+			return s3.ObjectInfo{Path: path, Size: uint64(len(path))}, nil
+		}
+
+		inspector = info.New(s3Client)
+	})
+
+	It("stats the version object", func() {
+		version := s3.Version{BackupName: "my-backup", Version: "1", Path: "my-backup/1"}
+
+		versionInfo, err := inspector.Inspect(version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(versionInfo.Version).To(Equal(version))
+		Expect(versionInfo.Object).To(Equal(s3.ObjectInfo{Path: "my-backup/1", Size: 11}))
+		Expect(versionInfo.Parts).To(BeEmpty())
+	})
+
+	It("stats the parts of a bundle", func() {
+		version := s3.Version{BackupName: "my-backup", Version: "1", Path: "my-backup/1", PartPaths: []string{"my-backup/.s3kup/parts/1/db"}}
+
+		versionInfo, err := inspector.Inspect(version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(versionInfo.Parts).To(Equal([]s3.ObjectInfo{{Path: "my-backup/.s3kup/parts/1/db", Size: 27}}))
+	})
+
+	It("only fetches the tags of objects that have them", func() {
+		s3Client.StatStub = func(path string) (s3.ObjectInfo, error) {
+			if path == "my-backup/1" {
+				return s3.ObjectInfo{Path: path, TagCount: 1}, nil
+			}
+			return s3.ObjectInfo{Path: path}, nil
+		}
+		s3Client.TagsReturns(map[string]string{"team": "db"}, nil)
+
+		version := s3.Version{BackupName: "my-backup", Version: "1", Path: "my-backup/1", PartPaths: []string{"my-backup/.s3kup/parts/1/db"}}
+		versionInfo, err := inspector.Inspect(version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(versionInfo.Object.Tags).To(Equal(map[string]string{"team": "db"}))
+		Expect(versionInfo.Parts[0].Tags).To(BeNil())
+
+		Expect(s3Client.TagsCallCount()).To(Equal(1))
+		Expect(s3Client.TagsArgsForCall(0)).To(Equal("my-backup/1"))
+	})
+
+	Context("when fetching the tags fails", func() {
+		It("forwards the error", func() {
+			s3Client.StatReturns(s3.ObjectInfo{TagCount: 1}, nil)
+			s3Client.StatStub = nil
+			s3Client.TagsReturns(nil, errors.New("failed to get the tags"))
+
+			_, err := inspector.Inspect(s3.Version{Path: "my-backup/1"})
+			Expect(err).To(MatchError("failed to get the tags"))
+		})
+	})
+
+	Context("when the stat fails", func() {
+		It("forwards the error", func() {
+			s3Client.StatStub = nil
+			s3Client.StatReturns(s3.ObjectInfo{}, errors.New("failed to stat"))
+
+			_, err := inspector.Inspect(s3.Version{Path: "my-backup/1"})
+			Expect(err).To(MatchError("failed to stat"))
+		})
+	})
+})
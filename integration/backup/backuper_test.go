@@ -30,7 +30,7 @@ var _ = Describe("Backuper", func() {
 	BeforeEach(func() {
 		bucketName := uuid.New()
 		client := s3.New(accessKey, secretKey, bucketName, s3EndpointURL)
-		backuper = backup.New(client, versionsToKeep)
+		backuper = backup.New(client, versionsToKeep, backup.DeletionLimits{MaxDeletes: 5})
 
 		s3Client = testhelpers.BuildGoamzS3(accessKey, secretKey, s3EndpointURL)
 		s3Bucket = s3Client.Bucket(bucketName)
@@ -48,14 +48,14 @@ var _ = Describe("Backuper", func() {
 
 		It("returns an error when the bucket doesnt exist", func() {
 			client := s3.New(accessKey, secretKey, "newBucket", s3EndpointURL)
-			backuper = backup.New(client, versionsToKeep)
+			backuper = backup.New(client, versionsToKeep, backup.DeletionLimits{MaxDeletes: 5})
 
-			err := backuper.Backup(filePath, data)
+			err := backuper.Backup(filePath, data, nil)
 			Expect(err).To(MatchError("The specified bucket does not exist"))
 		})
 
 		It("creates a versioned file on s3", func() {
-			err := backuper.Backup(filePath, data)
+			err := backuper.Backup(filePath, data, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			resp, err := s3Bucket.List(filePath, "", "", 100)
@@ -63,11 +63,11 @@ var _ = Describe("Backuper", func() {
 
 			fileNames := resp.Contents
 			Expect(len(fileNames)).To(Equal(1))
-			Expect(fileNames[0].Key).To(MatchRegexp(fmt.Sprintf("^%s/\\d{19}$", filePath)))
+			Expect(fileNames[0].Key).To(MatchRegexp(fmt.Sprintf("^%s/\\d{19}-[0-9a-f]{16}$", filePath)))
 		})
 
 		It("uploads the correct content to s3", func() {
-			err := backuper.Backup(filePath, data)
+			err := backuper.Backup(filePath, data, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			resp, err := s3Bucket.List(filePath, "", "", 100)
@@ -83,7 +83,7 @@ var _ = Describe("Backuper", func() {
 	Context("keeping track of versions", func() {
 		BeforeEach(func() {
 			for i := 0; i < 3; i++ {
-				err := backuper.Backup(filePath, []byte(fmt.Sprintf("data %d", i)))
+				err := backuper.Backup(filePath, []byte(fmt.Sprintf("data %d", i)), nil)
 				Expect(err).ToNot(HaveOccurred())
 			}
 		})
@@ -125,7 +125,7 @@ var _ = Describe("Backuper", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(resp.Contents)).To(Equal(5))
 
-			err = backuper.Backup(filePath, []byte("data"))
+			err = backuper.Backup(filePath, []byte("data"), nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			resp, err = s3Bucket.List(filePath, "", "", 100)
@@ -143,5 +143,18 @@ var _ = Describe("Backuper", func() {
 			Expect(fileNames).To(ContainElement(fmt.Sprintf("%s/00000000000000003", filePath)))
 		})
 
+		It("refuses to delete more versions than the limit", func() {
+			client := s3.New(accessKey, secretKey, s3Bucket.Name, s3EndpointURL)
+			backuper = backup.New(client, versionsToKeep, backup.DeletionLimits{MaxDeletes: 2})
+
+			err := backuper.Backup(filePath, []byte("data"), nil)
+			Expect(err).To(MatchError(HaveSuffix("Refused to delete 3 old versions, the limit is 2 per run (use --force to override)")))
+			Expect(err).To(BeAssignableToTypeOf(&backup.RetentionError{}))
+
+			resp, err := s3Bucket.List(filePath, "", "", 100)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Contents)).To(Equal(6))
+		})
+
 	})
 })
@@ -0,0 +1,123 @@
+package integration_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > bundles", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+	var tmpDir string
+
+	cliCmd := func(args ...string) *exec.Cmd {
+		args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+		return exec.Command(cli, args...)
+	}
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		err := ioutil.WriteFile(path, []byte(content), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		return path
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "s3kup-bundle")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("pushes the parts as a single version and pulls them back individually", func() {
+		dbPath := writeFile("db.sql", "db content")
+		uploadsPath := writeFile("uploads.tar", "uploads content")
+
+		output, err := cliCmd("push", "--part", "db="+dbPath, "--part", "uploads="+uploadsPath).CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		output, err = cliCmd("list").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp("^\\* [^\n]*\n$"))
+
+		output, err = cliCmd("pull", "--part", "db").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(Equal("db content"))
+
+		output, err = cliCmd("pull", "--part", "uploads").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(Equal("uploads content"))
+	})
+
+	It("fails to pull a part that is not in the bundle", func() {
+		dbPath := writeFile("db.sql", "db content")
+
+		output, err := cliCmd("push", "--part", "db="+dbPath).CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		output, err = cliCmd("pull", "--part", "config").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("The bundle has no part 'config'. Its parts are: db"))
+	})
+
+	It("deletes the parts of old bundles", func() {
+		dbPath := writeFile("db.sql", "db content")
+
+		for i := 0; i < 2; i++ {
+			output, err := cliCmd("push", "-k", "1", "--part", "db="+dbPath).CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+		}
+
+		resp, err := bucket.List(backupName+"/", "", "", 100)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(resp.Contents)).To(Equal(2))
+	})
+
+	It("doesn't create a version when a part can't be read", func() {
+		dbPath := writeFile("db.sql", "db content")
+
+		output, err := cliCmd("push", "--part", "db="+dbPath, "--part", "uploads="+filepath.Join(tmpDir, "missing")).CombinedOutput()
+		Expect(err).To(HaveOccurred(), string(output))
+
+		output, err = cliCmd("list").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(Equal("No versions found\n"))
+	})
+
+	It("fails for malformed parts", func() {
+		output, err := cliCmd("push", "--part", "db").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("Invalid part 'db'. It must be in the format name=path"))
+	})
+
+	It("fails to pull a part of a version that is not a bundle", func() {
+		bucket.Put("my/backup/10000001", []byte("content 1"), "", "")
+
+		output, err := cliCmd("pull", "--part", "db").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("The version is not a bundle"))
+	})
+})
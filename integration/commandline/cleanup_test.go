@@ -0,0 +1,66 @@
+package integration_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > cleanup", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	cliCmd := func(args ...string) *exec.Cmd {
+		args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+		return exec.Command(cli, args...)
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		bucket.Put("my/backup/10000001", []byte("content 1"), "", "")
+		bucket.Put("my/backup/.s3kup/staging/10000002", []byte("partial"), "", "")
+		bucket.Put("my/backup/.s3kup/parts/10000003/db", []byte("partial"), "", "")
+	})
+
+	It("doesn't list the uncommitted uploads", func() {
+		output, err := cliCmd("list").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp("^\\* 10000001[^\n]*\n$"))
+	})
+
+	It("removes the uncommitted uploads older than --older-than", func() {
+		output, err := cliCmd("cleanup", "--older-than", "0s").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(Equal("Deleted 2 stale uploads\n"))
+
+		resp, err := bucket.List("my/backup/", "", "", 100)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(resp.Contents)).To(Equal(1))
+		Expect(resp.Contents[0].Key).To(Equal("my/backup/10000001"))
+	})
+
+	It("keeps recent uploads by default", func() {
+		output, err := cliCmd("cleanup").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(Equal("Deleted 0 stale uploads\n"))
+
+		resp, err := bucket.List("my/backup/", "", "", 100)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(resp.Contents)).To(Equal(3))
+	})
+})
@@ -0,0 +1,119 @@
+package integration_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > completion", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+	var cacheDir string
+
+	completeCmd := func(args ...string) *exec.Cmd {
+		args = append([]string{"__complete"}, args...)
+		cmd := exec.Command(cli, args...)
+		cmd.Env = append(os.Environ(),
+			"XDG_CACHE_HOME="+cacheDir,
+			"S3KUP_ACCESS_KEY="+accessKey,
+			"S3KUP_SECRET_KEY="+secretKey,
+			"S3KUP_BUCKET_NAME="+bucketName,
+			"S3KUP_ENDPOINT_URL="+s3EndpointURL,
+		)
+		return cmd
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		var err error
+		cacheDir, err = ioutil.TempDir("", "s3kup-cache")
+		Expect(err).ToNot(HaveOccurred())
+
+		bucket.Put("my/backup/1427571015905296950-0000000000000001", []byte("content 1"), "", "")
+		bucket.Put("my/backup/1427571015905296951-0000000000000002", []byte("content 2"), "", "")
+		bucket.Put("my/backup/.s3kup/pins/1427571015905296950-0000000000000001", []byte{}, "", "")
+		bucket.Put("my/other/1427571015905296950-0000000000000001", []byte("content"), "", "")
+		bucket.Put("mysql/1427571015905296950-0000000000000001", []byte("content"), "", "")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(cacheDir)
+	})
+
+	It("prints the completion scripts", func() {
+		for _, shell := range []string{"bash", "zsh", "fish"} {
+			output, err := exec.Command(cli, "completion", shell).Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring("s3kup"))
+		}
+
+		output, err := exec.Command(cli, "completion", "powershell").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(ContainSubstring(`invalid argument "powershell"`))
+	})
+
+	It("suggests the versions of the backup, newest first", func() {
+		output, err := completeCmd("pull", "-n", backupName, "").Output()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(output)).To(HavePrefix(
+			"1427571015905296951-0000000000000002\t2015-03-28T19:30:15Z\n" +
+				"1427571015905296950-0000000000000001\t2015-03-28T19:30:15Z, pinned\n" +
+				":36\n"))
+	})
+
+	It("suggests only one version for pull and info, and many for delete", func() {
+		output, err := completeCmd("info", "-n", backupName, "1427571015905296950-0000000000000001", "").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(HavePrefix(":4\n"))
+
+		output, err = completeCmd("delete", "-n", backupName, "1427571015905296950-0000000000000001", "").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(ContainSubstring("1427571015905296951-0000000000000002\t"))
+	})
+
+	It("caches the versions for a short time", func() {
+		_, err := completeCmd("pull", "-n", backupName, "").Output()
+		Expect(err).ToNot(HaveOccurred())
+
+		bucket.Put("my/backup/1427571015905296952-0000000000000003", []byte("content 3"), "", "")
+
+		output, err := completeCmd("pull", "-n", backupName, "").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).ToNot(ContainSubstring("1427571015905296952"))
+	})
+
+	It("suggests the backup names", func() {
+		output, err := completeCmd("list", "-n", "my/").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(HavePrefix("my/backup\nmy/other\n:4\n"))
+
+		output, err = completeCmd("list", "-n", "").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(HavePrefix("my/backup\nmy/other\nmysql\n:4\n"))
+	})
+
+	It("suggests nothing when the bucket can't be listed", func() {
+		output, err := completeCmd("pull", "-n", backupName, "-b", "missing-bucket", "").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(HavePrefix(":4\n"))
+	})
+})
@@ -0,0 +1,93 @@
+package integration_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > config", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+	var configDir string
+	var configPath string
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		var err error
+		configDir, err = ioutil.TempDir("", "s3kup-config")
+		Expect(err).ToNot(HaveOccurred())
+
+		credentialsPath := filepath.Join(configDir, "credentials")
+		credentials := fmt.Sprintf("[backups]\naws_access_key_id = %s\naws_secret_access_key = %s\n", accessKey, secretKey)
+		Expect(ioutil.WriteFile(credentialsPath, []byte(credentials), 0600)).To(Succeed())
+
+		configPath = filepath.Join(configDir, "config.yaml")
+		config := fmt.Sprintf(`
+endpoint-url: %s
+bucket-name: %s
+credentials-file: %s
+profiles:
+  pg-prod:
+    file-name: pg/prod
+    credentials: backups
+    versions-to-keep: 2
+`, s3EndpointURL, bucketName, credentialsPath)
+		Expect(ioutil.WriteFile(configPath, []byte(config), 0600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(configDir)
+	})
+
+	It("pushes with the settings of the profile", func() {
+		bucket.Put("pg/prod/10000001", []byte("content 1"), "", "")
+		bucket.Put("pg/prod/10000002", []byte("content 2"), "", "")
+
+		pushCmd := exec.Command(cli, "push", "--config", configPath, "--profile", "pg-prod")
+		_, err := runPipedCmdsAndReturnLastOutput(exec.Command("echo", "store my data"), pushCmd)
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := bucket.List("pg/prod/", "", "", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Contents).To(HaveLen(2))
+		Expect(resp.Contents[0].Key).To(Equal("pg/prod/10000002"))
+	})
+
+	It("shows the effective settings and their source", func() {
+		output, err := exec.Command(cli, "config", "show", "--config", configPath, "--profile", "pg-prod", "-n", "pg/other").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		Expect(string(output)).To(ContainSubstring("Config file:  " + configPath + "\n"))
+		Expect(string(output)).To(ContainSubstring("Profile:      pg-prod\n"))
+		Expect(string(output)).To(MatchRegexp(`bucket-name\s+` + bucketName + `\s+config file ` + configPath + "\n"))
+		Expect(string(output)).To(MatchRegexp(`file-name\s+pg/other\s+flag --file-name\n`))
+		Expect(string(output)).To(MatchRegexp(`access-key\s+my_id\s+credentials 'backups' in ` + filepath.Join(configDir, "credentials") + "\n"))
+		Expect(string(output)).To(MatchRegexp(`secret-key\s+\*{8}\s+credentials 'backups'`))
+		Expect(string(output)).To(MatchRegexp(`versions-to-keep\s+2\s+profile 'pg-prod' in ` + configPath + "\n"))
+		Expect(string(output)).To(MatchRegexp(`max-deletes\s+2\s+default\n`))
+	})
+
+	It("fails for unknown profiles", func() {
+		output, err := exec.Command(cli, "list", "--config", configPath, "--profile", "unknown").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(ContainSubstring("Could not find profile 'unknown'"))
+	})
+})
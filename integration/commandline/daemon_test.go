@@ -0,0 +1,58 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > daemon", func() {
+	var dir string
+	var jobsPath string
+	var statePath string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "s3kup-daemon")
+		Expect(err).ToNot(HaveOccurred())
+
+		jobsPath = filepath.Join(dir, "jobs.yaml")
+		statePath = filepath.Join(dir, "daemon.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("refuses invalid jobs files", func() {
+		jobs := "jobs:\n  - name: pg-prod\n    schedule: '0 25 * * *'\n    command: [pg_dump]\n"
+		Expect(ioutil.WriteFile(jobsPath, []byte(jobs), 0600)).To(Succeed())
+
+		output, err := exec.Command(cli, "daemon", "--jobs", jobsPath, "--state-file", statePath).CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(ContainSubstring("Invalid schedule '0 25 * * *' of job 'pg-prod': invalid hour '25'"))
+	})
+
+	It("prints the state of the jobs", func() {
+		state := `{
+  "mysql": {"last_start": "2026-10-19T02:30:00Z", "last_end": "2026-10-19T02:31:00Z", "last_success": "2026-10-19T02:31:00Z", "last_status": "succeeded", "next_run": "2026-10-20T02:30:00Z", "runs": 3},
+  "pg-prod": {"last_start": "2026-10-19T03:00:00Z", "last_status": "failed", "last_error": "Command 'pg_dump' exited with status 1", "next_run": "2026-10-20T03:00:00Z", "runs": 2, "failures": 2, "missed": 1}
+}`
+		Expect(ioutil.WriteFile(statePath, []byte(state), 0600)).To(Succeed())
+
+		cmd := exec.Command(cli, "daemon", "status")
+		cmd.Env = append(os.Environ(), "S3KUP_DAEMON_STATE_FILE="+statePath)
+		output, err := cmd.CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		Expect(string(output)).To(Equal(
+			"JOB      STATUS     LAST RUN              LAST SUCCESS          NEXT RUN              RUNS  FAILURES  SKIPPED  MISSED\n" +
+				"mysql    succeeded  2026-10-19T02:30:00Z  2026-10-19T02:31:00Z  2026-10-20T02:30:00Z  3     0         0        0\n" +
+				"pg-prod  failed     2026-10-19T03:00:00Z  -                     2026-10-20T03:00:00Z  2     2         0        1\n" +
+				"\npg-prod: Command 'pg_dump' exited with status 1\n"))
+	})
+})
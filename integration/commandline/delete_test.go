@@ -0,0 +1,153 @@
+package integration_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+	"strings"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > delete", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	cliCmd := func(args ...string) *exec.Cmd {
+		args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+		return exec.Command(cli, args...)
+	}
+
+	storedKeys := func() []string {
+		resp, err := bucket.List("my/backup/", "", "", 1000)
+		Expect(err).ToNot(HaveOccurred())
+
+		keys := []string{}
+		for _, key := range resp.Contents {
+			keys = append(keys, key.Key)
+		}
+		return keys
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		bucket.Put("my/backup/10000001", []byte("content 1"), "", "")
+		bucket.Put("my/backup/10000002", []byte("content 2"), "", "")
+		bucket.Put("my/backup/.s3kup/pins/10000002", []byte{}, "", "")
+		bucket.Put("my/backup/10000003", []byte("manifest"), "", "")
+		bucket.Put("my/backup/.s3kup/parts/10000003/db", []byte("db"), "", "")
+		bucket.Put("my/backup/10000004", []byte("content 4"), "", "")
+	})
+
+	It("deletes the given versions and their parts", func() {
+		output, err := cliCmd("delete", "10000001", "10000003", "--yes").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("Versions to delete from 'my/backup':\n* 10000001"))
+		Expect(string(output)).To(ContainSubstring("Deleted 2 versions (3 objects)\n"))
+
+		Expect(storedKeys()).To(ConsistOf(
+			"my/backup/10000002",
+			"my/backup/.s3kup/pins/10000002",
+			"my/backup/10000004",
+		))
+	})
+
+	It("accepts selectors", func() {
+		output, err := cliCmd("delete", "oldest", "--yes").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(storedKeys()).ToNot(ContainElement("my/backup/10000001"))
+		Expect(len(storedKeys())).To(Equal(5))
+	})
+
+	It("deletes the unpinned versions older than the given duration", func() {
+		output, err := cliCmd("delete", "--older-than", "1h", "--yes").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		Expect(storedKeys()).To(ConsistOf(
+			"my/backup/10000002",
+			"my/backup/.s3kup/pins/10000002",
+		))
+	})
+
+	It("deletes everything but the pinned versions with --all", func() {
+		bucket.Put("my/backup/.s3kup/staging/10000005", []byte("partial"), "", "")
+
+		output, err := cliCmd("delete", "--all", "--yes").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("Deleted 3 versions (5 objects)\n"))
+		Expect(storedKeys()).To(ConsistOf(
+			"my/backup/10000002",
+			"my/backup/.s3kup/pins/10000002",
+		))
+	})
+
+	It("deletes everything with --all --include-pinned", func() {
+		bucket.Put("my/backup/.s3kup/staging/10000005", []byte("partial"), "", "")
+
+		output, err := cliCmd("delete", "--all", "--include-pinned", "--yes").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("Deleted 4 versions (7 objects)\n"))
+		Expect(storedKeys()).To(BeEmpty())
+	})
+
+	It("refuses to delete pinned versions", func() {
+		output, err := cliCmd("delete", "10000002", "--yes").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("Version '10000002' is pinned. Unpin it before deleting it"))
+		Expect(len(storedKeys())).To(Equal(6))
+	})
+
+	It("only prints what would be deleted on a dry run", func() {
+		output, err := cliCmd("delete", "10000001", "--dry-run").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("* 10000001"))
+		Expect(string(output)).To(ContainSubstring("Dry run, nothing was deleted\n"))
+		Expect(len(storedKeys())).To(Equal(6))
+	})
+
+	Context("without --yes", func() {
+		It("deletes when confirmed", func() {
+			cmd := cliCmd("delete", "10000001")
+			cmd.Stdin = strings.NewReader("y\n")
+			output, err := cmd.CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(string(output)).To(ContainSubstring("Delete 1 versions (1 objects) of 'my/backup'? [y/N]: "))
+			Expect(storedKeys()).ToNot(ContainElement("my/backup/10000001"))
+		})
+
+		It("aborts otherwise", func() {
+			cmd := cliCmd("delete", "10000001")
+			cmd.Stdin = strings.NewReader("\n")
+			output, err := cmd.CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(MatchRegexp("Aborted, nothing was deleted"))
+			Expect(len(storedKeys())).To(Equal(6))
+		})
+	})
+
+	It("fails when nothing is selected", func() {
+		output, err := cliCmd("delete").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("Specify the versions to delete, --older-than or --all"))
+	})
+
+	It("fails when --include-pinned is given without --all", func() {
+		output, err := cliCmd("delete", "10000002", "--include-pinned").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("--include-pinned can only be combined with --all"))
+		Expect(len(storedKeys())).To(Equal(6))
+	})
+})
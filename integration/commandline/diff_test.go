@@ -0,0 +1,113 @@
+package integration_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"math/rand"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > diff", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	diffCmd := func(args ...string) *exec.Cmd {
+		args = append([]string{"diff", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName}, args...)
+		return exec.Command(cli, args...)
+	}
+
+	gzipped := func(content string) []byte {
+		compressed := new(bytes.Buffer)
+		writer := gzip.NewWriter(compressed)
+		writer.Write([]byte(content))
+		writer.Close()
+		return compressed.Bytes()
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		bucket.Put("my/backup/10000001", []byte("create table a;\ncreate table b;\n"), "", "")
+		bucket.Put("my/backup/10000002", []byte("create table a;\ncreate table c;\n"), "", "")
+	})
+
+	differs := func(err error) bool {
+		exitErr, ok := err.(*exec.ExitError)
+		return ok && exitErr.ExitCode() == 1
+	}
+
+	It("writes a unified diff of two text versions", func() {
+		output, err := diffCmd("10000001", "latest").Output()
+		Expect(differs(err)).To(BeTrue())
+		Expect(string(output)).To(ContainSubstring("a: my/backup/10000001 ("))
+		Expect(string(output)).To(ContainSubstring("b: my/backup/10000002 ("))
+		Expect(string(output)).To(HaveSuffix(`--- a/10000001
++++ b/10000002
+@@ -1,2 +1,2 @@
+ create table a;
+-create table b;
++create table c;
+`))
+	})
+
+	It("reports identical versions", func() {
+		bucket.Put("my/backup/10000003", []byte("create table a;\ncreate table c;\n"), "", "")
+
+		output, err := diffCmd("@-1", "@").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(HaveSuffix("The contents are identical\n"))
+	})
+
+	It("summarizes the differences of binary versions", func() {
+		bucket.Put("my/backup/10000003", []byte("\x00\x01\x02\x03"), "", "")
+		bucket.Put("my/backup/10000004", []byte("\x00\x01\x09\x03\x04"), "", "")
+
+		output, err := diffCmd("@-1", "@").Output()
+		Expect(differs(err)).To(BeTrue())
+		Expect(string(output)).To(ContainSubstring("Size: 4 bytes -> 5 bytes\n"))
+		Expect(string(output)).To(HaveSuffix("The contents differ in 2 byte ranges, 2 bytes in total:\n  bytes 2-2\n  bytes 4-4 (only in b)\n"))
+	})
+
+	It("compares compressed versions with --decompress", func() {
+		bucket.Put("my/backup/10000003", gzipped("one\ntwo\n"), "", "")
+		bucket.Put("my/backup/10000004", gzipped("one\n2\n"), "", "")
+
+		output, err := diffCmd("@-1", "@", "--decompress").Output()
+		Expect(differs(err)).To(BeTrue())
+		Expect(string(output)).To(HaveSuffix("@@ -1,2 +1,2 @@\n one\n-two\n+2\n"))
+	})
+
+	It("exits with 2 without two versions", func() {
+		output, err := diffCmd("10000001").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+
+		exitErr, ok := err.(*exec.ExitError)
+		Expect(ok).To(BeTrue())
+		Expect(exitErr.ExitCode()).To(Equal(2))
+		Expect(string(output)).To(ContainSubstring("Give the two versions to compare"))
+	})
+
+	It("exits with 4 when a version doesn't exist", func() {
+		output, err := diffCmd("10000001", "10000009").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+
+		exitErr, ok := err.(*exec.ExitError)
+		Expect(ok).To(BeTrue())
+		Expect(exitErr.ExitCode()).To(Equal(4))
+		Expect(string(output)).To(ContainSubstring("10000009"))
+	})
+})
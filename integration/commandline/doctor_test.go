@@ -0,0 +1,63 @@
+package integration_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > doctor", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	doctorCmd := func(bucketName string) *exec.Cmd {
+		return exec.Command(cli, "doctor", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+	})
+
+	It("passes every check and leaves nothing behind", func() {
+		output, err := doctorCmd(bucketName).CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		for _, name := range []string{"endpoint", "clock", "credentials", "list", "put", "get", "delete"} {
+			Expect(string(output)).To(MatchRegexp(`(?m)^OK\s+%s\s`, name))
+		}
+
+		resp, err := bucket.List("", "", "", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Contents).To(BeEmpty())
+	})
+
+	Context("when the bucket doesn't exist", func() {
+		It("reports the failed operations and exits with an error", func() {
+			output, err := doctorCmd(bucketName + "-missing").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+
+			exitErr, ok := err.(*exec.ExitError)
+			Expect(ok).To(BeTrue())
+			Expect(exitErr.ExitCode()).To(Equal(4))
+
+			Expect(string(output)).To(MatchRegexp(`(?m)^OK\s+endpoint\s`))
+			Expect(string(output)).To(MatchRegexp(`(?m)^FAILED\s+list\s+NoSuchBucket: `))
+			Expect(string(output)).To(MatchRegexp(`(?m)^FAILED\s+put\s+NoSuchBucket: `))
+			Expect(string(output)).To(MatchRegexp(`(?m)^SKIPPED\s+get\s+the put failed`))
+			Expect(string(output)).To(ContainSubstring("s3kup: error class=not-found exit=4"))
+		})
+	})
+})
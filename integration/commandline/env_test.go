@@ -0,0 +1,121 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > environment", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+	var env []string
+
+	envCmd := func(args ...string) *exec.Cmd {
+		cmd := exec.Command(cli, args...)
+		cmd.Env = append(os.Environ(), env...)
+		return cmd
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		bucket.Put("my/backup/10000001", []byte("content 1"), "", "")
+
+		env = []string{
+			"S3KUP_ACCESS_KEY=" + accessKey,
+			"S3KUP_SECRET_KEY=" + secretKey,
+			"S3KUP_BUCKET_NAME=" + bucketName,
+			"S3KUP_ENDPOINT_URL=" + s3EndpointURL,
+			"S3KUP_FILE_NAME=" + backupName,
+		}
+	})
+
+	It("reads the global settings from the environment", func() {
+		output, err := envCmd("list").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("10000001"))
+	})
+
+	It("reads the push settings from the environment", func() {
+		bucket.Put("my/backup/10000002", []byte("content 2"), "", "")
+		env = append(env, "S3KUP_VERSIONS_TO_KEEP=1")
+
+		_, err := runPipedCmdsAndReturnLastOutput(exec.Command("echo", "store my data"), envCmd("push"))
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := bucket.List("my/backup/", "", "", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Contents).To(HaveLen(1))
+		Expect(resp.Contents[0].Key).ToNot(HavePrefix("my/backup/1000000"))
+	})
+
+	It("reads the subcommand flags from variables named after the command", func() {
+		env = append(env, "S3KUP_LIST_OUTPUT=json")
+
+		output, err := envCmd("list").Output()
+		Expect(err).ToNot(HaveOccurred())
+
+		var records []map[string]interface{}
+		Expect(json.Unmarshal(output, &records)).To(Succeed())
+		Expect(records).To(HaveLen(1))
+	})
+
+	It("fails for invalid subcommand values", func() {
+		env = append(env, "S3KUP_INFO_OLDEST=maybe")
+
+		output, err := envCmd("info").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(ContainSubstring("Invalid value 'maybe' in S3KUP_INFO_OLDEST"))
+	})
+
+	It("prefers the flags over the environment", func() {
+		output, err := envCmd("list", "-n", "other/backup").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).ToNot(ContainSubstring("10000001"))
+	})
+
+	It("prefers the environment over the config file", func() {
+		configDir, err := ioutil.TempDir("", "s3kup-config")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(configDir)
+
+		configPath := filepath.Join(configDir, "config.yaml")
+		Expect(ioutil.WriteFile(configPath, []byte("file-name: other/backup\n"), 0600)).To(Succeed())
+		env = append(env, "S3KUP_CONFIG="+configPath)
+
+		output, err := envCmd("list").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("10000001"))
+
+		output, err = envCmd("config", "show").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp(`file-name\s+my/backup\s+env S3KUP_FILE_NAME\n`))
+	})
+
+	It("names the variable of missing settings", func() {
+		env = []string{}
+
+		output, err := envCmd("list").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(ContainSubstring("Set it with --bucket-name, S3KUP_BUCKET_NAME or in the config file"))
+	})
+})
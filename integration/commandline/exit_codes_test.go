@@ -0,0 +1,83 @@
+package integration_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+	"strings"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > exit codes", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	cliCmd := func(args ...string) *exec.Cmd {
+		args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+		return exec.Command(cli, args...)
+	}
+
+	exitCode := func(cmd *exec.Cmd) (int, string) {
+		output, err := cmd.CombinedOutput()
+		Expect(err).To(HaveOccurred())
+
+		exitErr, ok := err.(*exec.ExitError)
+		Expect(ok).To(BeTrue(), err.Error())
+		return exitErr.ExitCode(), string(output)
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		bucket.Put("my/backup/10000001", []byte("test"), "", "")
+	})
+
+	It("exits with 2 for invalid flags and arguments", func() {
+		code, output := exitCode(exec.Command(cli, "list", "-a", accessKey, "-s", secretKey, "-e", s3EndpointURL, "-n", backupName))
+		Expect(code).To(Equal(2))
+		Expect(output).To(ContainSubstring(`s3kup: error class=usage exit=2 message="missing bucket name argument.`))
+
+		code, output = exitCode(cliCmd("list", "--not-a-flag"))
+		Expect(code).To(Equal(2))
+		Expect(output).To(ContainSubstring(`s3kup: error class=usage exit=2 message="unknown flag: --not-a-flag"`))
+	})
+
+	It("exits with 4 when the version doesn't exist", func() {
+		code, output := exitCode(cliCmd("pull", "10000002"))
+		Expect(code).To(Equal(4))
+		Expect(output).To(ContainSubstring(`s3kup: error class=not-found exit=4 message="Could not find version '10000002'"`))
+	})
+
+	It("exits with 6 when the input is empty", func() {
+		cmd := cliCmd("push")
+		cmd.Stdin = strings.NewReader("")
+
+		code, output := exitCode(cmd)
+		Expect(code).To(Equal(6))
+		Expect(output).To(ContainSubstring(`s3kup: error class=empty-input exit=6 message="The input is empty. Nothing was pushed"`))
+	})
+
+	It("exits with 8 when the command of `run` fails", func() {
+		code, output := exitCode(exec.Command(cli, "run", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "--", "sh", "-c", "exit 3"))
+		Expect(code).To(Equal(8))
+		Expect(output).To(ContainSubstring(`s3kup: error class=command-failed exit=8 message="Command 'sh' exited with status 3. Nothing was pushed"`))
+	})
+
+	It("prints the summary as the last line", func() {
+		_, output := exitCode(cliCmd("pull", "10000002"))
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		Expect(lines[len(lines)-1]).To(HavePrefix("s3kup: error "))
+	})
+})
@@ -0,0 +1,95 @@
+package integration_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > fsck", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	cliCmd := func(args ...string) *exec.Cmd {
+		args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+		return exec.Command(cli, args...)
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		bucket.Put("my/backup/10000001", []byte("content 1"), "", "")
+		bucket.Put("my/backup/10000002", []byte("content 2"), "", "")
+	})
+
+	Context("when there are foreign keys", func() {
+		BeforeEach(func() {
+			bucket.Put("my/backup/README", []byte("read me"), "", "")
+		})
+
+		It("still lists and pulls the versions", func() {
+			output, err := cliCmd("list").CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(string(output)).To(MatchRegexp("\\* 10000001"))
+			Expect(string(output)).To(MatchRegexp("\\* 10000002"))
+			Expect(string(output)).To(MatchRegexp("skipping unrecognized key: my/backup/README"))
+
+			output, err = cliCmd("pull").Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(Equal("content 2"))
+		})
+	})
+
+	It("reports a healthy backup", func() {
+		output, err := cliCmd("fsck").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(Equal("No problems found\n"))
+	})
+
+	Context("when there are problems", func() {
+		BeforeEach(func() {
+			bucket.Put("my/backup/README", []byte("read me"), "", "")
+			bucket.Put("my/backup/10000003", []byte{}, "", "")
+			bucket.Put("my/backup/.s3kup/staging/10000004", []byte("partial"), "", "")
+			bucket.Put("my/backup/.s3kup/pins/10000005", []byte{}, "", "")
+		})
+
+		It("reports them and fails", func() {
+			output, err := cliCmd("fsck").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring("foreign\tmy/backup/README\tnot a version nor s3kup metadata\n"))
+			Expect(string(output)).To(ContainSubstring("staging\tmy/backup/.s3kup/staging/10000004\tstaged upload that was never committed\n"))
+			Expect(string(output)).To(ContainSubstring("orphan-pin\tmy/backup/.s3kup/pins/10000005\tpin of a version that doesn't exist\n"))
+			Expect(string(output)).To(ContainSubstring("zero-byte\tmy/backup/10000003\tversion '10000003' is empty\n"))
+			Expect(string(output)).To(MatchRegexp("4 problems found"))
+		})
+
+		It("repairs the ones that can be repaired", func() {
+			output, err := cliCmd("fsck", "--repair", "--older-than", "0s").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring("Repaired 2 problems\n"))
+			Expect(string(output)).To(MatchRegexp("2 problems found"))
+
+			_, err = bucket.Get("my/backup/.s3kup/staging/10000004")
+			Expect(err).To(HaveOccurred())
+			_, err = bucket.Get("my/backup/.s3kup/pins/10000005")
+			Expect(err).To(HaveOccurred())
+			_, err = bucket.Get("my/backup/README")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
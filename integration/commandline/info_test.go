@@ -0,0 +1,96 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > info", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	cliCmd := func(args ...string) *exec.Cmd {
+		args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+		return exec.Command(cli, args...)
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		labels := map[string][]string{"X-Amz-Meta-S3kup-Labels": {"kind=nightly"}}
+		bucket.PutHeader("my/backup/10000001", []byte("test"), labels, "")
+		bucket.Put("my/backup/.s3kup/pins/10000001", []byte{}, "", "")
+		bucket.Put("my/backup/10000002", []byte("manifest"), "", "")
+		bucket.Put("my/backup/.s3kup/parts/10000002/db", []byte("db"), "", "")
+	})
+
+	It("prints the details of the version", func() {
+		output, err := cliCmd("info", "10000001").Output()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(output)).To(ContainSubstring("Name:           my/backup\n"))
+		Expect(string(output)).To(ContainSubstring("Version:        10000001\n"))
+		Expect(string(output)).To(ContainSubstring("Pinned:         true\n"))
+		Expect(string(output)).To(ContainSubstring("Labels:         kind=nightly\n"))
+		Expect(string(output)).To(ContainSubstring("Path:           my/backup/10000001\n"))
+		Expect(string(output)).To(ContainSubstring("Size:           4 (4B)\n"))
+		Expect(string(output)).To(ContainSubstring("ETag:           098f6bcd4621d373cade4e832627b4f6\n"))
+		Expect(string(output)).To(ContainSubstring("Storage class:  STANDARD\n"))
+		Expect(string(output)).To(ContainSubstring("Checksum:       md5:098f6bcd4621d373cade4e832627b4f6\n"))
+		Expect(string(output)).To(ContainSubstring("Metadata:\n  s3kup-labels: kind=nightly\n"))
+		Expect(string(output)).To(MatchRegexp("Last modified:  \\d{4}-\\d{2}-\\d{2}T\\d{2}:\\d{2}:\\d{2}Z\n"))
+	})
+
+	It("prints the parts of a bundle and selects the latest version by default", func() {
+		output, err := cliCmd("info").Output()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(output)).To(ContainSubstring("Version:        10000002\n"))
+		Expect(string(output)).To(ContainSubstring("\nPart db\nPath:           my/backup/.s3kup/parts/10000002/db\nSize:           2 (2B)\n"))
+	})
+
+	It("prints json", func() {
+		output, err := cliCmd("info", "oldest", "--output", "json").Output()
+		Expect(err).ToNot(HaveOccurred())
+
+		var record map[string]interface{}
+		Expect(json.Unmarshal(output, &record)).To(Succeed())
+		Expect(record["name"]).To(Equal("my/backup"))
+		Expect(record["version"]).To(Equal("10000001"))
+		Expect(record["path"]).To(Equal("my/backup/10000001"))
+		Expect(record["size"]).To(Equal(float64(4)))
+		Expect(record["etag"]).To(Equal("098f6bcd4621d373cade4e832627b4f6"))
+		Expect(record["storage_class"]).To(Equal("STANDARD"))
+		Expect(record["pinned"]).To(Equal(true))
+		Expect(record["labels"]).To(Equal(map[string]interface{}{"kind": "nightly"}))
+		Expect(record["metadata"]).To(Equal(map[string]interface{}{"s3kup-labels": "kind=nightly"}))
+		Expect(record["parts"]).To(BeEmpty())
+		Expect(record).To(HaveKey("last_modified"))
+		Expect(record).To(HaveKey("encryption"))
+		Expect(record).To(HaveKey("lock_mode"))
+		Expect(record).To(HaveKey("checksum"))
+		Expect(record).To(HaveKey("tag_count"))
+	})
+
+	It("fails for unknown versions", func() {
+		output, err := cliCmd("info", "19999999").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("Could not find version '19999999'"))
+	})
+})
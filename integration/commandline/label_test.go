@@ -0,0 +1,124 @@
+package integration_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > labels", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	cliCmd := func(args ...string) *exec.Cmd {
+		args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+		return exec.Command(cli, args...)
+	}
+
+	push := func(content string, args ...string) {
+		pushCmd := cliCmd(append([]string{"push"}, args...)...)
+		_, err := runPipedCmdsAndReturnLastOutput(exec.Command("echo", content), pushCmd)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+	})
+
+	It("shows the labels and filters by them when listing", func() {
+		push("nightly data", "--label", "kind=nightly")
+		push("pre-deploy data", "--label", "kind=pre-deploy")
+
+		output, err := cliCmd("list").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).ToNot(MatchRegexp("kind="))
+
+		output, err = cliCmd("list", "--show-labels").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp("\tkind=nightly\n"))
+		Expect(string(output)).To(MatchRegexp("\tkind=pre-deploy\n"))
+
+		output, err = cliCmd("list", "--label", "kind=nightly").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp("\tkind=nightly\n"))
+		Expect(string(output)).ToNot(MatchRegexp("kind=pre-deploy"))
+	})
+
+	It("pulls the latest version with the label", func() {
+		push("nightly data", "--label", "kind=nightly")
+		push("pre-deploy data", "--label", "kind=pre-deploy")
+
+		output, err := cliCmd("pull", "--label", "kind=nightly").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(Equal("nightly data\n"))
+	})
+
+	It("fails to pull when no version has the label", func() {
+		push("nightly data", "--label", "kind=nightly")
+
+		output, err := cliCmd("pull", "--label", "kind=manual").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("There's no version of 'my/backup' with the labels 'kind=manual'"))
+	})
+
+	It("keeps versions-to-keep per label", func() {
+		for i := 0; i < 3; i++ {
+			push("nightly data", "--label", "kind=nightly", "-k", "2")
+		}
+		push("pre-deploy data", "--label", "kind=pre-deploy", "-k", "1")
+		push("pre-deploy data", "--label", "kind=pre-deploy", "-k", "1")
+
+		output, err := cliCmd("list", "--label", "kind=nightly").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp("^(\\* .*\n){2}$"))
+
+		output, err = cliCmd("list", "--label", "kind=pre-deploy").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp("^(\\* .*\n){1}$"))
+	})
+
+	It("keeps the given number of versions per label on every push", func() {
+		for i := 0; i < 3; i++ {
+			push("nightly data", "--label", "kind=nightly")
+		}
+		push("pre-deploy data", "--label", "kind=pre-deploy")
+		push("pre-deploy data", "--label", "kind=pre-deploy")
+		push("manual data", "--label", "kind=manual", "--keep", "kind=nightly=2", "--keep", "kind=pre-deploy=1")
+
+		output, err := cliCmd("list", "--label", "kind=nightly").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp("^(\\* .*\n){2}$"))
+
+		output, err = cliCmd("list", "--label", "kind=pre-deploy").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp("^(\\* .*\n){1}$"))
+	})
+
+	It("fails for malformed keep rules", func() {
+		pushCmd := cliCmd("push", "--keep", "kind=nightly")
+		output, err := runPipedCmdsAndReturnLastOutput(exec.Command("echo", "data"), pushCmd)
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("Invalid keep rule 'kind=nightly'. The number of versions must be 1 or greater"))
+	})
+
+	It("fails for malformed labels", func() {
+		pushCmd := cliCmd("push", "--label", "nightly")
+		output, err := runPipedCmdsAndReturnLastOutput(exec.Command("echo", "data"), pushCmd)
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("Invalid label 'nightly'. It must be in the format key=value"))
+	})
+})
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"math/rand"
 	"os/exec"
+	"regexp"
+	"strings"
 
 	"github.com/mitchellh/goamz/s3"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
 )
 
 var _ = Describe("Cli > list", func() {
@@ -48,6 +51,139 @@ var _ = Describe("Cli > list", func() {
 		})
 	})
 
+	Context("when an output format is given", func() {
+		const (
+			pinnedID string = "1790823600000000000-0000000000000000"
+			bundleID string = "1790910000000000000-0000000000000000"
+		)
+
+		var bucketName string
+
+		listWith := func(args ...string) *exec.Cmd {
+			args = append([]string{"list"}, args...)
+			args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+			return exec.Command(cli, args...)
+		}
+
+		matchOutput := func(expected string) types.GomegaMatcher {
+			pattern := regexp.QuoteMeta(expected)
+			pattern = strings.Replace(pattern, "LAST_MODIFIED", "\\d{4}-\\d{2}-\\d{2}T\\d{2}:\\d{2}:\\d{2}Z", -1)
+			return MatchRegexp("^" + pattern + "$")
+		}
+
+		BeforeEach(func() {
+			bucketName = fmt.Sprintf("bucket%d", rand.Int())
+			bucket = s3Bucket(accessKey, secretKey, bucketName)
+			bucket.PutBucket("")
+
+			labels := map[string][]string{"X-Amz-Meta-S3kup-Labels": {"kind=nightly"}}
+			bucket.PutHeader("my/backup/"+pinnedID, []byte("content 1"), labels, "")
+			bucket.Put("my/backup/.s3kup/pins/"+pinnedID, []byte{}, "", "")
+			bucket.Put("my/backup/"+bundleID, []byte("manifest"), "", "")
+			bucket.Put("my/backup/.s3kup/parts/"+bundleID+"/db", []byte("db"), "", "")
+		})
+
+		It("prints json", func() {
+			output, err := listWith("--output", "json").Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(matchOutput(`[
+  {
+    "name": "my/backup",
+    "version": "1790823600000000000-0000000000000000",
+    "path": "my/backup/1790823600000000000-0000000000000000",
+    "size": 9,
+    "created_at": "2026-10-01T03:00:00Z",
+    "last_modified": "LAST_MODIFIED",
+    "pinned": true,
+    "labels": {
+      "kind": "nightly"
+    },
+    "parts": []
+  },
+  {
+    "name": "my/backup",
+    "version": "1790910000000000000-0000000000000000",
+    "path": "my/backup/1790910000000000000-0000000000000000",
+    "size": 8,
+    "created_at": "2026-10-02T03:00:00Z",
+    "last_modified": "LAST_MODIFIED",
+    "pinned": false,
+    "labels": {},
+    "parts": [
+      "db"
+    ]
+  }
+]
+`))
+		})
+
+		It("prints yaml", func() {
+			output, err := listWith("--output", "yaml").Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(matchOutput(`- name: my/backup
+  version: 1790823600000000000-0000000000000000
+  path: my/backup/1790823600000000000-0000000000000000
+  size: 9
+  created_at: "2026-10-01T03:00:00Z"
+  last_modified: "LAST_MODIFIED"
+  pinned: true
+  labels:
+    kind: nightly
+  parts: []
+- name: my/backup
+  version: 1790910000000000000-0000000000000000
+  path: my/backup/1790910000000000000-0000000000000000
+  size: 8
+  created_at: "2026-10-02T03:00:00Z"
+  last_modified: "LAST_MODIFIED"
+  pinned: false
+  labels: {}
+  parts:
+  - db
+`))
+		})
+
+		It("prints csv", func() {
+			output, err := listWith("--output", "csv").Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(matchOutput(`name,version,path,size,created_at,last_modified,pinned,labels,parts
+my/backup,1790823600000000000-0000000000000000,my/backup/1790823600000000000-0000000000000000,9,2026-10-01T03:00:00Z,LAST_MODIFIED,true,kind=nightly,
+my/backup,1790910000000000000-0000000000000000,my/backup/1790910000000000000-0000000000000000,8,2026-10-02T03:00:00Z,LAST_MODIFIED,false,,db
+`))
+		})
+
+		It("prints tsv", func() {
+			output, err := listWith("--output", "tsv").Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(matchOutput("name\tversion\tpath\tsize\tcreated_at\tlast_modified\tpinned\tlabels\tparts\n" +
+				"my/backup\t1790823600000000000-0000000000000000\tmy/backup/1790823600000000000-0000000000000000\t9\t2026-10-01T03:00:00Z\tLAST_MODIFIED\ttrue\tkind=nightly\t\n" +
+				"my/backup\t1790910000000000000-0000000000000000\tmy/backup/1790910000000000000-0000000000000000\t8\t2026-10-02T03:00:00Z\tLAST_MODIFIED\tfalse\t\tdb\n"))
+		})
+
+		It("prints each version with a template", func() {
+			output, err := listWith("--template", "{{.Version}} {{.Size}} {{.Pinned}} {{.Labels.kind}}").Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(Equal("1790823600000000000-0000000000000000 9 true nightly\n" +
+				"1790910000000000000-0000000000000000 8 false \n"))
+		})
+
+		It("prints empty lists when there are no versions", func() {
+			output, err := listWith("--output", "json", "--label", "kind=weekly").Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(Equal("[]\n"))
+
+			output, err = listWith("--output", "csv", "--label", "kind=weekly").Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(Equal("name,version,path,size,created_at,last_modified,pinned,labels,parts\n"))
+		})
+
+		It("fails for unknown formats", func() {
+			output, err := listWith("--output", "xml").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(MatchRegexp("Invalid output format 'xml'. It must be text, json, yaml, csv or tsv"))
+		})
+	})
+
 	Context("when there aren't remote versions", func() {
 		It("prints a no versions stored message", func() {
 			output, err := listCmd.CombinedOutput()
@@ -0,0 +1,116 @@
+package integration_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > migrate", func() {
+
+	const (
+		accessKey string = "my_id"
+		secretKey string = "my_secret"
+	)
+
+	var fromBucket *s3.Bucket
+	var toBucket *s3.Bucket
+	var configDir string
+	var configPath string
+
+	migrateCmd := func(args ...string) *exec.Cmd {
+		args = append([]string{"migrate", "--config", configPath, "--from", "old", "--to", "new"}, args...)
+		return exec.Command(cli, args...)
+	}
+
+	storedKeys := func(bucket *s3.Bucket) []string {
+		resp, err := bucket.List("", "", "", 100)
+		Expect(err).ToNot(HaveOccurred())
+
+		keys := []string{}
+		for _, key := range resp.Contents {
+			keys = append(keys, key.Key)
+		}
+		return keys
+	}
+
+	BeforeEach(func() {
+		fromBucketName := fmt.Sprintf("bucket%d", rand.Int())
+		fromBucket = s3Bucket(accessKey, secretKey, fromBucketName)
+		fromBucket.PutBucket("")
+
+		toBucketName := fmt.Sprintf("bucket%d", rand.Int())
+		toBucket = s3Bucket(accessKey, secretKey, toBucketName)
+		toBucket.PutBucket("")
+
+		var err error
+		configDir, err = ioutil.TempDir("", "s3kup-migrate")
+		Expect(err).ToNot(HaveOccurred())
+
+		configPath = filepath.Join(configDir, "config.yaml")
+		config := fmt.Sprintf(`
+endpoint-url: %s
+access-key: %s
+secret-key: %s
+profiles:
+  old:
+    bucket-name: %s
+    file-name: pg/prod
+  new:
+    bucket-name: %s
+`, s3EndpointURL, accessKey, secretKey, fromBucketName, toBucketName)
+		Expect(ioutil.WriteFile(configPath, []byte(config), 0600)).To(Succeed())
+
+		fromBucket.Put("pg/prod/10000001", []byte("first dump"), "", "")
+		fromBucket.Put("pg/prod/10000002", []byte("second dump"), "", "")
+		fromBucket.Put("pg/prod/.s3kup/pins/10000001", []byte{}, "", "")
+		fromBucket.Put("pg/dev/10000003", []byte("dev dump"), "", "")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(configDir)
+	})
+
+	It("copies every version of the backup of the source profile, keeping the version IDs", func() {
+		output, err := migrateCmd().CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("pg/prod: 2 versions, 2 copied (21B), 0 already migrated, 0 failed"))
+
+		Expect(storedKeys(toBucket)).To(ConsistOf("pg/prod/10000001", "pg/prod/10000002", "pg/prod/.s3kup/pins/10000001"))
+		content, err := toBucket.Get("pg/prod/10000002")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("second dump"))
+	})
+
+	It("skips the versions that were already migrated", func() {
+		output, err := migrateCmd().CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		output, err = migrateCmd().CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("pg/prod: 2 versions, 0 copied (0B), 2 already migrated, 0 failed"))
+	})
+
+	It("migrates the given backups, or all of them", func() {
+		output, err := migrateCmd("pg/dev").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(storedKeys(toBucket)).To(ConsistOf("pg/dev/10000003"))
+
+		output, err = migrateCmd("--all").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(storedKeys(toBucket)).To(HaveLen(4))
+	})
+
+	It("refuses to migrate a bucket into itself", func() {
+		output, err := exec.Command(cli, "migrate", "--config", configPath, "--from", "old", "--to", "old").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(ContainSubstring("The profiles 'old' and 'old' point to the same bucket"))
+	})
+})
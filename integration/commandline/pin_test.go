@@ -0,0 +1,104 @@
+package integration_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > pin", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	cliCmd := func(args ...string) *exec.Cmd {
+		args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+		return exec.Command(cli, args...)
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		bucket.Put("my/backup/10000001", []byte("content 1"), "", "")
+		bucket.Put("my/backup/10000002", []byte("content 2"), "", "")
+		bucket.Put("my/backup/10000003", []byte("content 3"), "", "")
+	})
+
+	It("marks the version as pinned", func() {
+		output, err := cliCmd("pin", "10000001").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		output, err = cliCmd("list").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("\\* 10000001.*\tpinned"))
+		Expect(string(output)).ToNot(MatchRegexp("\\* 10000002.*\tpinned"))
+	})
+
+	It("keeps pinned versions out of the retention", func() {
+		output, err := cliCmd("pin", "10000001").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		pushCmd := cliCmd("push", "-k", "2")
+		_, err = runPipedCmdsAndReturnLastOutput(exec.Command("echo", "'store my data'"), pushCmd)
+		Expect(err).ToNot(HaveOccurred())
+
+		output, err = cliCmd("list").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("\\* 10000001"))
+		Expect(string(output)).ToNot(MatchRegexp("\\* 10000002"))
+		Expect(string(output)).To(MatchRegexp("\\* 10000003"))
+	})
+
+	It("accepts version selectors", func() {
+		output, err := cliCmd("pin", "@-1").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		output, err = cliCmd("pin", "--oldest").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		output, err = cliCmd("list").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("\\* 10000001.*\tpinned"))
+		Expect(string(output)).To(MatchRegexp("\\* 10000002.*\tpinned"))
+		Expect(string(output)).ToNot(MatchRegexp("\\* 10000003.*\tpinned"))
+	})
+
+	It("fails if the version doesn't exist", func() {
+		output, err := cliCmd("pin", "19999999").CombinedOutput()
+		Expect(err).To(HaveOccurred())
+		Expect(string(output)).To(MatchRegexp("Could not find version '19999999'"))
+	})
+
+	Describe("unpin", func() {
+		It("removes the pin from the version", func() {
+			output, err := cliCmd("pin", "10000001").CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+
+			output, err = cliCmd("unpin", "10000001").CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+
+			output, err = cliCmd("list").CombinedOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).ToNot(MatchRegexp("pinned"))
+		})
+
+		It("fails if the version is not pinned", func() {
+			output, err := cliCmd("unpin", "10000002").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(MatchRegexp("Version '10000002' is not pinned"))
+		})
+	})
+})
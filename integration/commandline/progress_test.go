@@ -0,0 +1,78 @@
+package integration_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > progress", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+	var content []byte
+	var tmpDir string
+
+	cliCmd := func(args ...string) *exec.Cmd {
+		args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+		return exec.Command(cli, args...)
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		content = bytes.Repeat([]byte("0123456789abcdef"), 256*1024)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "s3kup-progress")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("logs the progress of a push when stderr is not a terminal", func() {
+		cmd := cliCmd("push", "--progress-interval", "1ms")
+		cmd.Stdin = bytes.NewReader(content)
+
+		output, err := cmd.CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(MatchRegexp(`\[PROGRESS\].* push my/backup: .* in flight`))
+		Expect(string(output)).To(MatchRegexp(`\[PROGRESS\].* push my/backup: 4M of 4M \(100%\) in \d+s, `))
+	})
+
+	It("logs the progress of a pull to a file", func() {
+		bucket.Put("my/backup/10000001", content, "", "")
+		output := filepath.Join(tmpDir, "dump")
+
+		out, err := cliCmd("pull", "--output", output, "--progress-interval", "1ms").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(out))
+		Expect(string(out)).To(MatchRegexp(`\[PROGRESS\].* pull my/backup: 4M of 4M \(100%\) in \d+s, `))
+	})
+
+	It("reports nothing with --no-progress", func() {
+		cmd := cliCmd("push", "--progress-interval", "1ms", "--no-progress")
+		cmd.Stdin = bytes.NewReader(content)
+
+		output, err := cmd.CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).ToNot(ContainSubstring("[PROGRESS]"))
+	})
+})
@@ -1,13 +1,19 @@
 package integration_test
 
 import (
+	"crypto/md5"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"time"
 
 	"github.com/mitchellh/goamz/s3"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/tscolari/s3kup/download"
 )
 
 var _ = Describe("Cli > pull", func() {
@@ -83,4 +89,135 @@ var _ = Describe("Cli > pull", func() {
 			})
 		})
 	})
+
+	Context("when an output file is given", func() {
+		var outputDir string
+
+		cliWith := func(command string, args ...string) *exec.Cmd {
+			args = append([]string{command}, args...)
+			args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+			return exec.Command(cli, args...)
+		}
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = ioutil.TempDir("", "s3kup-pull")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		It("writes the content to the file, with the mode and time it was pushed with", func() {
+			inputPath := filepath.Join(outputDir, "dump.sql")
+			Expect(ioutil.WriteFile(inputPath, []byte("my dump"), 0640)).To(Succeed())
+			modTime := time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)
+			Expect(os.Chtimes(inputPath, modTime, modTime)).To(Succeed())
+
+			output, err := cliWith("push", inputPath).CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+
+			outputPath := filepath.Join(outputDir, "restored.sql")
+			output, err = cliWith("pull", "--output", outputPath).CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(output).To(BeEmpty())
+
+			Expect(ioutil.ReadFile(outputPath)).To(Equal([]byte("my dump")))
+			fi, err := os.Stat(outputPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0640)))
+			Expect(fi.ModTime().Equal(modTime)).To(BeTrue())
+		})
+
+		It("completes an interrupted download", func() {
+			bucket.Put("my/backup/10000001", []byte("content 1"), "", "")
+			outputPath := filepath.Join(outputDir, "restored")
+			etag := fmt.Sprintf("%x", md5.Sum([]byte("content 1")))
+			Expect(ioutil.WriteFile(download.PartialPath(outputPath, "my/backup/10000001", etag), []byte("cont"), 0600)).To(Succeed())
+
+			output, err := cliWith("pull", "--output", outputPath).CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(ioutil.ReadFile(outputPath)).To(Equal([]byte("content 1")))
+
+			files, err := ioutil.ReadDir(outputDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(files)).To(Equal(1))
+		})
+
+		It("leaves no file behind when the pull fails", func() {
+			outputPath := filepath.Join(outputDir, "restored")
+			output, err := cliWith("pull", "--output", outputPath).CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(MatchRegexp("There's no backup named 'my/backup' on this bucket"))
+
+			files, err := ioutil.ReadDir(outputDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(BeEmpty())
+		})
+	})
+
+	Context("when a selector is specified", func() {
+		pullWith := func(args ...string) *exec.Cmd {
+			args = append([]string{"pull"}, args...)
+			args = append(args, "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+			cmd := exec.Command(cli, args...)
+			cmd.Env = append(os.Environ(), "TZ=UTC")
+			return cmd
+		}
+
+		versionAt := func(date string) string {
+			timestamp, err := time.Parse("2006-01-02 15:04", date)
+			Expect(err).ToNot(HaveOccurred())
+			return fmt.Sprintf("my/backup/%019d-0000000000000000", timestamp.UnixNano())
+		}
+
+		BeforeEach(func() {
+			bucket.Put(versionAt("2026-09-30 03:00"), []byte("september"), "", "")
+			bucket.Put(versionAt("2026-10-01 03:00"), []byte("october 1st"), "", "")
+			bucket.Put(versionAt("2026-10-02 03:00"), []byte("october 2nd"), "", "")
+		})
+
+		It("fetches relative to the latest version", func() {
+			output, err := pullWith("@-1").CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(string(output)).To(Equal("october 1st"))
+		})
+
+		It("fetches the latest version before a time", func() {
+			output, err := pullWith("--before", "2026-10-01 03:00").CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(string(output)).To(Equal("september"))
+		})
+
+		It("fetches the latest version of a day", func() {
+			output, err := pullWith("--at", "2026-10-01").CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(string(output)).To(Equal("october 1st"))
+		})
+
+		It("fetches the oldest version", func() {
+			output, err := pullWith("--oldest").CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(string(output)).To(Equal("september"))
+		})
+
+		It("fails when nothing matches", func() {
+			output, err := pullWith("--at", "2026-10-03").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(MatchRegexp("No version matches 'at:2026-10-03'"))
+		})
+
+		It("fails when more than one version is selected", func() {
+			output, err := pullWith("@-1", "--oldest").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(MatchRegexp("You can specify only one version"))
+		})
+
+		It("fails for invalid selectors", func() {
+			output, err := pullWith("newest").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(MatchRegexp("Invalid version 'newest'"))
+		})
+	})
 })
@@ -2,8 +2,11 @@ package integration_test
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/mitchellh/goamz/s3"
 	. "github.com/onsi/ginkgo"
@@ -40,9 +43,9 @@ var _ = Describe("Cli > push", func() {
 
 		Context("when the correct args are given", func() {
 
-			It("exits with failure if no input is given through a pipe", func() {
+			It("exits with failure if no input is given", func() {
 				output, err := backupCmd.CombinedOutput()
-				Expect(output).To(MatchRegexp("not using pipeline"))
+				Expect(string(output)).To(MatchRegexp("The input is empty. Nothing was pushed"))
 				Expect(err).To(HaveOccurred())
 			})
 
@@ -56,6 +59,77 @@ var _ = Describe("Cli > push", func() {
 				Expect(len(resp.Contents)).To(Equal(1))
 			})
 
+			Context("when the input is not piped", func() {
+				var inputDir string
+
+				pushCmd := func(args ...string) *exec.Cmd {
+					args = append([]string{"push", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName}, args...)
+					return exec.Command(cli, args...)
+				}
+
+				pulledContent := func() string {
+					pullCmd := exec.Command(cli, "pull", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName)
+					output, err := pullCmd.CombinedOutput()
+					Expect(err).ToNot(HaveOccurred(), string(output))
+					return string(output)
+				}
+
+				BeforeEach(func() {
+					var err error
+					inputDir, err = ioutil.TempDir("", "s3kup-push")
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(ioutil.WriteFile(filepath.Join(inputDir, "first"), []byte("first file\n"), 0600)).To(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(inputDir, "second"), []byte("second file\n"), 0600)).To(Succeed())
+				})
+
+				AfterEach(func() {
+					os.RemoveAll(inputDir)
+				})
+
+				It("pushes a redirected file", func() {
+					input, err := os.Open(filepath.Join(inputDir, "first"))
+					Expect(err).ToNot(HaveOccurred())
+					defer input.Close()
+
+					cmd := pushCmd()
+					cmd.Stdin = input
+					output, err := cmd.CombinedOutput()
+					Expect(err).ToNot(HaveOccurred(), string(output))
+					Expect(pulledContent()).To(Equal("first file\n"))
+				})
+
+				It("pushes the files given as arguments, concatenated", func() {
+					output, err := pushCmd(filepath.Join(inputDir, "first"), filepath.Join(inputDir, "second")).CombinedOutput()
+					Expect(err).ToNot(HaveOccurred(), string(output))
+					Expect(pulledContent()).To(Equal("first file\nsecond file\n"))
+				})
+
+				It("pushes the file given with --input", func() {
+					output, err := pushCmd("--input", filepath.Join(inputDir, "second")).CombinedOutput()
+					Expect(err).ToNot(HaveOccurred(), string(output))
+					Expect(pulledContent()).To(Equal("second file\n"))
+				})
+
+				It("reads stdin with --input -", func() {
+					_, err := runPipedCmdsAndReturnLastOutput(exec.Command("echo", "piped"), pushCmd("--input", "-"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(pulledContent()).To(Equal("piped\n"))
+				})
+
+				It("fails for directories", func() {
+					output, err := pushCmd(inputDir).CombinedOutput()
+					Expect(err).To(HaveOccurred())
+					Expect(string(output)).To(MatchRegexp("Invalid input '%s'. It is a directory", inputDir))
+				})
+
+				It("fails when the input is given both ways", func() {
+					output, err := pushCmd("--input", "-", filepath.Join(inputDir, "first")).CombinedOutput()
+					Expect(err).To(HaveOccurred())
+					Expect(string(output)).To(MatchRegexp("Give the input either with --input or as arguments, not both"))
+				})
+			})
+
 			It("keeps only the number of versions specified", func() {
 				firstRunInputCmd := exec.Command("echo", "'store my data'")
 				firstRunCmd := exec.Command(cli, "push", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "-k", "2")
@@ -77,6 +151,84 @@ var _ = Describe("Cli > push", func() {
 				Expect(len(resp.Contents)).To(Equal(2))
 			})
 
+			Context("when a key template is given", func() {
+				It("stores the backup following the template, and can list and pull it back", func() {
+					templateArgs := []string{"--key-template", "{{.Name}}/{{.Year}}/{{.Month}}/{{.Day}}/{{.ID}}{{.Ext}}", "--key-extension", ".txt"}
+					backupCmd := exec.Command(cli, append([]string{"push", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName}, templateArgs...)...)
+					_, err := runPipedCmdsAndReturnLastOutput(inputCmd, backupCmd)
+					Expect(err).ToNot(HaveOccurred())
+
+					resp, err := bucket.List(backupName, "", "", 100)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(resp.Contents)).To(Equal(1))
+					Expect(resp.Contents[0].Key).To(MatchRegexp("^my-backup/\\d{4}/\\d{2}/\\d{2}/\\d{19}-[0-9a-f]{16}\\.txt$"))
+
+					listCmd := exec.Command(cli, append([]string{"list", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName}, templateArgs...)...)
+					output, err := listCmd.CombinedOutput()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(output)).To(MatchRegexp("\\* \\d{19}-[0-9a-f]{16}"))
+
+					pullCmd := exec.Command(cli, append([]string{"pull", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName}, templateArgs...)...)
+					output, err = pullCmd.CombinedOutput()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(output)).To(Equal("'store my data'\n"))
+				})
+
+				It("fails if the template is invalid", func() {
+					backupCmd := exec.Command(cli, "push", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "--key-template", "{{.ID}}")
+					output, err := runPipedCmdsAndReturnLastOutput(inputCmd, backupCmd)
+					Expect(err).To(HaveOccurred())
+					Expect(output).To(MatchRegexp("Invalid key template. It must start with '{{.Name}}/'"))
+				})
+			})
+
+			Context("when too many old versions would be deleted", func() {
+				BeforeEach(func() {
+					bucket.Put("my-backup/10000001", []byte("content 1"), "", "")
+					bucket.Put("my-backup/10000002", []byte("content 2"), "", "")
+					bucket.Put("my-backup/10000003", []byte("content 3"), "", "")
+					bucket.Put("my-backup/10000004", []byte("content 4"), "", "")
+				})
+
+				It("refuses to delete them and logs each refused version", func() {
+					backupCmd := exec.Command(cli, "push", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "-k", "1", "--max-deletes", "2")
+					output, err := runPipedCmdsAndReturnLastOutput(inputCmd, backupCmd)
+					Expect(err).To(HaveOccurred())
+
+					Expect(output).To(MatchRegexp("refused to delete: 10000001"))
+					Expect(output).To(MatchRegexp("refused to delete: 10000004"))
+					Expect(output).To(MatchRegexp("Refused to delete 4 old versions, the limit is 2 per run"))
+					Expect(err.(*exec.ExitError).ExitCode()).To(Equal(7))
+
+					resp, err := bucket.List(backupName, "", "", 100)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(resp.Contents)).To(Equal(5))
+				})
+
+				It("refuses to delete them with the default limit", func() {
+					backupCmd := exec.Command(cli, "push", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "-k", "1")
+					output, err := runPipedCmdsAndReturnLastOutput(inputCmd, backupCmd)
+					Expect(err).To(HaveOccurred())
+
+					Expect(output).To(MatchRegexp("Refused to delete 4 old versions, the limit is 2 per run"))
+					Expect(err.(*exec.ExitError).ExitCode()).To(Equal(7))
+
+					resp, err := bucket.List(backupName, "", "", 100)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(resp.Contents)).To(Equal(5))
+				})
+
+				It("deletes them when forced", func() {
+					backupCmd := exec.Command(cli, "push", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "-k", "1", "--max-deletes", "2", "--force")
+					_, err := runPipedCmdsAndReturnLastOutput(inputCmd, backupCmd)
+					Expect(err).ToNot(HaveOccurred())
+
+					resp, err := bucket.List(backupName, "", "", 100)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(resp.Contents)).To(Equal(1))
+				})
+			})
+
 			Context("on verbose mode", func() {
 				It("outputs the steps", func() {
 					backupCmd := exec.Command(cli, "push", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "-k", "3", "--verbose")
@@ -84,7 +236,7 @@ var _ = Describe("Cli > push", func() {
 					Expect(err).ToNot(HaveOccurred())
 
 					Expect(output).To(MatchRegexp("Started backup of my-backup"))
-					Expect(output).To(MatchRegexp(" -- File version: \\d{19}\\]"))
+					Expect(output).To(MatchRegexp(" -- File version: \\d{19}-[0-9a-f]{16}\\]"))
 					Expect(output).To(MatchRegexp(" -- Looking for old versions to delete. keeping 3"))
 				})
 			})
@@ -114,6 +266,14 @@ var _ = Describe("Cli > push", func() {
 
 				Expect(output).To(MatchRegexp("invalid versions to keep. Must be 1 or greater"))
 			})
+
+			It("fails if max deletes is less than one", func() {
+				backupCmd = exec.Command(cli, "push", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "--max-deletes", "0")
+				output, err := runPipedCmdsAndReturnLastOutput(inputCmd, backupCmd)
+				Expect(err).To(HaveOccurred())
+
+				Expect(output).To(MatchRegexp("invalid max deletes. Must be 1 or greater"))
+			})
 		})
 	})
 })
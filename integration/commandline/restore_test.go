@@ -0,0 +1,81 @@
+package integration_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > restore", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	restoreCmd := func(args ...string) *exec.Cmd {
+		args = append([]string{"restore", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "--no-progress"}, args...)
+		return exec.Command(cli, args...)
+	}
+
+	exitCode := func(cmd *exec.Cmd) (int, string) {
+		output, err := cmd.CombinedOutput()
+		Expect(err).To(HaveOccurred())
+
+		exitErr, ok := err.(*exec.ExitError)
+		Expect(ok).To(BeTrue(), err.Error())
+		return exitErr.ExitCode(), string(output)
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		bucket.Put("my/backup/10000001", []byte("old dump"), "", "")
+		bucket.Put("my/backup/10000002", []byte("latest dump"), "", "")
+	})
+
+	It("pipes the latest version into the command", func() {
+		output, err := restoreCmd("--", "cat").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(Equal("latest dump"))
+	})
+
+	It("pipes the selected version into the command", func() {
+		output, err := restoreCmd("10000001", "--", "sh", "-c", "cat; echo ' restored'").Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(Equal("old dump restored\n"))
+	})
+
+	It("exits with 8 and reports the status of the command when it fails", func() {
+		code, output := exitCode(restoreCmd("--", "sh", "-c", "cat > /dev/null; echo 'relation exists' >&2; exit 3"))
+		Expect(code).To(Equal(8))
+		Expect(output).To(ContainSubstring(`s3kup: error class=command-failed exit=8 message="Command 'sh' exited with status 3: relation exists"`))
+	})
+
+	It("exits with 2 when the command is missing", func() {
+		code, output := exitCode(restoreCmd("10000001"))
+		Expect(code).To(Equal(2))
+		Expect(output).To(ContainSubstring("Give the command to restore into"))
+	})
+
+	Context("when the version fails the integrity checks", func() {
+		It("doesn't run the command and exits with 9", func() {
+			bucket.Put("my/backup/10000003", []byte{}, "", "")
+
+			code, output := exitCode(restoreCmd("--", "sh", "-c", "echo started"))
+			Expect(code).To(Equal(9))
+			Expect(output).ToNot(ContainSubstring("started"))
+			Expect(output).To(ContainSubstring(`s3kup: error class=integrity exit=9 message="'my/backup/10000003' is empty. Refusing to restore it"`))
+		})
+	})
+})
@@ -0,0 +1,125 @@
+package integration_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+
+	"github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli > run", func() {
+
+	const (
+		accessKey  string = "my_id"
+		secretKey  string = "my_secret"
+		regionName string = "my_region"
+		backupName string = "my/backup"
+	)
+
+	var bucket *s3.Bucket
+	var bucketName string
+
+	runCmd := func(args ...string) *exec.Cmd {
+		args = append([]string{"run", "-a", accessKey, "-s", secretKey, "-b", bucketName, "-e", s3EndpointURL, "-n", backupName, "-k", "1"}, args...)
+		return exec.Command(cli, args...)
+	}
+
+	storedKeys := func() []string {
+		resp, err := bucket.List("my/backup/", "", "", 10)
+		Expect(err).ToNot(HaveOccurred())
+
+		keys := []string{}
+		for _, key := range resp.Contents {
+			keys = append(keys, key.Key)
+		}
+		return keys
+	}
+
+	BeforeEach(func() {
+		bucketName = fmt.Sprintf("bucket%d", rand.Int())
+		bucket = s3Bucket(accessKey, secretKey, bucketName)
+		bucket.PutBucket("")
+
+		bucket.Put("my/backup/10000001", []byte("good old dump"), "", "")
+	})
+
+	It("pushes the output of the command", func() {
+		output, err := runCmd("--", "echo", "-n", "my dump").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		keys := storedKeys()
+		Expect(keys).To(HaveLen(1))
+		Expect(keys[0]).ToNot(Equal("my/backup/10000001"))
+
+		content, err := bucket.Get(keys[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("my dump"))
+	})
+
+	It("passes the flags after the command to it", func() {
+		output, err := runCmd("sh", "-c", "echo -n $0", "-k").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+
+		keys := storedKeys()
+		content, err := bucket.Get(keys[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("-k"))
+	})
+
+	It("forwards the stderr of the command", func() {
+		output, err := runCmd("--", "sh", "-c", "echo dumping >&2; echo data").CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("dumping\n"))
+	})
+
+	Context("when the command fails", func() {
+		It("pushes nothing and keeps the old versions", func() {
+			output, err := runCmd("--", "sh", "-c", "echo truncated; echo 'connection lost' >&2; exit 1").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring("Command 'sh' exited with status 1: connection lost. Nothing was pushed"))
+
+			Expect(storedKeys()).To(Equal([]string{"my/backup/10000001"}))
+		})
+	})
+
+	Context("when the command fails after a large output", func() {
+		It("pushes nothing and keeps the old versions", func() {
+			output, err := runCmd("--", "sh", "-c", "head -c 70000000 /dev/zero; exit 1").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring("Command 'sh' exited with status 1. Nothing was pushed"))
+
+			Expect(storedKeys()).To(Equal([]string{"my/backup/10000001"}))
+		})
+	})
+
+	Context("when the command is killed", func() {
+		It("pushes nothing", func() {
+			output, err := runCmd("--", "sh", "-c", "echo truncated; kill -9 $$").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring("Command 'sh' was killed by signal 'killed'. Nothing was pushed"))
+
+			Expect(storedKeys()).To(Equal([]string{"my/backup/10000001"}))
+		})
+	})
+
+	Context("when the command times out", func() {
+		It("pushes nothing", func() {
+			output, err := runCmd("--timeout", "200ms", "--", "sh", "-c", "echo truncated; exec sleep 5").CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring("Command 'sh' timed out after 200ms. Nothing was pushed"))
+
+			Expect(storedKeys()).To(Equal([]string{"my/backup/10000001"}))
+		})
+	})
+
+	Context("when no command is given", func() {
+		It("fails", func() {
+			output, err := runCmd().CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring("Give the command to run"))
+		})
+	})
+})
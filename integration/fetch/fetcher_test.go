@@ -62,13 +62,13 @@ var _ = Describe("Fetcher", func() {
 	Describe("#FetchVersion", func() {
 		Context("when the version doesn't exist", func() {
 			It("returns an error", func() {
-				_, err := fetcher.FetchVersion(backupName, 999)
+				_, err := fetcher.FetchVersion(backupName, "999")
 				Expect(err).To(MatchError("Could not find version '999'"))
 			})
 		})
 
 		It("returns the content of the given version", func() {
-			content, err := fetcher.FetchVersion(backupName, 1001)
+			content, err := fetcher.FetchVersion(backupName, "1001")
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(content).To(Equal([]byte("first backup")))
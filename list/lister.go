@@ -1,6 +1,10 @@
 package list
 
-import "github.com/tscolari/s3kup/s3"
+import (
+	"sort"
+
+	"github.com/tscolari/s3kup/s3"
+)
 
 type Lister struct {
 	s3 S3Client
@@ -8,6 +12,7 @@ type Lister struct {
 
 type S3Client interface {
 	List(path string) (versions s3.Versions, err error)
+	ListWithLabels(path string) (versions s3.Versions, err error)
 }
 
 func New(client S3Client) Lister {
@@ -17,6 +22,31 @@ func New(client S3Client) Lister {
 }
 
 func (l Lister) List(path string) (s3.Versions, error) {
-	versions, err := l.s3.List(path)
-	return versions, err
+	return sorted(l.s3.List(path))
+}
+
+func (l Lister) ListWithLabels(path string) (s3.Versions, error) {
+	return sorted(l.s3.ListWithLabels(path))
+}
+
+func (l Lister) ListMatching(path string, selector s3.Labels) (s3.Versions, error) {
+	if len(selector) == 0 {
+		return l.List(path)
+	}
+
+	versions, err := l.ListWithLabels(path)
+	if err != nil {
+		return versions, err
+	}
+
+	return versions.Matching(selector), nil
+}
+
+func sorted(versions s3.Versions, err error) (s3.Versions, error) {
+	if err != nil {
+		return versions, err
+	}
+
+	sort.Sort(versions)
+	return versions, nil
 }
@@ -2,7 +2,6 @@ package list_test
 
 import (
 	"errors"
-	"time"
 
 	"github.com/tscolari/s3kup/list"
 	"github.com/tscolari/s3kup/list/fakes"
@@ -26,6 +25,11 @@ var _ = Describe("Lister", func() {
 		Expect(s3Client.ListArgsForCall(0)).To(Equal("my-backup"))
 	})
 
+	It("doesn't fetch the labels", func() {
+		lister.List("my-backup")
+		Expect(s3Client.ListWithLabelsCallCount()).To(Equal(0))
+	})
+
 	It("forwards the error if s3 client fails", func() {
 		s3Client.ListReturns(nil, errors.New("failed here"))
 
@@ -34,17 +38,17 @@ var _ = Describe("Lister", func() {
 	})
 
 	Context("formating", func() {
-		var versionOne int64
-		var versionTwo int64
+		var versionOne string
+		var versionTwo string
 
 		BeforeEach(func() {
-			versionOne = time.Now().UnixNano()
-			versionTwo = time.Now().UnixNano()
+			versionOne = "1427554100187348642"
+			versionTwo = "1427571015905296950-9c1f0a2b5e3d7f10"
 
 			s3Client.ListStub = func(path string) (s3.Versions, error) {
 				return s3.Versions{
-					s3.Version{BackupName: path, Version: versionOne},
 					s3.Version{BackupName: path, Version: versionTwo},
+					s3.Version{BackupName: path, Version: versionOne},
 				}, nil
 			}
 		})
@@ -55,7 +59,7 @@ var _ = Describe("Lister", func() {
 			Expect(len(versions)).To(Equal(2))
 		})
 
-		It("returns the remote versions numbers", func() {
+		It("returns the remote versions, sorted", func() {
 			versions, err := lister.List("my-backup")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(versions).To(Equal(s3.Versions{
@@ -64,5 +68,43 @@ var _ = Describe("Lister", func() {
 			}))
 		})
 
+		It("returns only the versions matching the labels", func() {
+			s3Client.ListWithLabelsStub = func(path string) (s3.Versions, error) {
+				return s3.Versions{
+					s3.Version{BackupName: path, Version: versionTwo, Labels: s3.Labels{"kind": "nightly"}},
+					s3.Version{BackupName: path, Version: versionOne, Labels: s3.Labels{"kind": "pre-deploy"}},
+				}, nil
+			}
+
+			versions, err := lister.ListMatching("my-backup", s3.Labels{"kind": "nightly"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(Equal(s3.Versions{
+				s3.Version{BackupName: "my-backup", Version: versionTwo, Labels: s3.Labels{"kind": "nightly"}},
+			}))
+		})
+
+		It("returns the remote versions with their labels, sorted", func() {
+			s3Client.ListWithLabelsStub = func(path string) (s3.Versions, error) {
+				return s3.Versions{
+					s3.Version{BackupName: path, Version: versionTwo, Labels: s3.Labels{"kind": "nightly"}},
+					s3.Version{BackupName: path, Version: versionOne},
+				}, nil
+			}
+
+			versions, err := lister.ListWithLabels("my-backup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(Equal(s3.Versions{
+				s3.Version{BackupName: "my-backup", Version: versionOne},
+				s3.Version{BackupName: "my-backup", Version: versionTwo, Labels: s3.Labels{"kind": "nightly"}},
+			}))
+		})
+
+		It("doesn't fetch the labels without a selector", func() {
+			versions, err := lister.ListMatching("my-backup", s3.Labels{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(HaveLen(2))
+			Expect(s3Client.ListWithLabelsCallCount()).To(Equal(0))
+		})
+
 	})
 })
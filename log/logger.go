@@ -42,3 +42,10 @@ func Fatal(messages ...interface{}) {
 		golog.Fatalln(messages)
 	}
 }
+
+func Error(messages ...interface{}) {
+	if logLevel <= FATAL_LEVEL {
+		golog.SetPrefix("[ERROR]")
+		golog.Println(messages...)
+	}
+}
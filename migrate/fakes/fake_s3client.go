@@ -0,0 +1,239 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"io"
+	"sync"
+
+	"github.com/tscolari/s3kup/migrate"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type FakeS3Client struct {
+	ListStub        func(path string) (versions s3.Versions, err error)
+	listMutex       sync.RWMutex
+	listArgsForCall []struct {
+		path string
+	}
+	listReturns struct {
+		result1 s3.Versions
+		result2 error
+	}
+	StatStub        func(path string) (info s3.ObjectInfo, err error)
+	statMutex       sync.RWMutex
+	statArgsForCall []struct {
+		path string
+	}
+	statReturns struct {
+		result1 s3.ObjectInfo
+		result2 error
+	}
+	GetFromStub        func(path string, offset uint64) (body io.ReadCloser, start uint64, err error)
+	getFromMutex       sync.RWMutex
+	getFromArgsForCall []struct {
+		path   string
+		offset uint64
+	}
+	getFromReturns struct {
+		result1 io.ReadCloser
+		result2 uint64
+		result3 error
+	}
+	StoreReaderStub        func(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error)
+	storeReaderMutex       sync.RWMutex
+	storeReaderArgsForCall []struct {
+		path       string
+		reader     io.Reader
+		size       int64
+		labels     s3.Labels
+		attributes s3.Attributes
+	}
+	storeReaderReturns struct {
+		result1 uint64
+		result2 error
+	}
+	VersionPathStub        func(backupName, version string) (string, error)
+	versionPathMutex       sync.RWMutex
+	versionPathArgsForCall []struct {
+		backupName string
+		version    string
+	}
+	versionPathReturns struct {
+		result1 string
+		result2 error
+	}
+}
+
+func (fake *FakeS3Client) List(path string) (versions s3.Versions, err error) {
+	fake.listMutex.Lock()
+	fake.listArgsForCall = append(fake.listArgsForCall, struct {
+		path string
+	}{path})
+	fake.listMutex.Unlock()
+	if fake.ListStub != nil {
+		return fake.ListStub(path)
+	} else {
+		return fake.listReturns.result1, fake.listReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) ListCallCount() int {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return len(fake.listArgsForCall)
+}
+
+func (fake *FakeS3Client) ListArgsForCall(i int) string {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return fake.listArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) ListReturns(result1 s3.Versions, result2 error) {
+	fake.ListStub = nil
+	fake.listReturns = struct {
+		result1 s3.Versions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) Stat(path string) (info s3.ObjectInfo, err error) {
+	fake.statMutex.Lock()
+	fake.statArgsForCall = append(fake.statArgsForCall, struct {
+		path string
+	}{path})
+	fake.statMutex.Unlock()
+	if fake.StatStub != nil {
+		return fake.StatStub(path)
+	} else {
+		return fake.statReturns.result1, fake.statReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) StatCallCount() int {
+	fake.statMutex.RLock()
+	defer fake.statMutex.RUnlock()
+	return len(fake.statArgsForCall)
+}
+
+func (fake *FakeS3Client) StatArgsForCall(i int) string {
+	fake.statMutex.RLock()
+	defer fake.statMutex.RUnlock()
+	return fake.statArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) StatReturns(result1 s3.ObjectInfo, result2 error) {
+	fake.StatStub = nil
+	fake.statReturns = struct {
+		result1 s3.ObjectInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) GetFrom(path string, offset uint64) (body io.ReadCloser, start uint64, err error) {
+	fake.getFromMutex.Lock()
+	fake.getFromArgsForCall = append(fake.getFromArgsForCall, struct {
+		path   string
+		offset uint64
+	}{path, offset})
+	fake.getFromMutex.Unlock()
+	if fake.GetFromStub != nil {
+		return fake.GetFromStub(path, offset)
+	} else {
+		return fake.getFromReturns.result1, fake.getFromReturns.result2, fake.getFromReturns.result3
+	}
+}
+
+func (fake *FakeS3Client) GetFromCallCount() int {
+	fake.getFromMutex.RLock()
+	defer fake.getFromMutex.RUnlock()
+	return len(fake.getFromArgsForCall)
+}
+
+func (fake *FakeS3Client) GetFromArgsForCall(i int) (string, uint64) {
+	fake.getFromMutex.RLock()
+	defer fake.getFromMutex.RUnlock()
+	return fake.getFromArgsForCall[i].path, fake.getFromArgsForCall[i].offset
+}
+
+func (fake *FakeS3Client) GetFromReturns(result1 io.ReadCloser, result2 uint64, result3 error) {
+	fake.GetFromStub = nil
+	fake.getFromReturns = struct {
+		result1 io.ReadCloser
+		result2 uint64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeS3Client) StoreReader(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error) {
+	fake.storeReaderMutex.Lock()
+	fake.storeReaderArgsForCall = append(fake.storeReaderArgsForCall, struct {
+		path       string
+		reader     io.Reader
+		size       int64
+		labels     s3.Labels
+		attributes s3.Attributes
+	}{path, reader, size, labels, attributes})
+	fake.storeReaderMutex.Unlock()
+	if fake.StoreReaderStub != nil {
+		return fake.StoreReaderStub(path, reader, size, labels, attributes)
+	} else {
+		return fake.storeReaderReturns.result1, fake.storeReaderReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) StoreReaderCallCount() int {
+	fake.storeReaderMutex.RLock()
+	defer fake.storeReaderMutex.RUnlock()
+	return len(fake.storeReaderArgsForCall)
+}
+
+func (fake *FakeS3Client) StoreReaderArgsForCall(i int) (string, io.Reader, int64, s3.Labels, s3.Attributes) {
+	fake.storeReaderMutex.RLock()
+	defer fake.storeReaderMutex.RUnlock()
+	return fake.storeReaderArgsForCall[i].path, fake.storeReaderArgsForCall[i].reader, fake.storeReaderArgsForCall[i].size, fake.storeReaderArgsForCall[i].labels, fake.storeReaderArgsForCall[i].attributes
+}
+
+func (fake *FakeS3Client) StoreReaderReturns(result1 uint64, result2 error) {
+	fake.StoreReaderStub = nil
+	fake.storeReaderReturns = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) VersionPath(backupName, version string) (string, error) {
+	fake.versionPathMutex.Lock()
+	fake.versionPathArgsForCall = append(fake.versionPathArgsForCall, struct {
+		backupName string
+		version    string
+	}{backupName, version})
+	fake.versionPathMutex.Unlock()
+	if fake.VersionPathStub != nil {
+		return fake.VersionPathStub(backupName, version)
+	} else {
+		return fake.versionPathReturns.result1, fake.versionPathReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) VersionPathCallCount() int {
+	fake.versionPathMutex.RLock()
+	defer fake.versionPathMutex.RUnlock()
+	return len(fake.versionPathArgsForCall)
+}
+
+func (fake *FakeS3Client) VersionPathArgsForCall(i int) (string, string) {
+	fake.versionPathMutex.RLock()
+	defer fake.versionPathMutex.RUnlock()
+	return fake.versionPathArgsForCall[i].backupName, fake.versionPathArgsForCall[i].version
+}
+
+func (fake *FakeS3Client) VersionPathReturns(result1 string, result2 error) {
+	fake.VersionPathStub = nil
+	fake.versionPathReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+var _ migrate.S3Client = new(FakeS3Client)
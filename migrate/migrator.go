@@ -0,0 +1,299 @@
+package migrate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type Migrator struct {
+	from     S3Client
+	to       S3Client
+	parallel int
+}
+
+type S3Client interface {
+	List(path string) (versions s3.Versions, err error)
+	Stat(path string) (info s3.ObjectInfo, err error)
+	GetFrom(path string, offset uint64) (body io.ReadCloser, start uint64, err error)
+	StoreReader(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error)
+	VersionPath(backupName, version string) (string, error)
+}
+
+type Result struct {
+	Versions int
+	Copied   int
+	Skipped  int
+	Failed   int
+	Bytes    uint64
+}
+
+type ChecksumError struct {
+	Path    string
+	Message string
+}
+
+func (e *ChecksumError) Error() string {
+	return e.Message
+}
+
+func New(from, to S3Client, parallel int) Migrator {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	return Migrator{
+		from:     from,
+		to:       to,
+		parallel: parallel,
+	}
+}
+
+func (m Migrator) Migrate(backupName string) (Result, error) {
+	versions, err := m.from.List(backupName)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(versions) == 0 {
+		message := fmt.Sprintf("There's no backup named '%s' on the source bucket", backupName)
+		return Result{}, &s3.NotFoundError{Message: message}
+	}
+
+	result := Result{Versions: len(versions)}
+	var firstErr error
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	queue := make(chan s3.Version)
+	for i := 0; i < m.parallel && i < len(versions); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for version := range queue {
+				copied, size, err := m.migrateVersion(backupName, version)
+
+				mutex.Lock()
+				switch {
+				case err != nil:
+					log.Warn("Failed to migrate version", version.Version, "of", backupName+":", err)
+					result.Failed++
+					if firstErr == nil {
+						firstErr = err
+					}
+				case copied:
+					result.Copied++
+					result.Bytes += size
+				default:
+					result.Skipped++
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, version := range versions {
+		queue <- version
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return result, fmt.Errorf("Failed to migrate %d of %d versions of '%s'. Run it again to resume: %w", result.Failed, result.Versions, backupName, firstErr)
+	}
+
+	return result, nil
+}
+
+func (m Migrator) migrateVersion(backupName string, version s3.Version) (bool, uint64, error) {
+	versionPath, err := m.to.VersionPath(backupName, version.Version)
+	if err != nil {
+		return false, 0, err
+	}
+
+	paths := [][2]string{}
+	for _, partPath := range version.PartPaths {
+		paths = append(paths, [2]string{partPath, partPath})
+	}
+	paths = append(paths, [2]string{version.Path, versionPath})
+	if version.Pinned {
+		pinPath := s3.PinPath(backupName, version.Version)
+		paths = append(paths, [2]string{pinPath, pinPath})
+	}
+
+	copied := false
+	var size uint64
+	for _, path := range paths {
+		objectCopied, objectSize, err := m.copyObject(path[0], path[1])
+		if err != nil {
+			return false, 0, err
+		}
+
+		copied = copied || objectCopied
+		size += objectSize
+	}
+
+	return copied, size, nil
+}
+
+func (m Migrator) copyObject(fromPath, toPath string) (bool, uint64, error) {
+	source, err := m.from.Stat(fromPath)
+	if err != nil {
+		return false, 0, err
+	}
+
+	checksum := contentChecksum(source)
+	target, err := m.to.Stat(toPath)
+	if err == nil && sameObject(source, target, checksum) {
+		log.Info("Skipping", toPath, "it was already migrated")
+		return false, 0, nil
+	}
+	if err != nil && !s3.IsNotFoundError(err) {
+		return false, 0, err
+	}
+
+	log.Info("Copying", fromPath, "to", toPath)
+	body, _, err := m.from.GetFrom(fromPath, 0)
+	if err != nil {
+		return false, 0, err
+	}
+	defer body.Close()
+
+	content := newCheckedReader(fromPath, body, source)
+	attributes := source.Attributes
+	attributes.Checksum = checksum
+	_, err = m.to.StoreReader(toPath, content, int64(source.Size), source.Labels, attributes)
+	if err != nil {
+		return false, 0, err
+	}
+
+	return true, source.Size, m.verifyTarget(toPath, content)
+}
+
+// verifyTarget compares the copy to what was read from the source, from its
+// ETag when it's a checksum, or by reading the copy back otherwise.
+func (m Migrator) verifyTarget(path string, source *checkedReader) error {
+	target, err := m.to.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if target.Size != source.count {
+		return &ChecksumError{Path: path, Message: fmt.Sprintf("'%s' has %d bytes, but %d were transferred", path, target.Size, source.count)}
+	}
+
+	if _, ok := s3.NewETagHash(target.ETag, target.Size); ok && target.KMSKeyID == "" {
+		if etag := source.upload.ETag(); etag != strings.ToLower(target.ETag) {
+			return &ChecksumError{Path: path, Message: fmt.Sprintf("The checksum of the copy '%s' doesn't match: got '%s', expected '%s'", path, target.ETag, etag)}
+		}
+		return nil
+	}
+
+	body, _, err := m.to.GetFrom(path, 0)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	stored := sha256.New()
+	if _, err := io.Copy(stored, body); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(stored.Sum(nil), source.sha256.Sum(nil)) {
+		return &ChecksumError{Path: path, Message: fmt.Sprintf("The content of the copy '%s' doesn't match the source", path)}
+	}
+
+	return nil
+}
+
+// checkedReader fails at the end of the source when its size or its ETag
+// don't match the content, so the upload reading it is never completed.
+type checkedReader struct {
+	path   string
+	reader io.Reader
+	info   s3.ObjectInfo
+	source *s3.ETagHash
+	upload *s3.ETagHash
+	sha256 hash.Hash
+	count  uint64
+}
+
+func newCheckedReader(path string, reader io.Reader, info s3.ObjectInfo) *checkedReader {
+	r := &checkedReader{
+		path:   path,
+		reader: reader,
+		info:   info,
+		upload: s3.NewUploadETagHash(info.Size),
+		sha256: sha256.New(),
+	}
+	if source, ok := s3.NewETagHash(info.ETag, info.Size); ok && info.KMSKeyID == "" {
+		r.source = source
+	}
+
+	return r
+}
+
+func (r *checkedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += uint64(n)
+	r.upload.Write(p[:n])
+	r.sha256.Write(p[:n])
+	if r.source != nil {
+		r.source.Write(p[:n])
+	}
+
+	if err == io.EOF {
+		if checkErr := r.check(); checkErr != nil {
+			return n, checkErr
+		}
+	}
+
+	return n, err
+}
+
+func (r *checkedReader) check() error {
+	if r.count != r.info.Size {
+		return &ChecksumError{Path: r.path, Message: fmt.Sprintf("'%s' has %d bytes, but %d were transferred", r.path, r.info.Size, r.count)}
+	}
+
+	if r.source == nil {
+		return nil
+	}
+
+	if etag := r.source.ETag(); etag != strings.ToLower(r.info.ETag) {
+		return &ChecksumError{Path: r.path, Message: fmt.Sprintf("The checksum of '%s' doesn't match: got '%s', expected '%s'", r.path, etag, r.info.ETag)}
+	}
+
+	return nil
+}
+
+// contentChecksum is the checksum recorded by an earlier migration, or the
+// ETag when it's a checksum of the content.
+func contentChecksum(info s3.ObjectInfo) string {
+	if info.Attributes.Checksum != "" {
+		return info.Attributes.Checksum
+	}
+
+	if _, ok := s3.NewETagHash(info.ETag, info.Size); ok && info.KMSKeyID == "" {
+		return "md5:" + strings.ToLower(info.ETag)
+	}
+
+	return ""
+}
+
+func sameObject(source, target s3.ObjectInfo, checksum string) bool {
+	return source.Size == target.Size &&
+		checksum != "" &&
+		checksum == contentChecksum(target) &&
+		source.Labels.Equal(target.Labels) &&
+		source.Attributes.Mode == target.Attributes.Mode &&
+		source.Attributes.ModTime.Equal(target.Attributes.ModTime)
+}
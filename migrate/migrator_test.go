@@ -0,0 +1,312 @@
+package migrate_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/tscolari/s3kup/migrate"
+	"github.com/tscolari/s3kup/migrate/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type storedObject struct {
+	content    []byte
+	labels     s3.Labels
+	attributes s3.Attributes
+}
+
+type fakeBucket struct {
+	mutex   sync.Mutex
+	objects map[string]storedObject
+}
+
+func newFakeBucket(client *fakes.FakeS3Client) *fakeBucket {
+	bucket := &fakeBucket{objects: map[string]storedObject{}}
+	client.StatStub = bucket.stat
+	client.GetFromStub = bucket.getFrom
+	client.StoreReaderStub = bucket.storeReader
+	return bucket
+}
+
+func (b *fakeBucket) stat(path string) (s3.ObjectInfo, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	object, ok := b.objects[path]
+	if !ok {
+		return s3.ObjectInfo{}, &s3.NotFoundError{Message: "not found"}
+	}
+
+	sum := md5.Sum(object.content)
+	return s3.ObjectInfo{
+		Path:       path,
+		Size:       uint64(len(object.content)),
+		ETag:       hex.EncodeToString(sum[:]),
+		Labels:     object.labels,
+		Attributes: object.attributes,
+	}, nil
+}
+
+func (b *fakeBucket) getFrom(path string, offset uint64) (io.ReadCloser, uint64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	object, ok := b.objects[path]
+	if !ok {
+		return nil, 0, &s3.NotFoundError{Message: "not found"}
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(object.content[offset:])), offset, nil
+}
+
+func (b *fakeBucket) storeReader(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error) {
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(len(content)), b.store(path, content, labels, attributes)
+}
+
+func (b *fakeBucket) store(path string, content []byte, labels s3.Labels, attributes s3.Attributes) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.objects[path] = storedObject{content: content, labels: labels, attributes: attributes}
+	return nil
+}
+
+func (b *fakeBucket) put(path string, content string) {
+	b.store(path, []byte(content), nil, s3.Attributes{})
+}
+
+var _ = Describe("Migrator", func() {
+	md5Hex := func(content string) string {
+		sum := md5.Sum([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	var migrator migrate.Migrator
+	var from *fakes.FakeS3Client
+	var to *fakes.FakeS3Client
+	var source *fakeBucket
+	var target *fakeBucket
+	var modTime time.Time
+
+	BeforeEach(func() {
+		modTime = time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)
+
+		from = new(fakes.FakeS3Client)
+		source = newFakeBucket(from)
+		source.store("my-backup/1", []byte("first dump"), s3.Labels{"env": "prod"}, s3.Attributes{Mode: 0600, ModTime: modTime})
+		source.put("my-backup/2", `{"format":"s3kup-bundle/1"}`)
+		source.put("my-backup/.s3kup/parts/2/db", "second dump")
+		source.put("my-backup/.s3kup/pins/2", "")
+		from.ListReturns(s3.Versions{
+			{Path: "my-backup/1", BackupName: "my-backup", Version: "1"},
+			{Path: "my-backup/2", BackupName: "my-backup", Version: "2", Pinned: true, PartPaths: []string{"my-backup/.s3kup/parts/2/db"}},
+		}, nil)
+
+		to = new(fakes.FakeS3Client)
+		target = newFakeBucket(to)
+		to.VersionPathStub = func(backupName, version string) (string, error) {
+			return "dumps/" + backupName + "/" + version, nil
+		}
+
+		migrator = migrate.New(from, to, 2)
+	})
+
+	It("copies every version with its parts, pin and metadata, keeping the version IDs", func() {
+		result, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(migrate.Result{Versions: 2, Copied: 2, Bytes: 48}))
+
+		Expect(from.ListArgsForCall(0)).To(Equal("my-backup"))
+		Expect(target.objects).To(HaveLen(4))
+		Expect(target.objects["dumps/my-backup/1"]).To(Equal(storedObject{
+			content:    []byte("first dump"),
+			labels:     s3.Labels{"env": "prod"},
+			attributes: s3.Attributes{Mode: 0600, ModTime: modTime, Checksum: "md5:" + md5Hex("first dump")},
+		}))
+		Expect(string(target.objects["dumps/my-backup/2"].content)).To(Equal(`{"format":"s3kup-bundle/1"}`))
+		Expect(string(target.objects["my-backup/.s3kup/parts/2/db"].content)).To(Equal("second dump"))
+		Expect(target.objects).To(HaveKey("my-backup/.s3kup/pins/2"))
+	})
+
+	It("copies the parts of a bundle before its manifest", func() {
+		_, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+
+		paths := []string{}
+		for i := 0; i < to.StoreReaderCallCount(); i++ {
+			path, _, _, _, _ := to.StoreReaderArgsForCall(i)
+			paths = append(paths, path)
+		}
+		Expect(paths).To(ContainElement("my-backup/.s3kup/parts/2/db"))
+		Expect(indexOf(paths, "my-backup/.s3kup/parts/2/db")).To(BeNumerically("<", indexOf(paths, "dumps/my-backup/2")))
+		Expect(indexOf(paths, "dumps/my-backup/2")).To(BeNumerically("<", indexOf(paths, "my-backup/.s3kup/pins/2")))
+	})
+
+	It("skips the objects that were already migrated", func() {
+		_, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+		stored := to.StoreReaderCallCount()
+
+		result, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(migrate.Result{Versions: 2, Skipped: 2}))
+		Expect(to.StoreReaderCallCount()).To(Equal(stored))
+	})
+
+	It("skips the copies by the checksum recorded with them, whatever their ETag", func() {
+		_, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+		stored := to.StoreReaderCallCount()
+
+		to.StatStub = func(path string) (s3.ObjectInfo, error) {
+			info, err := target.stat(path)
+			info.ETag = "provider-specific-etag"
+			return info, err
+		}
+
+		result, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(migrate.Result{Versions: 2, Skipped: 2}))
+		Expect(to.StoreReaderCallCount()).To(Equal(stored))
+	})
+
+	It("copies the objects whose checksum can't be compared", func() {
+		_, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+
+		from.StatStub = func(path string) (s3.ObjectInfo, error) {
+			info, err := source.stat(path)
+			info.KMSKeyID = "my-key"
+			return info, err
+		}
+
+		result, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Copied).To(Equal(2))
+	})
+
+	It("copies again the objects whose copy differs", func() {
+		target.put("dumps/my-backup/1", "first du")
+		target.store("my-backup/.s3kup/parts/2/db", []byte("second dump"), s3.Labels{"env": "dev"}, s3.Attributes{})
+
+		result, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Copied).To(Equal(2))
+		Expect(string(target.objects["dumps/my-backup/1"].content)).To(Equal("first dump"))
+		Expect(target.objects["my-backup/.s3kup/parts/2/db"].labels).To(BeNil())
+	})
+
+	It("copies the versions in parallel", func() {
+		var mutex sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		to.StoreReaderStub = func(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error) {
+			mutex.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mutex.Unlock()
+
+			time.Sleep(50 * time.Millisecond)
+
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+			return target.storeReader(path, reader, size, labels, attributes)
+		}
+
+		_, err := migrator.Migrate("my-backup")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(maxInFlight).To(Equal(2))
+	})
+
+	Context("when the source doesn't match its checksum", func() {
+		It("doesn't copy it, migrates the other versions and fails", func() {
+			from.GetFromStub = func(path string, offset uint64) (io.ReadCloser, uint64, error) {
+				if path == "my-backup/1" {
+					return ioutil.NopCloser(bytes.NewReader([]byte("first dumb"))), 0, nil
+				}
+				return source.getFrom(path, offset)
+			}
+
+			result, err := migrator.Migrate("my-backup")
+			Expect(err).To(MatchError(HavePrefix("Failed to migrate 1 of 2 versions of 'my-backup'. Run it again to resume: The checksum of 'my-backup/1' doesn't match")))
+			Expect(result).To(Equal(migrate.Result{Versions: 2, Copied: 1, Failed: 1, Bytes: 38}))
+
+			var checksumErr *migrate.ChecksumError
+			Expect(errors.As(err, &checksumErr)).To(BeTrue())
+			Expect(checksumErr.Path).To(Equal("my-backup/1"))
+			Expect(target.objects).ToNot(HaveKey("dumps/my-backup/1"))
+		})
+	})
+
+	Context("when the copy doesn't match the source", func() {
+		It("fails", func() {
+			to.StoreReaderStub = func(path string, reader io.Reader, size int64, labels s3.Labels, attributes s3.Attributes) (uint64, error) {
+				content, err := ioutil.ReadAll(reader)
+				if err != nil {
+					return 0, err
+				}
+				return uint64(len(content)), target.store(path, content[1:], labels, attributes)
+			}
+
+			_, err := migrator.Migrate("my-backup")
+			var checksumErr *migrate.ChecksumError
+			Expect(errors.As(err, &checksumErr)).To(BeTrue())
+		})
+	})
+
+	Context("when the checksum of the copy can't be verified", func() {
+		It("downloads the copy to compare it", func() {
+			to.StatStub = func(path string) (s3.ObjectInfo, error) {
+				info, err := target.stat(path)
+				info.KMSKeyID = "my-key"
+				return info, err
+			}
+
+			_, err := migrator.Migrate("my-backup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(to.GetFromCallCount()).To(Equal(4))
+		})
+	})
+
+	Context("when the backup doesn't exist", func() {
+		It("returns a not found error", func() {
+			from.ListReturns(s3.Versions{}, nil)
+
+			_, err := migrator.Migrate("my-backup")
+			Expect(err).To(MatchError("There's no backup named 'my-backup' on the source bucket"))
+			Expect(s3.IsNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("when listing the source fails", func() {
+		It("forwards the error", func() {
+			from.ListReturns(nil, errors.New("failed to list"))
+
+			_, err := migrator.Migrate("my-backup")
+			Expect(err).To(MatchError("failed to list"))
+		})
+	})
+})
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,65 @@
+package pin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type Pinner struct {
+	s3 S3Client
+}
+
+type S3Client interface {
+	Store(path string, content []byte) error
+	List(path string) (versions s3.Versions, err error)
+	Delete(path string) error
+}
+
+func New(client S3Client) Pinner {
+	return Pinner{
+		s3: client,
+	}
+}
+
+func (p Pinner) Pin(backupName string, version string) error {
+	if _, err := p.findVersion(backupName, version); err != nil {
+		return err
+	}
+
+	log.Info("Pinning version", version, "of", backupName)
+	return p.s3.Store(s3.PinPath(backupName, version), []byte{})
+}
+
+func (p Pinner) Unpin(backupName string, version string) error {
+	storedVersion, err := p.findVersion(backupName, version)
+	if err != nil {
+		return err
+	}
+
+	if !storedVersion.Pinned {
+		message := fmt.Sprintf("Version '%s' is not pinned", version)
+		return errors.New(message)
+	}
+
+	log.Info("Unpinning version", version, "of", backupName)
+	return p.s3.Delete(s3.PinPath(backupName, version))
+}
+
+func (p Pinner) findVersion(backupName string, version string) (s3.Version, error) {
+	versions, err := p.s3.List(backupName)
+	if err != nil {
+		return s3.Version{}, err
+	}
+
+	for _, storedVersion := range versions {
+		if storedVersion.Version == version {
+			return storedVersion, nil
+		}
+	}
+
+	message := fmt.Sprintf("Could not find version '%s'", version)
+	return s3.Version{}, &s3.NotFoundError{Message: message}
+}
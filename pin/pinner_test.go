@@ -0,0 +1,100 @@
+package pin_test
+
+import (
+	"errors"
+
+	"github.com/tscolari/s3kup/pin"
+	"github.com/tscolari/s3kup/pin/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pinner", func() {
+	var pinner pin.Pinner
+	var s3Client *fakes.FakeS3Client
+
+	BeforeEach(func() {
+		s3Client = new(fakes.FakeS3Client)
+		s3Client.ListReturns(s3.Versions{
+			s3.Version{BackupName: "my-backup", Path: "my-backup/1", Version: "1"},
+			s3.Version{BackupName: "my-backup", Path: "my-backup/2", Version: "2", Pinned: true},
+		}, nil)
+
+		pinner = pin.New(s3Client)
+	})
+
+	Describe("#Pin", func() {
+		It("stores a pin marker for the version", func() {
+			err := pinner.Pin("my-backup", "1")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(s3Client.StoreCallCount()).To(Equal(1))
+			path, content := s3Client.StoreArgsForCall(0)
+			Expect(path).To(Equal("my-backup/.s3kup/pins/1"))
+			Expect(content).To(BeEmpty())
+		})
+
+		Context("when the version doesn't exist", func() {
+			It("returns an error", func() {
+				err := pinner.Pin("my-backup", "3")
+				Expect(err).To(MatchError("Could not find version '3'"))
+				Expect(s3.IsNotFoundError(err)).To(BeTrue())
+				Expect(s3Client.StoreCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when listing the versions fails", func() {
+			It("forwards the error", func() {
+				s3Client.ListReturns(nil, errors.New("failed to list"))
+
+				err := pinner.Pin("my-backup", "1")
+				Expect(err).To(MatchError("failed to list"))
+			})
+		})
+
+		Context("when storing the marker fails", func() {
+			It("forwards the error", func() {
+				s3Client.StoreReturns(errors.New("failed to store"))
+
+				err := pinner.Pin("my-backup", "1")
+				Expect(err).To(MatchError("failed to store"))
+			})
+		})
+	})
+
+	Describe("#Unpin", func() {
+		It("deletes the pin marker of the version", func() {
+			err := pinner.Unpin("my-backup", "2")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(s3Client.DeleteCallCount()).To(Equal(1))
+			Expect(s3Client.DeleteArgsForCall(0)).To(Equal("my-backup/.s3kup/pins/2"))
+		})
+
+		Context("when the version is not pinned", func() {
+			It("returns an error", func() {
+				err := pinner.Unpin("my-backup", "1")
+				Expect(err).To(MatchError("Version '1' is not pinned"))
+				Expect(s3Client.DeleteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the version doesn't exist", func() {
+			It("returns an error", func() {
+				err := pinner.Unpin("my-backup", "3")
+				Expect(err).To(MatchError("Could not find version '3'"))
+			})
+		})
+
+		Context("when deleting the marker fails", func() {
+			It("forwards the error", func() {
+				s3Client.DeleteReturns(errors.New("failed to delete"))
+
+				err := pinner.Unpin("my-backup", "2")
+				Expect(err).To(MatchError("failed to delete"))
+			})
+		})
+	})
+})
@@ -0,0 +1,175 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	golog "log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+)
+
+const (
+	DefaultInterval = 30 * time.Second
+	refreshInterval = 200 * time.Millisecond
+)
+
+type Display struct {
+	out         io.Writer
+	label       string
+	interactive bool
+	interval    time.Duration
+	logger      *golog.Logger
+
+	expected      int64
+	transferred   int64
+	inFlight      int32
+	finishedParts int32
+
+	startedAt time.Time
+	logged    bool
+	stop      chan struct{}
+	stopOnce  sync.Once
+	done      chan struct{}
+}
+
+func New(out io.Writer, label string, interactive bool, interval time.Duration) *Display {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &Display{
+		out:         out,
+		label:       label,
+		interactive: interactive,
+		interval:    interval,
+		logger:      golog.New(out, "[PROGRESS]", golog.LstdFlags),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+func (d *Display) Expect(bytes int64) {
+	atomic.AddInt64(&d.expected, bytes)
+}
+
+func (d *Display) Transferred(bytes int64) {
+	atomic.AddInt64(&d.transferred, bytes)
+}
+
+func (d *Display) PartStarted() {
+	atomic.AddInt32(&d.inFlight, 1)
+}
+
+func (d *Display) PartFinished() {
+	atomic.AddInt32(&d.inFlight, -1)
+	atomic.AddInt32(&d.finishedParts, 1)
+}
+
+func (d *Display) Start() {
+	d.startedAt = time.Now()
+	go d.run()
+}
+
+func (d *Display) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+		<-d.done
+
+		summary := d.Summary(time.Since(d.startedAt))
+		if d.interactive {
+			fmt.Fprintf(d.out, "\r%s\x1b[K\n", summary)
+		} else if d.logged {
+			d.logger.Println(summary)
+		}
+	})
+}
+
+func (d *Display) run() {
+	defer close(d.done)
+
+	interval := d.interval
+	if d.interactive {
+		interval = refreshInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			status := d.Status(time.Since(d.startedAt))
+			if d.interactive {
+				fmt.Fprintf(d.out, "\r%s\x1b[K", status)
+			} else {
+				d.logger.Println(status)
+				d.logged = true
+			}
+		}
+	}
+}
+
+func (d *Display) Status(elapsed time.Duration) string {
+	expected, transferred := d.counts()
+	details := []string{d.amount(expected, transferred)}
+
+	rate := rate(transferred, elapsed)
+	details = append(details, bytefmt.ByteSize(rate)+"/s")
+	if expected > transferred && rate > 0 {
+		eta := time.Duration(uint64(expected-transferred)/rate) * time.Second
+		details = append(details, "ETA "+eta.String())
+	}
+
+	inFlight := atomic.LoadInt32(&d.inFlight)
+	finished := atomic.LoadInt32(&d.finishedParts)
+	details = append(details, fmt.Sprintf("%d %s in flight, %d done", inFlight, plural(inFlight, "part", "parts"), finished))
+
+	return d.label + ": " + strings.Join(details, ", ")
+}
+
+func (d *Display) Summary(elapsed time.Duration) string {
+	expected, transferred := d.counts()
+	elapsed = elapsed.Round(time.Second)
+	return fmt.Sprintf("%s: %s in %s, %s/s", d.label, d.amount(expected, transferred), elapsed, bytefmt.ByteSize(rate(transferred, elapsed)))
+}
+
+func (d *Display) counts() (int64, int64) {
+	expected := atomic.LoadInt64(&d.expected)
+	transferred := atomic.LoadInt64(&d.transferred)
+	if transferred < 0 {
+		transferred = 0
+	}
+
+	return expected, transferred
+}
+
+func (d *Display) amount(expected, transferred int64) string {
+	if expected <= 0 {
+		return bytefmt.ByteSize(uint64(transferred))
+	}
+
+	percentage := transferred * 100 / expected
+	return fmt.Sprintf("%s of %s (%d%%)", bytefmt.ByteSize(uint64(transferred)), bytefmt.ByteSize(uint64(expected)), percentage)
+}
+
+func rate(transferred int64, elapsed time.Duration) uint64 {
+	if elapsed < time.Second {
+		elapsed = time.Second
+	}
+
+	return uint64(float64(transferred) / elapsed.Seconds())
+}
+
+func plural(count int32, singular, pluralForm string) string {
+	if count == 1 {
+		return singular
+	}
+
+	return pluralForm
+}
@@ -0,0 +1,115 @@
+package progress_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tscolari/s3kup/progress"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+var _ = Describe("Display", func() {
+	var out *syncBuffer
+	var display *progress.Display
+
+	BeforeEach(func() {
+		out = &syncBuffer{}
+		display = progress.New(out, "push my/backup", false, time.Hour)
+	})
+
+	Describe("#Status", func() {
+		It("shows the bytes, rate, ETA and parts when the size is known", func() {
+			display.Expect(100 * 1024 * 1024)
+			display.PartStarted()
+			display.Transferred(40 * 1024 * 1024)
+			display.PartFinished()
+			display.PartStarted()
+			display.Transferred(8 * 1024 * 1024)
+
+			Expect(display.Status(12 * time.Second)).To(Equal("push my/backup: 48M of 100M (48%), 4M/s, ETA 13s, 1 part in flight, 1 done"))
+		})
+
+		It("leaves out the total and the ETA when the size is unknown", func() {
+			display.PartStarted()
+			display.Transferred(3 * 1024)
+
+			Expect(display.Status(3 * time.Second)).To(Equal("push my/backup: 3K, 1K/s, 1 part in flight, 0 done"))
+		})
+
+		It("goes back when a part is sent again", func() {
+			display.Expect(2048)
+			display.Transferred(2048)
+			display.Transferred(-1024)
+
+			Expect(display.Status(time.Second)).To(HavePrefix("push my/backup: 1K of 2K (50%), "))
+		})
+	})
+
+	Describe("#Summary", func() {
+		It("shows the transferred bytes, the time and the average rate", func() {
+			display.Expect(10 * 1024 * 1024)
+			display.Transferred(10 * 1024 * 1024)
+
+			Expect(display.Summary(5*time.Second + 200*time.Millisecond)).To(Equal("push my/backup: 10M of 10M (100%) in 5s, 2M/s"))
+		})
+	})
+
+	Context("when the output is not a terminal", func() {
+		It("logs the status periodically and a summary at the end", func() {
+			display = progress.New(out, "pull my/backup", false, 50*time.Millisecond)
+			display.Start()
+			display.Expect(1024)
+			display.Transferred(512)
+
+			Eventually(out.String).Should(ContainSubstring("pull my/backup: 512B of 1K (50%)"))
+			display.Stop()
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines[0]).To(MatchRegexp(`^\[PROGRESS\]\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} pull my/backup: `))
+			Expect(lines[len(lines)-1]).To(MatchRegexp(`pull my/backup: 512B of 1K \(50%\) in 0s, 512B/s$`))
+		})
+
+		It("logs nothing when the transfer ends before the first line", func() {
+			display.Start()
+			display.Transferred(512)
+			display.Stop()
+
+			Expect(out.String()).To(BeEmpty())
+		})
+	})
+
+	Context("when the output is a terminal", func() {
+		It("redraws the status line and ends it with the summary", func() {
+			display = progress.New(out, "push my/backup", true, time.Hour)
+			display.Start()
+			display.Expect(1024)
+			display.Transferred(1024)
+
+			Eventually(out.String).Should(ContainSubstring("\rpush my/backup: 1K of 1K (100%)"))
+			display.Stop()
+
+			Expect(out.String()).To(HaveSuffix("\rpush my/backup: 1K of 1K (100%) in 0s, 1K/s\x1b[K\n"))
+		})
+	})
+})
@@ -0,0 +1,5 @@
+package progress
+
+import "syscall"
+
+const getTermios = syscall.TCGETS
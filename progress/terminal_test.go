@@ -0,0 +1,30 @@
+package progress_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/tscolari/s3kup/progress"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IsTerminal", func() {
+	It("is false for character devices that aren't terminals", func() {
+		devNull, err := os.Open(os.DevNull)
+		Expect(err).ToNot(HaveOccurred())
+		defer devNull.Close()
+
+		Expect(progress.IsTerminal(devNull)).To(BeFalse())
+	})
+
+	It("is false for regular files", func() {
+		file, err := ioutil.TempFile("", "terminal")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(file.Name())
+		defer file.Close()
+
+		Expect(progress.IsTerminal(file)).To(BeFalse())
+	})
+})
@@ -0,0 +1,17 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package progress
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// IsTerminal asks the terminal driver for the file's settings, which only
+// succeeds for terminals, unlike other character devices such as /dev/null.
+func IsTerminal(file *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), getTermios, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
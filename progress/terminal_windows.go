@@ -0,0 +1,11 @@
+package progress
+
+import (
+	"os"
+	"syscall"
+)
+
+func IsTerminal(file *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(file.Fd()), &mode) == nil
+}
@@ -0,0 +1,96 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/tscolari/s3kup/remove"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type FakeS3Client struct {
+	InventoryStub        func(path string) (inventory s3.Inventory, err error)
+	inventoryMutex       sync.RWMutex
+	inventoryArgsForCall []struct {
+		path string
+	}
+	inventoryReturns struct {
+		result1 s3.Inventory
+		result2 error
+	}
+	DeleteMultiStub        func(paths []string) error
+	deleteMultiMutex       sync.RWMutex
+	deleteMultiArgsForCall []struct {
+		paths []string
+	}
+	deleteMultiReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeS3Client) Inventory(path string) (inventory s3.Inventory, err error) {
+	fake.inventoryMutex.Lock()
+	fake.inventoryArgsForCall = append(fake.inventoryArgsForCall, struct {
+		path string
+	}{path})
+	fake.inventoryMutex.Unlock()
+	if fake.InventoryStub != nil {
+		return fake.InventoryStub(path)
+	} else {
+		return fake.inventoryReturns.result1, fake.inventoryReturns.result2
+	}
+}
+
+func (fake *FakeS3Client) InventoryCallCount() int {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	return len(fake.inventoryArgsForCall)
+}
+
+func (fake *FakeS3Client) InventoryArgsForCall(i int) string {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	return fake.inventoryArgsForCall[i].path
+}
+
+func (fake *FakeS3Client) InventoryReturns(result1 s3.Inventory, result2 error) {
+	fake.InventoryStub = nil
+	fake.inventoryReturns = struct {
+		result1 s3.Inventory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Client) DeleteMulti(paths []string) error {
+	fake.deleteMultiMutex.Lock()
+	fake.deleteMultiArgsForCall = append(fake.deleteMultiArgsForCall, struct {
+		paths []string
+	}{paths})
+	fake.deleteMultiMutex.Unlock()
+	if fake.DeleteMultiStub != nil {
+		return fake.DeleteMultiStub(paths)
+	} else {
+		return fake.deleteMultiReturns.result1
+	}
+}
+
+func (fake *FakeS3Client) DeleteMultiCallCount() int {
+	fake.deleteMultiMutex.RLock()
+	defer fake.deleteMultiMutex.RUnlock()
+	return len(fake.deleteMultiArgsForCall)
+}
+
+func (fake *FakeS3Client) DeleteMultiArgsForCall(i int) []string {
+	fake.deleteMultiMutex.RLock()
+	defer fake.deleteMultiMutex.RUnlock()
+	return fake.deleteMultiArgsForCall[i].paths
+}
+
+func (fake *FakeS3Client) DeleteMultiReturns(result1 error) {
+	fake.DeleteMultiStub = nil
+	fake.deleteMultiReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ remove.S3Client = new(FakeS3Client)
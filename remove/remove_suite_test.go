@@ -0,0 +1,13 @@
+package remove_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRemove(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Remove Suite")
+}
@@ -0,0 +1,161 @@
+package remove
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+const batchSize = 1000
+
+type Remover struct {
+	s3 S3Client
+}
+
+type S3Client interface {
+	Inventory(path string) (inventory s3.Inventory, err error)
+	DeleteMulti(paths []string) error
+}
+
+type Plan struct {
+	BackupName string
+	Versions   s3.Versions
+	Paths      []string
+}
+
+func New(client S3Client) Remover {
+	return Remover{
+		s3: client,
+	}
+}
+
+func (r Remover) PlanVersions(backupName string, versionIDs []string, olderThan time.Time) (Plan, error) {
+	inventory, err := r.s3.Inventory(backupName)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	selected := map[string]s3.Version{}
+	for _, versionID := range versionIDs {
+		version, found := findVersion(inventory.Versions, versionID)
+		if !found {
+			message := fmt.Sprintf("Could not find version '%s'", versionID)
+			return Plan{}, &s3.NotFoundError{Message: message}
+		}
+
+		if version.Pinned {
+			message := fmt.Sprintf("Version '%s' is pinned. Unpin it before deleting it", versionID)
+			return Plan{}, errors.New(message)
+		}
+
+		selected[version.Path] = version
+	}
+
+	if !olderThan.IsZero() {
+		for _, version := range inventory.Versions {
+			if !createdAt(version).Before(olderThan) {
+				continue
+			}
+
+			if version.Pinned {
+				log.Info(" -- skipping pinned version:", version.Version)
+				continue
+			}
+
+			selected[version.Path] = version
+		}
+	}
+
+	plan := Plan{BackupName: backupName, Versions: s3.Versions{}, Paths: []string{}}
+	for _, version := range selected {
+		plan.Versions = append(plan.Versions, version)
+	}
+	sort.Sort(plan.Versions)
+
+	for _, version := range plan.Versions {
+		plan.Paths = append(plan.Paths, version.Path)
+		plan.Paths = append(plan.Paths, version.PartPaths...)
+	}
+
+	return plan, nil
+}
+
+// PlanAll plans the deletion of every s3kup object of the backup. Pinned
+// versions and their pins are left alone, unless includePinned is set.
+func (r Remover) PlanAll(backupName string, includePinned bool) (Plan, error) {
+	inventory, err := r.s3.Inventory(backupName)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := Plan{BackupName: backupName, Versions: s3.Versions{}, Paths: []string{}}
+	for _, version := range inventory.Versions {
+		if version.Pinned && !includePinned {
+			log.Info(" -- skipping pinned version:", version.Version)
+			continue
+		}
+
+		plan.Versions = append(plan.Versions, version)
+	}
+	sort.Sort(plan.Versions)
+
+	for _, version := range plan.Versions {
+		plan.Paths = append(plan.Paths, version.Path)
+		plan.Paths = append(plan.Paths, version.PartPaths...)
+		if version.Pinned {
+			plan.Paths = append(plan.Paths, s3.PinPath(backupName, version.Version))
+		}
+	}
+
+	for _, objects := range [][]s3.Object{inventory.Staging, inventory.OrphanParts, inventory.OrphanPins} {
+		for _, object := range objects {
+			plan.Paths = append(plan.Paths, object.Path)
+		}
+	}
+
+	for _, object := range inventory.Foreign {
+		log.Warn(" -- leaving unrecognized key:", object.Path)
+	}
+
+	return plan, nil
+}
+
+func (r Remover) Remove(plan Plan) error {
+	log.Info("Deleting", len(plan.Versions), "versions of", plan.BackupName)
+	for start := 0; start < len(plan.Paths); start += batchSize {
+		end := start + batchSize
+		if end > len(plan.Paths) {
+			end = len(plan.Paths)
+		}
+
+		err := r.s3.DeleteMulti(plan.Paths[start:end])
+		if err != nil {
+			return err
+		}
+		log.Info(" -- deleted", end, "of", len(plan.Paths), "objects")
+	}
+
+	return nil
+}
+
+func findVersion(versions s3.Versions, versionID string) (s3.Version, bool) {
+	for _, version := range versions {
+		if version.Version == versionID {
+			return version, true
+		}
+	}
+
+	return s3.Version{}, false
+}
+
+func createdAt(version s3.Version) time.Time {
+	if timestamp := s3.VersionIDTimestamp(version.Version); timestamp > 0 {
+		return time.Unix(0, timestamp)
+	}
+
+	return version.LastModified
+}
@@ -0,0 +1,149 @@
+package remove_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tscolari/s3kup/remove"
+	"github.com/tscolari/s3kup/remove/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Remover", func() {
+	var remover remove.Remover
+	var s3Client *fakes.FakeS3Client
+
+	versionAt := func(date string, pinned bool, partPaths ...string) s3.Version {
+		timestamp, err := time.Parse("2006-01-02", date)
+		Expect(err).ToNot(HaveOccurred())
+
+		id := fmt.Sprintf("%019d-0000000000000000", timestamp.UnixNano())
+		return s3.Version{BackupName: "my-backup", Version: id, Path: "my-backup/" + id, Pinned: pinned, PartPaths: partPaths}
+	}
+
+	september := versionAt("2026-09-30", false, "my-backup/.s3kup/parts/1/db")
+	pinned := versionAt("2026-10-01", true)
+	october := versionAt("2026-10-02", false)
+
+	BeforeEach(func() {
+		s3Client = new(fakes.FakeS3Client)
+		s3Client.InventoryReturns(s3.Inventory{
+			Versions:    s3.Versions{october, september, pinned},
+			Staging:     []s3.Object{{Path: "my-backup/.s3kup/staging/2"}},
+			OrphanParts: []s3.Object{{Path: "my-backup/.s3kup/parts/3/db"}},
+			OrphanPins:  []s3.Object{{Path: "my-backup/.s3kup/pins/4"}},
+			Foreign:     []s3.Object{{Path: "my-backup/README"}},
+		}, nil)
+
+		remover = remove.New(s3Client)
+	})
+
+	Describe("#PlanVersions", func() {
+		It("plans the deletion of the given versions and their parts", func() {
+			plan, err := remover.PlanVersions("my-backup", []string{october.Version, september.Version}, time.Time{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s3Client.InventoryArgsForCall(0)).To(Equal("my-backup"))
+
+			Expect(plan.BackupName).To(Equal("my-backup"))
+			Expect(plan.Versions).To(Equal(s3.Versions{september, october}))
+			Expect(plan.Paths).To(Equal([]string{september.Path, "my-backup/.s3kup/parts/1/db", october.Path}))
+			Expect(s3Client.DeleteMultiCallCount()).To(Equal(0))
+		})
+
+		It("plans the deletion of the unpinned versions older than the given time", func() {
+			plan, err := remover.PlanVersions("my-backup", nil, time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.Versions).To(Equal(s3.Versions{september}))
+		})
+
+		It("doesn't plan a version twice", func() {
+			plan, err := remover.PlanVersions("my-backup", []string{september.Version}, time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.Versions).To(Equal(s3.Versions{september}))
+		})
+
+		It("fails for pinned versions", func() {
+			_, err := remover.PlanVersions("my-backup", []string{pinned.Version}, time.Time{})
+			Expect(err).To(MatchError(fmt.Sprintf("Version '%s' is pinned. Unpin it before deleting it", pinned.Version)))
+		})
+
+		It("fails for unknown versions", func() {
+			_, err := remover.PlanVersions("my-backup", []string{"1"}, time.Time{})
+			Expect(err).To(MatchError("Could not find version '1'"))
+			Expect(s3.IsNotFoundError(err)).To(BeTrue())
+		})
+
+		Context("when listing fails", func() {
+			It("forwards the error", func() {
+				s3Client.InventoryReturns(s3.Inventory{}, errors.New("failed to list"))
+
+				_, err := remover.PlanVersions("my-backup", nil, time.Now())
+				Expect(err).To(MatchError("failed to list"))
+			})
+		})
+	})
+
+	Describe("#PlanAll", func() {
+		It("plans the deletion of every s3kup object of the backup but the pinned versions", func() {
+			plan, err := remover.PlanAll("my-backup", false)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.Versions).To(Equal(s3.Versions{september, october}))
+			Expect(plan.Paths).To(Equal([]string{
+				september.Path,
+				"my-backup/.s3kup/parts/1/db",
+				october.Path,
+				"my-backup/.s3kup/staging/2",
+				"my-backup/.s3kup/parts/3/db",
+				"my-backup/.s3kup/pins/4",
+			}))
+		})
+
+		It("plans the deletion of the pinned versions and their pins when asked to", func() {
+			plan, err := remover.PlanAll("my-backup", true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.Versions).To(Equal(s3.Versions{september, pinned, october}))
+			Expect(plan.Paths).To(Equal([]string{
+				september.Path,
+				"my-backup/.s3kup/parts/1/db",
+				pinned.Path,
+				s3.PinPath("my-backup", pinned.Version),
+				october.Path,
+				"my-backup/.s3kup/staging/2",
+				"my-backup/.s3kup/parts/3/db",
+				"my-backup/.s3kup/pins/4",
+			}))
+		})
+	})
+
+	Describe("#Remove", func() {
+		It("deletes the planned paths in batches of 1000", func() {
+			paths := []string{}
+			for i := 0; i < 2500; i++ {
+				paths = append(paths, fmt.Sprintf("my-backup/%d", i))
+			}
+
+			err := remover.Remove(remove.Plan{BackupName: "my-backup", Paths: paths})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(s3Client.DeleteMultiCallCount()).To(Equal(3))
+			Expect(s3Client.DeleteMultiArgsForCall(0)).To(Equal(paths[:1000]))
+			Expect(s3Client.DeleteMultiArgsForCall(1)).To(Equal(paths[1000:2000]))
+			Expect(s3Client.DeleteMultiArgsForCall(2)).To(Equal(paths[2000:]))
+		})
+
+		Context("when deleting fails", func() {
+			It("forwards the error", func() {
+				s3Client.DeleteMultiReturns(errors.New("failed to delete"))
+
+				err := remover.Remove(remove.Plan{Paths: []string{"my-backup/1"}})
+				Expect(err).To(MatchError("failed to delete"))
+			})
+		})
+	})
+})
@@ -0,0 +1,58 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"io"
+	"sync"
+
+	"github.com/tscolari/s3kup/restore"
+)
+
+type FakeRunner struct {
+	PipeStub        func(command []string, input io.Reader, stdout io.Writer) error
+	pipeMutex       sync.RWMutex
+	pipeArgsForCall []struct {
+		command []string
+		input   io.Reader
+		stdout  io.Writer
+	}
+	pipeReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeRunner) Pipe(command []string, input io.Reader, stdout io.Writer) error {
+	fake.pipeMutex.Lock()
+	fake.pipeArgsForCall = append(fake.pipeArgsForCall, struct {
+		command []string
+		input   io.Reader
+		stdout  io.Writer
+	}{command, input, stdout})
+	fake.pipeMutex.Unlock()
+	if fake.PipeStub != nil {
+		return fake.PipeStub(command, input, stdout)
+	} else {
+		return fake.pipeReturns.result1
+	}
+}
+
+func (fake *FakeRunner) PipeCallCount() int {
+	fake.pipeMutex.RLock()
+	defer fake.pipeMutex.RUnlock()
+	return len(fake.pipeArgsForCall)
+}
+
+func (fake *FakeRunner) PipeArgsForCall(i int) ([]string, io.Reader, io.Writer) {
+	fake.pipeMutex.RLock()
+	defer fake.pipeMutex.RUnlock()
+	return fake.pipeArgsForCall[i].command, fake.pipeArgsForCall[i].input, fake.pipeArgsForCall[i].stdout
+}
+
+func (fake *FakeRunner) PipeReturns(result1 error) {
+	fake.PipeStub = nil
+	fake.pipeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ restore.Runner = new(FakeRunner)
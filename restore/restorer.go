@@ -0,0 +1,122 @@
+package restore
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tscolari/s3kup/download"
+	"github.com/tscolari/s3kup/log"
+	"github.com/tscolari/s3kup/s3"
+)
+
+type Restorer struct {
+	s3      S3Client
+	runner  Runner
+	tempDir string
+}
+
+type S3Client interface {
+	Stat(path string) (info s3.ObjectInfo, err error)
+	GetFromIfMatch(path string, offset uint64, etag string) (body io.ReadCloser, start uint64, err error)
+}
+
+type Runner interface {
+	Pipe(command []string, input io.Reader, stdout io.Writer) error
+}
+
+type IntegrityError struct {
+	Path    string
+	Message string
+}
+
+func (e *IntegrityError) Error() string {
+	return e.Message
+}
+
+// New downloads the versions to restore in a private directory under tempDir,
+// or the system's temp directory when it's empty.
+func New(client S3Client, runner Runner, tempDir string) Restorer {
+	return Restorer{
+		s3:      client,
+		runner:  runner,
+		tempDir: tempDir,
+	}
+}
+
+func (r Restorer) Restore(path string, size uint64, command []string, stdout io.Writer) error {
+	log.Info("Checking", path, "before restoring it")
+	object, err := r.s3.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if object.Size == 0 {
+		return integrityError(path, "'%s' is empty. Refusing to restore it", path)
+	}
+
+	if object.Size != size {
+		return integrityError(path, "'%s' has %d bytes, but %d were expected. Refusing to restore it", path, object.Size, size)
+	}
+
+	if _, ok := s3.NewETagHash(object.ETag, object.Size); !ok || object.KMSKeyID != "" {
+		return integrityError(path, "The checksum of '%s' can't be verified from its ETag '%s'. Refusing to restore it", path, object.ETag)
+	}
+
+	dir, err := ioutil.TempDir(r.tempDir, "s3kup-restore")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	downloadPath := filepath.Join(dir, "version")
+	err = download.New(r.s3).Download(path, downloadPath)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(downloadPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = verify(file, path, object)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Restoring", path, "into", command[0])
+	return r.runner.Pipe(command, file, stdout)
+}
+
+func verify(file *os.File, path string, object s3.ObjectInfo) error {
+	hash, _ := s3.NewETagHash(object.ETag, object.Size)
+	count, err := io.Copy(hash, file)
+	if err != nil {
+		return err
+	}
+
+	if uint64(count) != object.Size {
+		return integrityError(path, "The download of '%s' has %d bytes, but %d were expected", path, count, object.Size)
+	}
+
+	if etag := hash.ETag(); etag != strings.ToLower(object.ETag) {
+		return integrityError(path, "The checksum of '%s' doesn't match: got '%s', expected '%s'", path, etag, object.ETag)
+	}
+
+	log.Info("Verified the checksum of", path)
+	return nil
+}
+
+func integrityError(path string, format string, args ...interface{}) error {
+	return &IntegrityError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
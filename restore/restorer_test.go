@@ -0,0 +1,194 @@
+package restore_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/tscolari/s3kup/restore"
+	"github.com/tscolari/s3kup/restore/fakes"
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Restorer", func() {
+	var restorer restore.Restorer
+	var s3Client *fakes.FakeS3Client
+	var runner *fakes.FakeRunner
+	var stdout *bytes.Buffer
+	var content []byte
+	var piped []byte
+	var tempDir string
+
+	md5Hex := func(content []byte) string {
+		sum := md5.Sum(content)
+		return hex.EncodeToString(sum[:])
+	}
+
+	BeforeEach(func() {
+		content = []byte("my database dump")
+		piped = nil
+		stdout = new(bytes.Buffer)
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "restorer")
+		Expect(err).ToNot(HaveOccurred())
+
+		s3Client = new(fakes.FakeS3Client)
+		s3Client.StatReturns(s3.ObjectInfo{Path: "my-backup/1", Size: uint64(len(content)), ETag: md5Hex(content)}, nil)
+		s3Client.GetFromIfMatchStub = func(path string, offset uint64, etag string) (io.ReadCloser, uint64, error) {
+			return ioutil.NopCloser(bytes.NewReader(content)), 0, nil
+		}
+
+		runner = new(fakes.FakeRunner)
+		runner.PipeStub = func(command []string, input io.Reader, stdout io.Writer) error {
+			var err error
+			piped, err = ioutil.ReadAll(input)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		restorer = restore.New(s3Client, runner, tempDir)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("downloads the version and pipes it into the command", func() {
+		err := restorer.Restore("my-backup/1", uint64(len(content)), []string{"psql", "mydb"}, stdout)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(s3Client.StatArgsForCall(0)).To(Equal("my-backup/1"))
+		path, offset, etag := s3Client.GetFromIfMatchArgsForCall(0)
+		Expect(path).To(Equal("my-backup/1"))
+		Expect(offset).To(BeZero())
+		Expect(etag).To(Equal(md5Hex(content)))
+
+		command, _, commandStdout := runner.PipeArgsForCall(0)
+		Expect(command).To(Equal([]string{"psql", "mydb"}))
+		Expect(commandStdout).To(BeIdenticalTo(stdout))
+		Expect(piped).To(Equal(content))
+	})
+
+	It("keeps the download in a private directory and removes it afterwards", func() {
+		runner.PipeStub = func(command []string, input io.Reader, stdout io.Writer) error {
+			dirs, err := ioutil.ReadDir(tempDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dirs).To(HaveLen(1))
+			Expect(dirs[0].Mode().Perm()).To(Equal(os.FileMode(0700)))
+			return nil
+		}
+
+		err := restorer.Restore("my-backup/1", uint64(len(content)), []string{"psql", "mydb"}, stdout)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runner.PipeCallCount()).To(Equal(1))
+
+		dirs, err := ioutil.ReadDir(tempDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dirs).To(BeEmpty())
+	})
+
+	It("forwards the error of the command", func() {
+		runner.PipeStub = nil
+		runner.PipeReturns(errors.New("Command 'psql' exited with status 3"))
+
+		err := restorer.Restore("my-backup/1", uint64(len(content)), []string{"psql", "mydb"}, stdout)
+		Expect(err).To(MatchError("Command 'psql' exited with status 3"))
+	})
+
+	Context("when the content doesn't match the checksum", func() {
+		It("doesn't start the command", func() {
+			s3Client.GetFromIfMatchStub = func(path string, offset uint64, etag string) (io.ReadCloser, uint64, error) {
+				return ioutil.NopCloser(bytes.NewReader([]byte("my database dumb"))), 0, nil
+			}
+
+			err := restorer.Restore("my-backup/1", uint64(len(content)), []string{"psql", "mydb"}, stdout)
+			Expect(err).To(MatchError("The checksum of 'my-backup/1' doesn't match: got '" + md5Hex([]byte("my database dumb")) + "', expected '" + md5Hex(content) + "'"))
+			Expect(runner.PipeCallCount()).To(BeZero())
+
+			var integrityErr *restore.IntegrityError
+			Expect(errors.As(err, &integrityErr)).To(BeTrue())
+			Expect(integrityErr.Path).To(Equal("my-backup/1"))
+
+			dirs, err := ioutil.ReadDir(tempDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dirs).To(BeEmpty())
+		})
+	})
+
+	Context("when the download ends early", func() {
+		It("doesn't start the command", func() {
+			s3Client.GetFromIfMatchStub = func(path string, offset uint64, etag string) (io.ReadCloser, uint64, error) {
+				return ioutil.NopCloser(bytes.NewReader(content[offset:4])), offset, nil
+			}
+
+			err := restorer.Restore("my-backup/1", uint64(len(content)), []string{"psql", "mydb"}, stdout)
+			Expect(err).To(MatchError(ContainSubstring("the download ended at byte 4 of 16")))
+			Expect(runner.PipeCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the version fails the checks", func() {
+		It("refuses to restore an empty version", func() {
+			s3Client.StatReturns(s3.ObjectInfo{Path: "my-backup/1", ETag: md5Hex(nil)}, nil)
+
+			err := restorer.Restore("my-backup/1", 0, []string{"psql", "mydb"}, stdout)
+			Expect(err).To(MatchError("'my-backup/1' is empty. Refusing to restore it"))
+			Expect(s3Client.GetFromIfMatchCallCount()).To(BeZero())
+			Expect(runner.PipeCallCount()).To(BeZero())
+		})
+
+		It("refuses to restore a version with an unexpected size", func() {
+			err := restorer.Restore("my-backup/1", 1024, []string{"psql", "mydb"}, stdout)
+			Expect(err).To(MatchError("'my-backup/1' has 16 bytes, but 1024 were expected. Refusing to restore it"))
+			Expect(runner.PipeCallCount()).To(BeZero())
+		})
+
+		It("refuses to restore a version whose checksum can't be verified", func() {
+			s3Client.StatReturns(s3.ObjectInfo{Path: "my-backup/1", Size: uint64(len(content)), ETag: md5Hex(content) + "-3"}, nil)
+
+			err := restorer.Restore("my-backup/1", uint64(len(content)), []string{"psql", "mydb"}, stdout)
+			Expect(err).To(MatchError("The checksum of 'my-backup/1' can't be verified from its ETag '" + md5Hex(content) + "-3'. Refusing to restore it"))
+
+			var integrityErr *restore.IntegrityError
+			Expect(errors.As(err, &integrityErr)).To(BeTrue())
+			Expect(runner.PipeCallCount()).To(BeZero())
+		})
+
+		It("refuses to restore a version encrypted with a KMS key", func() {
+			s3Client.StatReturns(s3.ObjectInfo{Path: "my-backup/1", Size: uint64(len(content)), ETag: md5Hex(content), KMSKeyID: "my-key"}, nil)
+
+			err := restorer.Restore("my-backup/1", uint64(len(content)), []string{"psql", "mydb"}, stdout)
+			Expect(err).To(HaveOccurred())
+			Expect(runner.PipeCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the version can't be checked", func() {
+		It("forwards the error", func() {
+			s3Client.StatReturns(s3.ObjectInfo{}, errors.New("failed to stat"))
+
+			err := restorer.Restore("my-backup/1", uint64(len(content)), []string{"psql", "mydb"}, stdout)
+			Expect(err).To(MatchError("failed to stat"))
+			Expect(runner.PipeCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the version can't be downloaded", func() {
+		It("forwards the error", func() {
+			s3Client.GetFromIfMatchStub = nil
+			s3Client.GetFromIfMatchReturns(nil, 0, errors.New("failed to get"))
+
+			err := restorer.Restore("my-backup/1", uint64(len(content)), []string{"psql", "mydb"}, stdout)
+			Expect(err).To(MatchError(ContainSubstring("failed to get")))
+			Expect(runner.PipeCallCount()).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func killProcessGroup(process *os.Process) error {
+	return process.Kill()
+}
@@ -0,0 +1,321 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	stderrTailSize  = 4096
+	killGracePeriod = 5 * time.Second
+)
+
+type CommandError struct {
+	Command    string
+	Message    string
+	ExitStatus int
+}
+
+func (e *CommandError) Error() string {
+	return e.Message
+}
+
+type Runner struct {
+	timeout time.Duration
+	stdin   io.Reader
+	stderr  io.Writer
+	env     []string
+}
+
+type process struct {
+	command []string
+	cmd     *exec.Cmd
+	stderr  *tailWriter
+	exited  chan struct{}
+	waitErr error
+}
+
+func New(timeout time.Duration, stdin io.Reader, stderr io.Writer) Runner {
+	return Runner{
+		timeout: timeout,
+		stdin:   stdin,
+		stderr:  stderr,
+	}
+}
+
+// WithEnv adds variables to the environment the command inherits.
+func (r Runner) WithEnv(env []string) Runner {
+	r.env = env
+	return r
+}
+
+// Run streams the output of the command to consume. When the command fails,
+// consume reads the error instead of the end of the output, so nothing it
+// uploads is complete.
+func (r Runner) Run(command []string, consume func(stdout io.Reader) error) error {
+	if len(command) == 0 {
+		return errors.New("Missing the command to run")
+	}
+
+	pipeReader, pipeWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	p := r.newProcess(command, pipeWriter)
+	p.cmd.Stdin = r.stdin
+
+	err = p.start()
+	pipeWriter.Close()
+	if err != nil {
+		pipeReader.Close()
+		return err
+	}
+
+	finished := make(chan error, 1)
+	go func() {
+		timeout, stop := r.timer()
+		defer stop()
+
+		select {
+		case <-p.exited:
+			finished <- p.result(p.waitErr)
+		case <-timeout:
+			p.kill()
+			finished <- p.error(fmt.Sprintf("Command '%s' timed out after %s", command[0], r.timeout), 0)
+		}
+	}()
+
+	stdout := &output{pipe: pipeReader, finished: finished}
+	err = consume(stdout)
+	pipeReader.Close()
+	if err != nil && !stdout.done {
+		p.kill()
+		return err
+	}
+
+	if waitErr := stdout.wait(); waitErr != nil {
+		return waitErr
+	}
+
+	return err
+}
+
+type output struct {
+	pipe     *os.File
+	finished <-chan error
+	done     bool
+	err      error
+}
+
+func (o *output) Read(b []byte) (int, error) {
+	n, err := o.pipe.Read(b)
+	if err == io.EOF {
+		if waitErr := o.wait(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
+
+func (o *output) wait() error {
+	if !o.done {
+		o.err = <-o.finished
+		o.done = true
+	}
+
+	return o.err
+}
+
+func (r Runner) Pipe(command []string, input io.Reader, stdout io.Writer) error {
+	if len(command) == 0 {
+		return errors.New("Missing the command to run")
+	}
+
+	p := r.newProcess(command, stdout)
+	stdin, err := p.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	err = p.start()
+	if err != nil {
+		return err
+	}
+
+	fed := make(chan error, 1)
+	go func() {
+		fed <- feed(stdin, input)
+	}()
+
+	timeout, stop := r.timer()
+	defer stop()
+
+	var feedErr, waitErr error
+	select {
+	case feedErr = <-fed:
+		var inputErr inputError
+		if errors.As(feedErr, &inputErr) {
+			p.kill()
+			return fmt.Errorf("%w. '%s' was killed before the end of its input", inputErr.err, command[0])
+		}
+
+		select {
+		case <-p.exited:
+			waitErr = p.waitErr
+		case <-timeout:
+			p.kill()
+			return p.error(fmt.Sprintf("Command '%s' timed out after %s", command[0], r.timeout), 0)
+		}
+	case <-p.exited:
+		waitErr = p.waitErr
+		select {
+		case feedErr = <-fed:
+		case <-time.After(killGracePeriod):
+			feedErr = errors.New("the input is still being read")
+		}
+	case <-timeout:
+		p.kill()
+		return p.error(fmt.Sprintf("Command '%s' timed out after %s", command[0], r.timeout), 0)
+	}
+
+	if waitErr == nil && feedErr != nil {
+		return &CommandError{Command: command[0], Message: fmt.Sprintf("Command '%s' exited before reading all of its input", command[0])}
+	}
+
+	return p.result(waitErr)
+}
+
+type inputError struct {
+	err error
+}
+
+func (e inputError) Error() string {
+	return e.err.Error()
+}
+
+func feed(stdin io.WriteCloser, input io.Reader) error {
+	_, err := io.Copy(stdin, readerFunc(func(p []byte) (int, error) {
+		n, err := input.Read(p)
+		if err != nil && err != io.EOF {
+			err = inputError{err: err}
+		}
+		return n, err
+	}))
+	if err != nil {
+		return err
+	}
+
+	return stdin.Close()
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+func (r Runner) newProcess(command []string, stdout io.Writer) *process {
+	p := &process{
+		command: command,
+		cmd:     exec.Command(command[0], command[1:]...),
+		stderr:  &tailWriter{limit: stderrTailSize},
+		exited:  make(chan struct{}),
+	}
+
+	p.cmd.Stdout = stdout
+	p.cmd.Stderr = p.stderr
+	setProcessGroup(p.cmd)
+	if len(r.env) > 0 {
+		p.cmd.Env = append(os.Environ(), r.env...)
+	}
+	if r.stderr != nil {
+		p.cmd.Stderr = io.MultiWriter(r.stderr, p.stderr)
+	}
+
+	return p
+}
+
+func (r Runner) timer() (<-chan time.Time, func()) {
+	if r.timeout <= 0 {
+		return nil, func() {}
+	}
+
+	timer := time.NewTimer(r.timeout)
+	return timer.C, func() { timer.Stop() }
+}
+
+func (p *process) start() error {
+	err := p.cmd.Start()
+	if err != nil {
+		return &CommandError{Command: p.command[0], Message: fmt.Sprintf("Could not start '%s': %s", p.command[0], err)}
+	}
+
+	go func() {
+		p.waitErr = p.cmd.Wait()
+		close(p.exited)
+	}()
+
+	return nil
+}
+
+// kill also kills the children of the command, which would otherwise keep
+// running and holding its output open.
+func (p *process) kill() {
+	killProcessGroup(p.cmd.Process)
+	select {
+	case <-p.exited:
+	case <-time.After(killGracePeriod):
+	}
+}
+
+func (p *process) result(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return &CommandError{Command: p.command[0], Message: fmt.Sprintf("Command '%s' failed: %s", p.command[0], err)}
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return p.error(fmt.Sprintf("Command '%s' was killed by signal '%s'", p.command[0], status.Signal()), 128+int(status.Signal()))
+	}
+
+	return p.error(fmt.Sprintf("Command '%s' exited with status %d", p.command[0], exitErr.ExitCode()), exitErr.ExitCode())
+}
+
+func (p *process) error(message string, exitStatus int) error {
+	if line := p.stderr.lastLine(); line != "" {
+		message += ": " + line
+	}
+
+	return &CommandError{Command: p.command[0], Message: message, ExitStatus: exitStatus}
+}
+
+type tailWriter struct {
+	limit int
+	buf   []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.limit {
+		w.buf = w.buf[len(w.buf)-w.limit:]
+	}
+
+	return len(p), nil
+}
+
+func (w *tailWriter) lastLine() string {
+	lines := strings.Split(strings.TrimSpace(string(w.buf)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,189 @@
+package runner_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing/iotest"
+	"time"
+
+	"github.com/tscolari/s3kup/runner"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Runner", func() {
+	var stderr *bytes.Buffer
+	var r runner.Runner
+	var tmpDir string
+
+	BeforeEach(func() {
+		stderr = new(bytes.Buffer)
+		r = runner.New(0, strings.NewReader("from stdin"), stderr)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "runner")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	var streamed string
+
+	collect := func(output io.Reader) error {
+		content, err := ioutil.ReadAll(output)
+		streamed = string(content)
+		return err
+	}
+
+	BeforeEach(func() {
+		streamed = ""
+	})
+
+	It("streams the output of the command", func() {
+		err := r.Run([]string{"sh", "-c", "echo my data; cat"}, collect)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(streamed).To(Equal("my data\nfrom stdin"))
+	})
+
+	It("forwards the stderr of the command", func() {
+		err := r.Run([]string{"sh", "-c", "echo progress >&2"}, collect)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stderr.String()).To(Equal("progress\n"))
+	})
+
+	Context("when the command exits with an error", func() {
+		It("returns an error with the status and the last line of stderr", func() {
+			err := r.Run([]string{"sh", "-c", "echo partial; echo starting >&2; echo connection lost >&2; exit 3"}, collect)
+			Expect(err).To(MatchError("Command 'sh' exited with status 3: connection lost"))
+
+			var commandErr *runner.CommandError
+			Expect(errors.As(err, &commandErr)).To(BeTrue())
+			Expect(commandErr.Command).To(Equal("sh"))
+		})
+
+		It("gives the error to the consumer instead of the end of the output", func() {
+			var readErr error
+			err := r.Run([]string{"sh", "-c", "echo partial; exit 3"}, func(output io.Reader) error {
+				content, err := ioutil.ReadAll(output)
+				streamed, readErr = string(content), err
+				return err
+			})
+			Expect(err).To(MatchError("Command 'sh' exited with status 3"))
+			Expect(readErr).To(MatchError("Command 'sh' exited with status 3"))
+			Expect(streamed).To(Equal("partial\n"))
+		})
+	})
+
+	Context("when the command is killed", func() {
+		It("returns an error with the signal", func() {
+			err := r.Run([]string{"sh", "-c", "kill -9 $$"}, collect)
+			Expect(err).To(MatchError("Command 'sh' was killed by signal 'killed'"))
+		})
+	})
+
+	Context("when the command times out", func() {
+		It("kills it and returns an error", func() {
+			r = runner.New(100*time.Millisecond, nil, stderr)
+
+			start := time.Now()
+			err := r.Run([]string{"sleep", "5"}, collect)
+			Expect(err).To(MatchError("Command 'sleep' timed out after 100ms"))
+			Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+		})
+
+		It("doesn't wait for the children that keep the output open", func() {
+			r = runner.New(100*time.Millisecond, nil, stderr)
+
+			start := time.Now()
+			err := r.Run([]string{"sh", "-c", "sleep 30 | cat"}, collect)
+			Expect(err).To(MatchError("Command 'sh' timed out after 100ms"))
+			Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+		})
+
+		It("kills the children of the command too", func() {
+			r = runner.New(100*time.Millisecond, nil, stderr)
+			marker := filepath.Join(tmpDir, "marker")
+
+			err := r.Run([]string{"sh", "-c", "(sleep 1; touch " + marker + ") > /dev/null & wait"}, collect)
+			Expect(err).To(MatchError("Command 'sh' timed out after 100ms"))
+
+			time.Sleep(1500 * time.Millisecond)
+			Expect(marker).ToNot(BeAnExistingFile())
+		})
+	})
+
+	Context("when the consumer fails", func() {
+		It("kills the command and returns the error of the consumer", func() {
+			start := time.Now()
+			err := r.Run([]string{"sh", "-c", "echo partial; exec sleep 30"}, func(output io.Reader) error {
+				return errors.New("upload failed")
+			})
+			Expect(err).To(MatchError("upload failed"))
+			Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+		})
+	})
+
+	Context("when the command can't be started", func() {
+		It("returns an error", func() {
+			err := r.Run([]string{"s3kup-missing-command"}, collect)
+			Expect(err).To(MatchError(HavePrefix("Could not start 's3kup-missing-command'")))
+		})
+	})
+
+	Context("when no command is given", func() {
+		It("returns an error", func() {
+			err := r.Run([]string{}, collect)
+			Expect(err).To(MatchError("Missing the command to run"))
+		})
+	})
+
+	Describe("#Pipe", func() {
+		var stdout *bytes.Buffer
+
+		BeforeEach(func() {
+			stdout = new(bytes.Buffer)
+		})
+
+		It("feeds the input to the command", func() {
+			err := r.Pipe([]string{"sh", "-c", "cat; echo done"}, strings.NewReader("my data\n"), stdout)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal("my data\ndone\n"))
+		})
+
+		It("kills the command when the input can't be read", func() {
+			input := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset")))
+			err := r.Pipe([]string{"sh", "-c", "while read line; do :; done; echo committed"}, input, stdout)
+			Expect(err).To(MatchError("connection reset. 'sh' was killed before the end of its input"))
+			Expect(stdout.String()).To(BeEmpty())
+		})
+
+		It("returns the exit status of the command", func() {
+			err := r.Pipe([]string{"sh", "-c", "cat > /dev/null; echo failed >&2; exit 3"}, strings.NewReader("my data"), stdout)
+			Expect(err).To(MatchError("Command 'sh' exited with status 3: failed"))
+
+			var commandErr *runner.CommandError
+			Expect(errors.As(err, &commandErr)).To(BeTrue())
+			Expect(commandErr.ExitStatus).To(Equal(3))
+		})
+
+		It("fails when the command exits before reading all of its input", func() {
+			input := bytes.NewReader(make([]byte, 1024*1024))
+			err := r.Pipe([]string{"true"}, input, stdout)
+			Expect(err).To(MatchError("Command 'true' exited before reading all of its input"))
+		})
+
+		It("kills the command when it times out", func() {
+			r = runner.New(100*time.Millisecond, nil, stderr)
+			err := r.Pipe([]string{"sh", "-c", "cat > /dev/null; exec sleep 5"}, strings.NewReader("my data"), stdout)
+			Expect(err).To(MatchError("Command 'sh' timed out after 100ms"))
+		})
+	})
+})
@@ -0,0 +1,64 @@
+package s3
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	modeMeta     = "S3kup-Mode"
+	modTimeMeta  = "S3kup-Mtime"
+	checksumMeta = "S3kup-Checksum"
+)
+
+// Checksum is a checksum of the content that was verified when the object was
+// copied, kept so later copies can be compared without relying on ETags.
+type Attributes struct {
+	Mode     os.FileMode
+	ModTime  time.Time
+	Checksum string
+}
+
+func FileAttributes(fi os.FileInfo) Attributes {
+	return Attributes{
+		Mode:    fi.Mode().Perm(),
+		ModTime: fi.ModTime(),
+	}
+}
+
+func (a Attributes) IsZero() bool {
+	return a.Mode == 0 && a.ModTime.IsZero()
+}
+
+func (a Attributes) meta() map[string][]string {
+	meta := map[string][]string{}
+	if a.Mode != 0 {
+		meta[modeMeta] = []string{strconv.FormatUint(uint64(a.Mode.Perm()), 8)}
+	}
+
+	if !a.ModTime.IsZero() {
+		meta[modTimeMeta] = []string{a.ModTime.UTC().Format(time.RFC3339Nano)}
+	}
+
+	if a.Checksum != "" {
+		meta[checksumMeta] = []string{a.Checksum}
+	}
+
+	return meta
+}
+
+func decodeAttributes(header http.Header) Attributes {
+	attributes := Attributes{}
+	if mode, err := strconv.ParseUint(header.Get(metaHeaderPrefix+modeMeta), 8, 32); err == nil {
+		attributes.Mode = os.FileMode(mode).Perm()
+	}
+
+	if modTime, err := time.Parse(time.RFC3339Nano, header.Get(metaHeaderPrefix+modTimeMeta)); err == nil {
+		attributes.ModTime = modTime
+	}
+
+	attributes.Checksum = header.Get(metaHeaderPrefix + checksumMeta)
+	return attributes
+}
@@ -1,16 +1,53 @@
 package s3
 
 import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/mitchellh/goamz/aws"
 	goamzs3 "github.com/mitchellh/goamz/s3"
+	"github.com/tscolari/s3kup/log"
+)
+
+const (
+	ServerSideEncryption  = "AES256"
+	endpointTimeout       = 10 * time.Second
+	responseHeaderTimeout = time.Minute
 )
 
+// httpClient gives up on requests s3 doesn't answer, without limiting how
+// long a body takes to stream.
+var httpClient = newHTTPClient(responseHeaderTimeout)
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	return &http.Client{Transport: transport}
+}
+
 type Client struct {
-	s3     *goamzs3.S3
-	bucket *goamzs3.Bucket
+	s3          *goamzs3.S3
+	bucket      *goamzs3.Bucket
+	keyTemplate KeyTemplate
+	encrypt     bool
+	progress    Progress
 }
 
 func New(accessKey, secretKey, bucketName, endPointURL string) *Client {
+	return NewWithKeyTemplate(accessKey, secretKey, bucketName, endPointURL, KeyTemplate{})
+}
+
+func NewWithKeyTemplate(accessKey, secretKey, bucketName, endPointURL string, keyTemplate KeyTemplate) *Client {
 	auth := aws.Auth{
 		AccessKey: accessKey,
 		SecretKey: secretKey,
@@ -22,8 +59,10 @@ func New(accessKey, secretKey, bucketName, endPointURL string) *Client {
 	bucket := s3.Bucket(bucketName)
 
 	return &Client{
-		s3:     s3,
-		bucket: bucket,
+		s3:          s3,
+		bucket:      bucket,
+		keyTemplate: keyTemplate,
+		progress:    noProgress{},
 	}
 }
 
@@ -41,32 +80,530 @@ func getRegion(endPointURL string) aws.Region {
 	}
 }
 
+func (c *Client) EnableServerSideEncryption() {
+	c.encrypt = true
+}
+
+func (c *Client) SetProgress(progress Progress) {
+	c.progress = progress
+}
+
 func (c *Client) Store(path string, fileContent []byte) error {
-	return c.bucket.Put(path, fileContent, "", "")
+	if c.encrypt {
+		return c.putHeader(path, fileContent, c.encryptionHeaders())
+	}
+
+	c.startUpload(fileContent)
+	defer c.progress.PartFinished()
+	return c.bucket.PutReader(path, c.uploadReader(fileContent), int64(len(fileContent)), "", "")
+}
+
+func (c *Client) StoreWithLabels(path string, fileContent []byte, labels Labels) error {
+	return c.StoreWithAttributes(path, fileContent, labels, Attributes{})
+}
+
+func (c *Client) StoreWithAttributes(path string, fileContent []byte, labels Labels, attributes Attributes) error {
+	_, err := c.StoreReader(path, bytes.NewReader(fileContent), int64(len(fileContent)), labels, attributes)
+	return err
+}
+
+// StoreReader uploads the content as it is read, holding one part at a time in
+// memory. The size is -1 when it isn't known ahead.
+func (c *Client) StoreReader(path string, reader io.Reader, size int64, labels Labels, attributes Attributes) (uint64, error) {
+	meta := attributes.meta()
+	if len(labels) > 0 {
+		meta[labelsMeta] = []string{labels.encode()}
+	}
+
+	head := &bytes.Buffer{}
+	_, err := io.CopyN(head, reader, int64(MultipartThreshold)+1)
+	if err == io.EOF {
+		return uint64(head.Len()), c.storeSingle(path, head.Bytes(), meta)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	partSize := MinPartSize
+	if size > 0 {
+		if plan := PlanMultipart(uint64(size)); plan.Parts > 1 {
+			partSize = plan.PartSize
+		}
+	}
+
+	return c.storeMultipart(path, io.MultiReader(head, reader), meta, partSize, size < 0)
+}
+
+func (c *Client) storeSingle(path string, fileContent []byte, meta map[string][]string) error {
+	if len(meta) == 0 {
+		return c.Store(path, fileContent)
+	}
+
+	headers := c.encryptionHeaders()
+	for key, values := range meta {
+		headers[metaHeaderPrefix+key] = values
+	}
+
+	return c.putHeader(path, fileContent, headers)
+}
+
+func (c *Client) putHeader(path string, fileContent []byte, headers map[string][]string) error {
+	c.startUpload(fileContent)
+	defer c.progress.PartFinished()
+	return c.bucket.PutReaderHeader(path, c.uploadReader(fileContent), int64(len(fileContent)), headers, "")
+}
+
+func (c *Client) startUpload(fileContent []byte) {
+	c.progress.Expect(int64(len(fileContent)))
+	c.progress.PartStarted()
+}
+
+func (c *Client) uploadReader(content []byte) io.ReadSeeker {
+	return &progressReader{reader: bytes.NewReader(content), progress: c.progress}
+}
+
+func (c *Client) storeMultipart(path string, reader io.Reader, meta map[string][]string, partSize uint64, growParts bool) (uint64, error) {
+	log.Info(fmt.Sprintf(" -- Uploading in parts of %d bytes", partSize))
+	headers := c.encryptionHeaders()
+	for key, values := range meta {
+		headers[metaHeaderPrefix+key] = values
+	}
+
+	multi, err := c.initMulti(path, headers)
+	if err != nil {
+		return 0, err
+	}
+
+	var stored uint64
+	parts := []goamzs3.Part{}
+	buffer := make([]byte, partSize)
+	for {
+		n, err := io.ReadFull(reader, buffer)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			multi.Abort()
+			return stored, err
+		}
+
+		c.progress.Expect(int64(n))
+		c.progress.PartStarted()
+		part, putErr := multi.PutPart(len(parts)+1, c.uploadReader(buffer[:n]))
+		c.progress.PartFinished()
+		if putErr != nil {
+			multi.Abort()
+			return stored, putErr
+		}
+
+		parts = append(parts, part)
+		stored += uint64(n)
+		if err == io.ErrUnexpectedEOF {
+			break
+		}
+
+		if growParts && uint64(len(parts))%PartsPerGrowth == 0 {
+			buffer = make([]byte, 2*len(buffer))
+		}
+	}
+
+	log.Info(fmt.Sprintf(" -- Uploaded %d parts", len(parts)))
+	err = multi.Complete(parts)
+	if err != nil {
+		multi.Abort()
+	}
+	return stored, err
+}
+
+func (c *Client) initMulti(path string, headers map[string][]string) (*goamzs3.Multi, error) {
+	content, err := c.request("POST", path, url.Values{"uploads": {""}}, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct{ UploadId string }
+	err = xml.Unmarshal(content, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goamzs3.Multi{Bucket: c.bucket, Key: path, UploadId: result.UploadId}, nil
+}
+
+func (c *Client) copyMultipart(fromPath, toPath string, size uint64, headers map[string][]string, plan MultipartPlan) error {
+	log.Info(fmt.Sprintf(" -- Copying %d parts of %d bytes", plan.Parts, plan.PartSize))
+	multi, err := c.initMulti(toPath, headers)
+	if err != nil {
+		return err
+	}
+
+	parts := []goamzs3.Part{}
+	for n := 0; n < plan.Parts; n++ {
+		start, end := plan.Bounds(n, size)
+		part, err := c.copyPart(multi, n+1, fromPath, start, end)
+		if err != nil {
+			multi.Abort()
+			return err
+		}
+
+		parts = append(parts, part)
+	}
+
+	err = multi.Complete(parts)
+	if err != nil {
+		multi.Abort()
+	}
+	return err
+}
+
+func (c *Client) copyPart(multi *goamzs3.Multi, n int, fromPath string, start, end uint64) (goamzs3.Part, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(n)}, "uploadId": {multi.UploadId}}
+	headers := map[string][]string{
+		"X-Amz-Copy-Source":       {(&url.URL{Path: "/" + c.bucket.Name + "/" + fromPath}).EscapedPath()},
+		"X-Amz-Copy-Source-Range": {fmt.Sprintf("bytes=%d-%d", start, end-1)},
+	}
+
+	content, err := c.request("PUT", multi.Key, query, headers, nil)
+	if err != nil {
+		return goamzs3.Part{}, err
+	}
+
+	var result struct{ ETag string }
+	err = xml.Unmarshal(content, &result)
+	if err != nil {
+		return goamzs3.Part{}, err
+	}
+
+	return goamzs3.Part{N: n, ETag: result.ETag, Size: int64(end - start)}, nil
+}
+
+func (c *Client) Labels(path string) (Labels, error) {
+	resp, err := c.bucket.Head(path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return decodeLabels(resp.Header.Get(labelsHeader))
+}
+
+func (c *Client) Attributes(path string) (Attributes, error) {
+	resp, err := c.bucket.Head(path)
+	if err != nil {
+		return Attributes{}, err
+	}
+	defer resp.Body.Close()
+
+	return decodeAttributes(resp.Header), nil
+}
+
+func (c *Client) Stat(path string) (ObjectInfo, error) {
+	resp, err := c.bucket.Head(path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	return newObjectInfo(path, resp), nil
+}
+
+func (c *Client) Tags(path string) (map[string]string, error) {
+	content, err := c.request("GET", path, url.Values{"tagging": {""}}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagging struct {
+		Tags []struct {
+			Key   string
+			Value string
+		} `xml:"TagSet>Tag"`
+	}
+	err = xml.Unmarshal(content, &tagging)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{}
+	for _, tag := range tagging.Tags {
+		tags[tag.Key] = tag.Value
+	}
+
+	return tags, nil
+}
+
+func (c *Client) EndpointTime() (time.Time, error) {
+	client := http.Client{Timeout: endpointTimeout}
+	resp, err := client.Head(c.s3.S3Endpoint)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp.Body.Close()
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("The endpoint '%s' didn't send a valid Date header", c.s3.S3Endpoint)
+	}
+
+	return serverTime, nil
+}
+
+func (c *Client) Endpoint() string {
+	return c.s3.S3Endpoint
 }
 
 func (c *Client) Delete(path string) error {
 	return c.bucket.Del(path)
 }
 
+func (c *Client) DeleteMulti(paths []string) error {
+	return c.bucket.MultiDel(paths)
+}
+
+func (c *Client) Size(path string) (uint64, error) {
+	resp, err := c.bucket.Head(path)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return uint64(resp.ContentLength), nil
+}
+
+func (c *Client) Copy(fromPath, toPath string) error {
+	resp, err := c.bucket.Head(fromPath)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	size := uint64(resp.ContentLength)
+	if plan := PlanCopy(size); plan.Parts > 1 {
+		headers := c.encryptionHeaders()
+		for key, values := range resp.Header {
+			if strings.HasPrefix(key, metaHeaderPrefix) || key == "Content-Type" {
+				headers[key] = values
+			}
+		}
+
+		return c.copyMultipart(fromPath, toPath, size, headers, plan)
+	}
+
+	options := goamzs3.CopyOptions{MetadataDirective: "COPY"}
+	options.SSE = c.encrypt
+	_, err = c.bucket.PutCopy(toPath, "", options, c.bucket.Name+"/"+fromPath)
+	return err
+}
+
 func (c *Client) List(path string) (Versions, error) {
-	resp, err := c.bucket.List(path+"/", "", "", 100)
+	inventory, err := c.Inventory(path)
+	if err != nil {
+		return Versions{}, err
+	}
+
+	for _, object := range inventory.Foreign {
+		log.Warn(" -- skipping unrecognized key:", object.Path)
+	}
+
+	return inventory.Versions, nil
+}
+
+func (c *Client) ListUncommitted(path string) ([]Object, error) {
+	inventory, err := c.Inventory(path)
+	if err != nil {
+		return []Object{}, err
+	}
+
+	return append(inventory.Staging, inventory.OrphanParts...), nil
+}
+
+// ListIncompleteUploads lists the multipart uploads of the staged uploads and
+// bundle parts of the backup, e.g. of pushes that were killed midway.
+func (c *Client) ListIncompleteUploads(path string) ([]Upload, error) {
+	query := url.Values{"uploads": {""}, "prefix": {metadataPath(path)}}
+	uploads := []Upload{}
+	for {
+		content, err := c.request("GET", "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			IsTruncated        bool
+			NextKeyMarker      string
+			NextUploadIdMarker string
+			Upload             []struct {
+				Key       string
+				UploadId  string
+				Initiated string
+			}
+		}
+		err = xml.Unmarshal(content, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, upload := range result.Upload {
+			initiated, err := time.Parse(time.RFC3339, upload.Initiated)
+			if err != nil {
+				return nil, errors.New("Failed to parse the upload timestamp. '" + upload.Initiated + "' was not recognized")
+			}
+			uploads = append(uploads, Upload{Path: upload.Key, UploadID: upload.UploadId, Initiated: initiated})
+		}
+
+		if !result.IsTruncated || result.NextKeyMarker == "" {
+			return uploads, nil
+		}
+		query.Set("key-marker", result.NextKeyMarker)
+		query.Set("upload-id-marker", result.NextUploadIdMarker)
+	}
+}
+
+func (c *Client) AbortUpload(path, uploadID string) error {
+	_, err := c.request("DELETE", path, url.Values{"uploadId": {uploadID}}, nil, nil)
+	return err
+}
+
+func (c *Client) Inventory(path string) (Inventory, error) {
+	keys, err := c.listKeys(path + "/")
+	if err != nil {
+		return Inventory{}, err
+	}
+
+	return c.newInventory(path, keys), nil
+}
+
+func (c *Client) listKeys(prefix string) ([]goamzs3.Key, error) {
+	keys := []goamzs3.Key{}
+	marker := ""
+	for {
+		resp, err := c.bucket.List(prefix, "", marker, 1000)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, resp.Contents...)
+		if !resp.IsTruncated || len(resp.Contents) == 0 {
+			return keys, nil
+		}
+
+		marker = resp.Contents[len(resp.Contents)-1].Key
+		if resp.NextMarker != "" {
+			marker = resp.NextMarker
+		}
+	}
+}
+
+func (c *Client) BackupNames(prefix string) ([]string, error) {
+	keys, err := c.listKeys(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+	for _, key := range keys {
+		if name, ok := parseMetadataName(key.Key); ok {
+			found[name] = true
+		} else if name, ok := c.keyTemplate.ParseName(key.Key); ok {
+			found[name] = true
+		}
+	}
+
+	names := []string{}
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (c *Client) ListWithLabels(path string) (Versions, error) {
+	versions, err := c.List(path)
 	if err != nil {
-		return []Version{}, err
+		return versions, err
 	}
 
-	files := []Version{}
-	for _, file := range resp.Contents {
-		version, err := NewVersion(file)
+	for i := range versions {
+		versions[i].Labels, err = c.Labels(versions[i].Path)
 		if err != nil {
 			return Versions{}, err
 		}
-		files = append(files, version)
 	}
 
-	return files, nil
+	return versions, nil
 }
 
 func (c *Client) Get(path string) ([]byte, error) {
-	return c.bucket.Get(path)
+	resp, err := c.bucket.GetResponse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	body := newProgressBody(resp.Body, resp.ContentLength, c.progress)
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+func (c *Client) GetFrom(path string, offset uint64) (io.ReadCloser, uint64, error) {
+	return c.GetFromIfMatch(path, offset, "")
+}
+
+// GetFromIfMatch fails with a precondition error, instead of returning another
+// content, when the object no longer has the given ETag.
+func (c *Client) GetFromIfMatch(path string, offset uint64, etag string) (io.ReadCloser, uint64, error) {
+	if offset == 0 && etag == "" {
+		resp, err := c.bucket.GetResponse(path)
+		if err != nil {
+			return nil, 0, err
+		}
+		return newProgressBody(resp.Body, resp.ContentLength, c.progress), 0, nil
+	}
+
+	req, err := http.NewRequest("GET", c.bucket.SignedURL(path, time.Now().Add(time.Hour)), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if etag != "" {
+		req.Header.Set("If-Match", `"`+etag+`"`)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return newProgressBody(resp.Body, resp.ContentLength, c.progress), offset, nil
+	case http.StatusOK:
+		return newProgressBody(resp.Body, resp.ContentLength, c.progress), 0, nil
+	}
+
+	resp.Body.Close()
+	message := fmt.Sprintf("Failed to get '%s' from byte %d: %s", path, offset, resp.Status)
+	return nil, 0, &goamzs3.Error{StatusCode: resp.StatusCode, Message: message}
+}
+
+func (c *Client) encryptionHeaders() map[string][]string {
+	if !c.encrypt {
+		return map[string][]string{}
+	}
+
+	return map[string][]string{"X-Amz-Server-Side-Encryption": {ServerSideEncryption}}
+}
+
+func (c *Client) VersionPath(backupName, version string) (string, error) {
+	return c.keyTemplate.Key(backupName, version)
+}
+
+func (c *Client) parseVersion(backupName string, key goamzs3.Key) (Version, error) {
+	if version, ok := c.keyTemplate.ParseKey(backupName, key.Key); ok {
+		return newVersion(key, backupName, version)
+	}
+
+	return NewVersion(key)
 }
@@ -1,8 +1,17 @@
 package s3_test
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing/iotest"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -51,6 +60,60 @@ var _ = Describe("Client", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(remoteContent).To(Equal(fileContent))
 		})
+
+		Context("when server side encryption is enabled", func() {
+			BeforeEach(func() {
+				client.EnableServerSideEncryption()
+			})
+
+			It("stores the file content and keeps the labels", func() {
+				err := client.StoreWithLabels(filePath, []byte("test"), s3.Labels{"kind": "nightly"})
+				Expect(err).ToNot(HaveOccurred())
+
+				remoteContent, err := bucket.Get(filePath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(remoteContent).To(Equal([]byte("test")))
+
+				labels, err := client.Labels(filePath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(labels).To(Equal(s3.Labels{"kind": "nightly"}))
+			})
+		})
+	})
+
+	Describe("#StoreWithLabels", func() {
+		It("stores the labels with the file", func() {
+			err := client.StoreWithLabels(filePath, []byte("test"), s3.Labels{"kind": "pre-deploy", "env": "prod"})
+			Expect(err).ToNot(HaveOccurred())
+
+			labels, err := client.Labels(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labels).To(Equal(s3.Labels{"kind": "pre-deploy", "env": "prod"}))
+		})
+
+		It("stores files without labels", func() {
+			err := client.StoreWithLabels(filePath, []byte("test"), nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			labels, err := client.Labels(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labels).To(BeEmpty())
+		})
+	})
+
+	Describe("#ListWithLabels", func() {
+		It("lists the versions with their labels", func() {
+			err := client.StoreWithLabels(filePath+"/1", []byte("test"), s3.Labels{"kind": "nightly"})
+			Expect(err).ToNot(HaveOccurred())
+			err = client.Store(filePath+"/2", []byte("test"))
+			Expect(err).ToNot(HaveOccurred())
+
+			versions, err := client.ListWithLabels(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(versions)).To(Equal(2))
+			Expect(versions[0].Labels).To(Equal(s3.Labels{"kind": "nightly"}))
+			Expect(versions[1].Labels).To(BeEmpty())
+		})
 	})
 
 	Describe("#List", func() {
@@ -68,10 +131,444 @@ var _ = Describe("Client", func() {
 			Expect(len(files)).To(Equal(5))
 
 			for i := 0; i < 5; i++ {
-				Expect(files[i].Version).To(Equal(int64(i)))
+				Expect(files[i].Version).To(Equal(fmt.Sprintf("%d", i)))
 				Expect(files[i].BackupName).To(Equal(filePath))
 			}
 		})
+
+		Context("when there are pinned versions", func() {
+			BeforeEach(func() {
+				err := bucket.Put(s3.PinPath(filePath, "3"), []byte{}, "", "")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("doesn't list the pin markers as versions", func() {
+				files, err := client.List(filePath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(files)).To(Equal(5))
+			})
+
+			It("flags the pinned versions", func() {
+				files, err := client.List(filePath)
+				Expect(err).ToNot(HaveOccurred())
+
+				for _, file := range files {
+					Expect(file.Pinned).To(Equal(file.Version == "3"))
+				}
+			})
+		})
+		Context("when there are unrecognized keys", func() {
+			BeforeEach(func() {
+				err := bucket.Put(filePath+"/README", []byte("read me"), "", "")
+				Expect(err).ToNot(HaveOccurred())
+				err = bucket.Put(filePath+"/.s3kup/unknown/1", []byte{}, "", "")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("skips them", func() {
+				files, err := client.List(filePath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(files)).To(Equal(5))
+			})
+		})
+	})
+
+	Describe("#List with bundles", func() {
+		It("lists the bundle parts with their version", func() {
+			err := bucket.Put(filePath+"/1", []byte("manifest"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(s3.PartPath(filePath, "1", "db"), []byte("db"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(s3.PartPath(filePath, "1", "uploads"), []byte("uploads"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			versions, err := client.List(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(versions)).To(Equal(1))
+			Expect(versions[0].PartPaths).To(ConsistOf(
+				s3.PartPath(filePath, "1", "db"),
+				s3.PartPath(filePath, "1", "uploads"),
+			))
+		})
+	})
+
+	Describe("#ListUncommitted", func() {
+		It("returns the staged uploads and the parts without a manifest", func() {
+			err := bucket.Put(filePath+"/1", []byte("manifest"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(s3.PartPath(filePath, "1", "db"), []byte("db"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(s3.PartPath(filePath, "2", "db"), []byte("db"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(s3.StagingPath(filePath, "3"), []byte("test"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			objects, err := client.ListUncommitted(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(objects)).To(Equal(2))
+			Expect(objects[0].Path).To(Equal(s3.StagingPath(filePath, "3")))
+			Expect(objects[1].Path).To(Equal(s3.PartPath(filePath, "2", "db")))
+			Expect(objects[1].Size).To(Equal(uint64(2)))
+
+			versions, err := client.List(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(versions)).To(Equal(1))
+		})
+	})
+
+	Describe("#ListIncompleteUploads", func() {
+		var server *httptest.Server
+		var requests []string
+
+		BeforeEach(func() {
+			requests = []string{}
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+				switch {
+				case r.Method == "DELETE":
+					w.WriteHeader(http.StatusNoContent)
+				case r.URL.Query().Get("key-marker") == "":
+					fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>true</IsTruncated><NextKeyMarker>my/backup/.s3kup/staging/1</NextKeyMarker><NextUploadIdMarker>upload-1</NextUploadIdMarker>
+<Upload><Key>my/backup/.s3kup/staging/1</Key><UploadId>upload-1</UploadId><Initiated>2020-01-02T03:04:05.000Z</Initiated></Upload></ListMultipartUploadsResult>`)
+				default:
+					fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated>
+<Upload><Key>my/backup/.s3kup/parts/2/db</Key><UploadId>upload-2</UploadId><Initiated>2020-01-03T03:04:05.000Z</Initiated></Upload></ListMultipartUploadsResult>`)
+				}
+			}))
+
+			client = s3.New(accessKey, secretKey, bucketName, server.URL)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("lists the uploads under the metadata of the backup, page by page", func() {
+			uploads, err := client.ListIncompleteUploads("my/backup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uploads).To(Equal([]s3.Upload{
+				{Path: "my/backup/.s3kup/staging/1", UploadID: "upload-1", Initiated: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+				{Path: "my/backup/.s3kup/parts/2/db", UploadID: "upload-2", Initiated: time.Date(2020, 1, 3, 3, 4, 5, 0, time.UTC)},
+			}))
+			Expect(requests).To(Equal([]string{
+				"GET /my_bucket/?prefix=my%2Fbackup%2F.s3kup%2F&uploads",
+				"GET /my_bucket/?key-marker=my%2Fbackup%2F.s3kup%2Fstaging%2F1&prefix=my%2Fbackup%2F.s3kup%2F&upload-id-marker=upload-1&uploads",
+			}))
+		})
+
+		It("aborts an upload", func() {
+			err := client.AbortUpload("my/backup/.s3kup/staging/1", "upload-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests).To(Equal([]string{"DELETE /my_bucket/my/backup/.s3kup/staging/1?uploadId=upload-1"}))
+		})
+	})
+
+	Describe("#Inventory", func() {
+		It("classifies every key under the backup path", func() {
+			err := bucket.Put(filePath+"/1", []byte("test"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(filePath+"/README", []byte("read me"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(s3.PinPath(filePath, "1"), []byte{}, "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(s3.PinPath(filePath, "2"), []byte{}, "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(s3.PartPath(filePath, "3", "db"), []byte("db"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(s3.StagingPath(filePath, "4"), []byte("test"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			inventory, err := client.Inventory(filePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(len(inventory.Versions)).To(Equal(1))
+			Expect(inventory.Versions[0].Pinned).To(BeTrue())
+			Expect(len(inventory.Foreign)).To(Equal(1))
+			Expect(inventory.Foreign[0].Path).To(Equal(filePath + "/README"))
+			Expect(len(inventory.OrphanPins)).To(Equal(1))
+			Expect(inventory.OrphanPins[0].Path).To(Equal(s3.PinPath(filePath, "2")))
+			Expect(len(inventory.OrphanParts)).To(Equal(1))
+			Expect(inventory.OrphanParts[0].Path).To(Equal(s3.PartPath(filePath, "3", "db")))
+			Expect(len(inventory.Staging)).To(Equal(1))
+			Expect(inventory.Staging[0].Path).To(Equal(s3.StagingPath(filePath, "4")))
+		})
+	})
+
+	Describe("#StoreReader", func() {
+		It("stores content of unknown size and returns how much was stored", func() {
+			size, err := client.StoreReader(filePath, strings.NewReader("streamed"), -1, s3.Labels{"kind": "nightly"}, s3.Attributes{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(size).To(Equal(uint64(8)))
+
+			remoteContent, err := bucket.Get(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remoteContent).To(Equal([]byte("streamed")))
+
+			labels, err := client.Labels(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labels).To(Equal(s3.Labels{"kind": "nightly"}))
+		})
+
+		It("doesn't store anything when reading the content fails", func() {
+			_, err := client.StoreReader(filePath, iotest.ErrReader(errors.New("failed to read")), -1, nil, s3.Attributes{})
+			Expect(err).To(MatchError("failed to read"))
+
+			_, err = bucket.Get(filePath)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#StoreWithAttributes", func() {
+		It("stores the file mode, modification time and checksum with the file", func() {
+			modTime := time.Date(2026, 10, 1, 3, 0, 0, 123, time.UTC)
+			attributes := s3.Attributes{Mode: 0640, ModTime: modTime, Checksum: "md5:098f6bcd4621d373cade4e832627b4f6"}
+			err := client.StoreWithAttributes(filePath, []byte("test"), s3.Labels{"kind": "nightly"}, attributes)
+			Expect(err).ToNot(HaveOccurred())
+
+			storedAttributes, err := client.Attributes(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedAttributes.Mode).To(Equal(os.FileMode(0640)))
+			Expect(storedAttributes.ModTime.Equal(modTime)).To(BeTrue())
+			Expect(storedAttributes.Checksum).To(Equal("md5:098f6bcd4621d373cade4e832627b4f6"))
+
+			labels, err := client.Labels(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labels).To(Equal(s3.Labels{"kind": "nightly"}))
+		})
+
+		It("returns empty attributes when none were stored", func() {
+			err := client.Store(filePath, []byte("test"))
+			Expect(err).ToNot(HaveOccurred())
+
+			attributes, err := client.Attributes(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(attributes.IsZero()).To(BeTrue())
+		})
+	})
+
+	Describe("#GetFrom", func() {
+		BeforeEach(func() {
+			err := bucket.Put(filePath, []byte("my file contents"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns the content from the start", func() {
+			body, start, err := client.GetFrom(filePath, 0)
+			Expect(err).ToNot(HaveOccurred())
+			defer body.Close()
+
+			Expect(start).To(Equal(uint64(0)))
+			Expect(ioutil.ReadAll(body)).To(Equal([]byte("my file contents")))
+		})
+
+		It("returns the content from the offset the body starts at", func() {
+			body, start, err := client.GetFrom(filePath, 3)
+			Expect(err).ToNot(HaveOccurred())
+			defer body.Close()
+
+			Expect(ioutil.ReadAll(body)).To(Equal([]byte("my file contents"[start:])))
+		})
+	})
+
+	Describe("#Stat", func() {
+		It("returns what is known about the stored object", func() {
+			err := client.StoreWithLabels(filePath, []byte("test"), s3.Labels{"kind": "nightly"})
+			Expect(err).ToNot(HaveOccurred())
+
+			info, err := client.Stat(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Path).To(Equal(filePath))
+			Expect(info.Size).To(Equal(uint64(4)))
+			Expect(info.LastModified.IsZero()).To(BeFalse())
+			Expect(info.ETag).To(Equal("098f6bcd4621d373cade4e832627b4f6"))
+			Expect(info.Checksum).To(Equal("md5:098f6bcd4621d373cade4e832627b4f6"))
+			Expect(info.StorageClass).To(Equal("STANDARD"))
+			Expect(info.Metadata).To(HaveKeyWithValue("s3kup-labels", "kind=nightly"))
+			Expect(info.Labels).To(Equal(s3.Labels{"kind": "nightly"}))
+		})
+
+		It("fails when the object doesn't exist", func() {
+			_, err := client.Stat(filePath)
+			Expect(err).To(HaveOccurred())
+		})
+
+		Context("when the object is encrypted with SSE-KMS", func() {
+			var server *httptest.Server
+
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("ETag", `"0a8b1c2d3e4f5a6b7c8d9e0f1a2b3c4d"`)
+					w.Header().Set("X-Amz-Server-Side-Encryption", "aws:kms")
+					w.Header().Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", "arn:aws:kms:us-east-1:1:key/1")
+				}))
+
+				client = s3.New(accessKey, secretKey, bucketName, server.URL)
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			It("doesn't take the ETag for the MD5 of the content", func() {
+				info, err := client.Stat("my/backup/1")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(info.ETag).To(Equal("0a8b1c2d3e4f5a6b7c8d9e0f1a2b3c4d"))
+				Expect(info.Checksum).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#Tags", func() {
+		var server *httptest.Server
+		var requests []string
+
+		BeforeEach(func() {
+			requests = []string{}
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+				fmt.Fprint(w, `<Tagging><TagSet><Tag><Key>team</Key><Value>db</Value></Tag><Tag><Key>cost-center</Key><Value>42</Value></Tag></TagSet></Tagging>`)
+			}))
+
+			client = s3.New(accessKey, secretKey, bucketName, server.URL)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("returns the tag set of the object", func() {
+			tags, err := client.Tags("my/backup/1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tags).To(Equal(map[string]string{"team": "db", "cost-center": "42"}))
+			Expect(requests).To(Equal([]string{"GET /my_bucket/my/backup/1?tagging"}))
+		})
+	})
+
+	Describe("#GetFromIfMatch", func() {
+		var server *httptest.Server
+		var restoreTimeout func()
+
+		BeforeEach(func() {
+			restoreTimeout = s3.SetResponseHeaderTimeout(50 * time.Millisecond)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(time.Second)
+			}))
+
+			client = s3.New(accessKey, secretKey, bucketName, server.URL)
+		})
+
+		AfterEach(func() {
+			server.CloseClientConnections()
+			server.Close()
+			restoreTimeout()
+		})
+
+		It("gives up when s3 doesn't answer", func() {
+			_, _, err := client.GetFromIfMatch("my/backup/1", 10, "etag")
+			Expect(err).To(MatchError(ContainSubstring("timeout awaiting response headers")))
+		})
+	})
+
+	Describe("#Size", func() {
+		It("returns the size of the stored object", func() {
+			err := bucket.Put(filePath, []byte("test"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			size, err := client.Size(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(size).To(Equal(uint64(4)))
+		})
+	})
+
+	Describe("#Copy", func() {
+		It("copies the object and its labels", func() {
+			err := client.StoreWithLabels(filePath+"/from", []byte("test"), s3.Labels{"kind": "nightly"})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = client.Copy(filePath+"/from", filePath+"/to")
+			Expect(err).ToNot(HaveOccurred())
+
+			content, err := bucket.Get(filePath + "/to")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(Equal([]byte("test")))
+
+			labels, err := client.Labels(filePath + "/to")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labels).To(Equal(s3.Labels{"kind": "nightly"}))
+		})
+
+		Context("when the object is bigger than a single copy request takes", func() {
+			var server *httptest.Server
+			var requests []string
+			var initHeaders http.Header
+
+			BeforeEach(func() {
+				requests = []string{}
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+" "+r.Header.Get("X-Amz-Copy-Source-Range"))
+					switch {
+					case r.Method == "HEAD":
+						w.Header().Set("Content-Length", fmt.Sprintf("%d", s3.MaxCopySize+1))
+						w.Header().Set("X-Amz-Meta-S3kup-Labels", "kind=nightly")
+					case r.Method == "POST" && r.URL.RawQuery == "uploads":
+						initHeaders = r.Header
+						fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload</UploadId></InitiateMultipartUploadResult>`)
+					case r.Method == "PUT":
+						fmt.Fprint(w, `<CopyPartResult><ETag>"etag"</ETag></CopyPartResult>`)
+					default:
+						fmt.Fprint(w, `<CompleteMultipartUploadResult></CompleteMultipartUploadResult>`)
+					}
+				}))
+
+				client = s3.New(accessKey, secretKey, bucketName, server.URL)
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			It("copies it in parts with the metadata set when the upload starts", func() {
+				err := client.Copy("big/from", "big/to")
+				Expect(err).ToNot(HaveOccurred())
+
+				plan := s3.PlanCopy(s3.MaxCopySize + 1)
+				Expect(requests).To(HaveLen(plan.Parts + 3))
+				Expect(requests[1]).To(Equal("POST /my_bucket/big/to?uploads "))
+				Expect(requests[2]).To(Equal("PUT /my_bucket/big/to?partNumber=1&uploadId=upload bytes=0-16777215"))
+				Expect(requests[plan.Parts+1]).To(Equal("PUT /my_bucket/big/to?partNumber=321&uploadId=upload bytes=5368709120-5368709120"))
+				Expect(requests[plan.Parts+2]).To(HavePrefix("POST /my_bucket/big/to?uploadId=upload"))
+				Expect(initHeaders.Get("X-Amz-Meta-S3kup-Labels")).To(Equal("kind=nightly"))
+			})
+		})
+	})
+
+	Describe("with a key template", func() {
+		BeforeEach(func() {
+			keyTemplate, err := s3.NewKeyTemplate("{{.Name}}/{{.Year}}/{{.Month}}/{{.ID}}{{.Ext}}", ".gz")
+			Expect(err).ToNot(HaveOccurred())
+
+			client = s3.NewWithKeyTemplate(accessKey, secretKey, bucketName, s3EndpointURL, keyTemplate)
+		})
+
+		It("renders the version path with the template", func() {
+			Expect(client.VersionPath(filePath, "1427571015905296950")).To(Equal(filePath + "/2015/03/1427571015905296950.gz"))
+		})
+
+		It("lists the versions stored with the template and with the default layout", func() {
+			err := bucket.Put(filePath+"/2015/03/1427571015905296950.gz", []byte("test"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			err = bucket.Put(filePath+"/1427554100187348642", []byte("test"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			files, err := client.List(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(files)).To(Equal(2))
+
+			for _, file := range files {
+				Expect(file.BackupName).To(Equal(filePath))
+				Expect([]string{"1427571015905296950", "1427554100187348642"}).To(ContainElement(file.Version))
+			}
+		})
 	})
 
 	Describe("#Get", func() {
@@ -93,6 +590,60 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Describe("#SetProgress", func() {
+		var recorder *recordingProgress
+
+		BeforeEach(func() {
+			recorder = &recordingProgress{}
+			client.SetProgress(recorder)
+		})
+
+		It("reports the bytes and parts of uploads", func() {
+			err := client.StoreWithLabels(filePath, []byte("my file contents"), s3.Labels{"kind": "nightly"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(recorder.expected).To(Equal(int64(16)))
+			Expect(recorder.transferred).To(Equal(int64(16)))
+			Expect(recorder.started).To(Equal(1))
+			Expect(recorder.finished).To(Equal(1))
+		})
+
+		It("reports the bytes and parts of downloads", func() {
+			err := bucket.Put(filePath, []byte("my file contents"), "", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.Get(filePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			body, _, err := client.GetFrom(filePath, 0)
+			Expect(err).ToNot(HaveOccurred())
+			ioutil.ReadAll(io.LimitReader(body, 4))
+			body.Close()
+
+			Expect(recorder.expected).To(Equal(int64(20)))
+			Expect(recorder.transferred).To(Equal(int64(20)))
+			Expect(recorder.started).To(Equal(2))
+			Expect(recorder.finished).To(Equal(2))
+		})
+	})
+
+	Describe("#EndpointTime", func() {
+		It("returns the time of the endpoint", func() {
+			endpointTime, err := client.EndpointTime()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(endpointTime).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+
+		Context("when the endpoint can't be reached", func() {
+			It("returns an error", func() {
+				client = s3.New(accessKey, secretKey, bucketName, "http://127.0.0.1:1")
+
+				_, err := client.EndpointTime()
+				Expect(s3.IsNetworkError(err)).To(BeTrue())
+			})
+		})
+	})
+
 	Describe("#Delete", func() {
 		It("removes the s3 file path", func() {
 			err := bucket.Put(filePath, []byte("test"), "", "")
@@ -105,4 +656,47 @@ var _ = Describe("Client", func() {
 			Expect(err).To(MatchError("The specified key does not exist."))
 		})
 	})
+
+	Describe("#DeleteMulti", func() {
+		It("removes all the given paths", func() {
+			for i := 0; i < 3; i++ {
+				err := bucket.Put(fmt.Sprintf("%s/%d", filePath, i), []byte("test"), "", "")
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			err := client.DeleteMulti([]string{filePath + "/0", filePath + "/2"})
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := bucket.List(filePath+"/", "", "", 100)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resp.Contents)).To(Equal(1))
+			Expect(resp.Contents[0].Key).To(Equal(filePath + "/1"))
+		})
+	})
+
+	Describe("#BackupNames", func() {
+		It("returns the names of the backups with versions or metadata under the prefix", func() {
+			bucket.Put(filePath+"/pg/prod/1427571015905296950", []byte("test"), "", "")
+			bucket.Put(filePath+"/pg/prod/1427571015905296951", []byte("test"), "", "")
+			bucket.Put(filePath+"/pg/staging/.s3kup/staging/1427571015905296950", []byte("test"), "", "")
+			bucket.Put(filePath+"/mysql/1427571015905296950", []byte("test"), "", "")
+			bucket.Put(filePath+"/pg/README.md", []byte("test"), "", "")
+
+			names, err := client.BackupNames(filePath + "/pg/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(Equal([]string{filePath + "/pg/prod", filePath + "/pg/staging"}))
+		})
+	})
 })
+
+type recordingProgress struct {
+	expected    int64
+	transferred int64
+	started     int
+	finished    int
+}
+
+func (p *recordingProgress) Expect(bytes int64)      { p.expected += bytes }
+func (p *recordingProgress) Transferred(bytes int64) { p.transferred += bytes }
+func (p *recordingProgress) PartStarted()            { p.started++ }
+func (p *recordingProgress) PartFinished()           { p.finished++ }
@@ -0,0 +1,69 @@
+package s3
+
+import (
+	"errors"
+	"io"
+	"net"
+
+	goamzs3 "github.com/mitchellh/goamz/s3"
+)
+
+var credentialsErrorCodes = map[string]bool{
+	"AccessDenied":          true,
+	"InvalidAccessKeyId":    true,
+	"SignatureDoesNotMatch": true,
+	"ExpiredToken":          true,
+	"InvalidToken":          true,
+}
+
+var notFoundErrorCodes = map[string]bool{
+	"NoSuchBucket": true,
+	"NoSuchKey":    true,
+}
+
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+func IsNotFoundError(err error) bool {
+	var notFoundErr *NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return true
+	}
+
+	var s3Err *goamzs3.Error
+	return errors.As(err, &s3Err) && (s3Err.StatusCode == 404 || notFoundErrorCodes[s3Err.Code])
+}
+
+func IsCredentialsError(err error) bool {
+	var s3Err *goamzs3.Error
+	return errors.As(err, &s3Err) && (s3Err.StatusCode == 401 || s3Err.StatusCode == 403 || credentialsErrorCodes[s3Err.Code])
+}
+
+func IsChangedError(err error) bool {
+	var s3Err *goamzs3.Error
+	return errors.As(err, &s3Err) && s3Err.StatusCode == 412
+}
+
+func IsNetworkError(err error) bool {
+	var s3Err *goamzs3.Error
+	if errors.As(err, &s3Err) {
+		return s3Err.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func ErrorCode(err error) string {
+	var s3Err *goamzs3.Error
+	if errors.As(err, &s3Err) {
+		return s3Err.Code
+	}
+
+	return ""
+}
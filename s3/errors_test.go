@@ -0,0 +1,72 @@
+package s3_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+
+	"github.com/tscolari/s3kup/s3"
+
+	goamzs3 "github.com/mitchellh/goamz/s3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+	s3Error := func(statusCode int, code string) error {
+		return fmt.Errorf("failed: %w", &goamzs3.Error{StatusCode: statusCode, Code: code, Message: code})
+	}
+
+	networkError := &url.Error{Op: "Get", URL: "https://s3.amazonaws.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+
+	Describe("IsNotFoundError", func() {
+		It("recognizes missing versions, keys and buckets", func() {
+			Expect(s3.IsNotFoundError(&s3.NotFoundError{Message: "Could not find version '1'"})).To(BeTrue())
+			Expect(s3.IsNotFoundError(s3Error(404, "NoSuchKey"))).To(BeTrue())
+			Expect(s3.IsNotFoundError(s3Error(404, ""))).To(BeTrue())
+			Expect(s3.IsNotFoundError(s3Error(0, "NoSuchBucket"))).To(BeTrue())
+
+			Expect(s3.IsNotFoundError(s3Error(403, "AccessDenied"))).To(BeFalse())
+			Expect(s3.IsNotFoundError(errors.New("Could not find version '1'"))).To(BeFalse())
+		})
+	})
+
+	Describe("IsCredentialsError", func() {
+		It("recognizes rejected credentials", func() {
+			Expect(s3.IsCredentialsError(s3Error(403, "InvalidAccessKeyId"))).To(BeTrue())
+			Expect(s3.IsCredentialsError(s3Error(403, "SignatureDoesNotMatch"))).To(BeTrue())
+			Expect(s3.IsCredentialsError(s3Error(400, "ExpiredToken"))).To(BeTrue())
+
+			Expect(s3.IsCredentialsError(s3Error(404, "NoSuchKey"))).To(BeFalse())
+			Expect(s3.IsCredentialsError(networkError)).To(BeFalse())
+		})
+	})
+
+	Describe("IsChangedError", func() {
+		It("recognizes failed preconditions", func() {
+			Expect(s3.IsChangedError(s3Error(412, "PreconditionFailed"))).To(BeTrue())
+
+			Expect(s3.IsChangedError(s3Error(404, "NoSuchKey"))).To(BeFalse())
+			Expect(s3.IsChangedError(networkError)).To(BeFalse())
+		})
+	})
+
+	Describe("IsNetworkError", func() {
+		It("recognizes connection failures and server errors", func() {
+			Expect(s3.IsNetworkError(networkError)).To(BeTrue())
+			Expect(s3.IsNetworkError(fmt.Errorf("download failed: %w", io.ErrUnexpectedEOF))).To(BeTrue())
+			Expect(s3.IsNetworkError(s3Error(503, "SlowDown"))).To(BeTrue())
+
+			Expect(s3.IsNetworkError(s3Error(403, "AccessDenied"))).To(BeFalse())
+			Expect(s3.IsNetworkError(errors.New("something else"))).To(BeFalse())
+		})
+	})
+	Describe("ErrorCode", func() {
+		It("returns the code of s3 errors", func() {
+			Expect(s3.ErrorCode(fmt.Errorf("put failed: %w", s3Error(403, "AccessDenied")))).To(Equal("AccessDenied"))
+			Expect(s3.ErrorCode(networkError)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var etagRegexp = regexp.MustCompile(`^[0-9a-f]{32}(-[0-9]+)?$`)
+
+type ETagHash struct {
+	partSize       uint64
+	multipart      bool
+	partsPerGrowth uint64
+	current        hash.Hash
+	filled         uint64
+	partSums       []byte
+}
+
+func NewETagHash(etag string, size uint64) (*ETagHash, bool) {
+	etag = strings.ToLower(etag)
+	if !etagRegexp.MatchString(etag) {
+		return nil, false
+	}
+
+	h := &ETagHash{current: md5.New()}
+	dash := strings.Index(etag, "-")
+	if dash < 0 {
+		return h, true
+	}
+
+	parts, err := strconv.Atoi(etag[dash+1:])
+	if err != nil {
+		return nil, false
+	}
+
+	// Uploads of unknown size, like piped pushes, grow their parts instead
+	// of following the plan.
+	switch plan := PlanMultipart(size); parts {
+	case plan.Parts:
+		h.partSize = plan.PartSize
+	case GrowingParts(size):
+		h.partSize = MinPartSize
+		h.partsPerGrowth = PartsPerGrowth
+	default:
+		return nil, false
+	}
+
+	h.multipart = true
+	return h, true
+}
+
+// NewUploadETagHash computes the ETag s3 gives to a StoreReader upload of size
+// bytes.
+func NewUploadETagHash(size uint64) *ETagHash {
+	h := &ETagHash{current: md5.New()}
+	if plan := PlanMultipart(size); plan.Parts > 1 {
+		h.partSize = plan.PartSize
+		h.multipart = true
+	}
+
+	return h
+}
+
+func (h *ETagHash) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		chunk := p
+		if h.multipart && uint64(len(chunk)) > h.partSize-h.filled {
+			chunk = chunk[:h.partSize-h.filled]
+		}
+
+		h.current.Write(chunk)
+		h.filled += uint64(len(chunk))
+		p = p[len(chunk):]
+
+		if h.multipart && h.filled == h.partSize {
+			h.partSums = h.current.Sum(h.partSums)
+			h.current.Reset()
+			h.filled = 0
+
+			if h.partsPerGrowth > 0 && uint64(len(h.partSums)/md5.Size)%h.partsPerGrowth == 0 {
+				h.partSize *= 2
+			}
+		}
+	}
+
+	return written, nil
+}
+
+func (h *ETagHash) ETag() string {
+	if !h.multipart {
+		return hex.EncodeToString(h.current.Sum(nil))
+	}
+
+	partSums := append([]byte{}, h.partSums...)
+	if h.filled > 0 {
+		partSums = h.current.Sum(partSums)
+	}
+
+	sum := md5.Sum(partSums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(partSums)/md5.Size)
+}
@@ -0,0 +1,122 @@
+package s3_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ETagHash", func() {
+	md5Hex := func(content []byte) string {
+		sum := md5.Sum(content)
+		return hex.EncodeToString(sum[:])
+	}
+
+	It("computes the MD5 of single part objects", func() {
+		content := []byte("my file contents")
+
+		h, ok := s3.NewETagHash(md5Hex(content), uint64(len(content)))
+		Expect(ok).To(BeTrue())
+
+		h.Write(content[:4])
+		h.Write(content[4:])
+		Expect(h.ETag()).To(Equal(md5Hex(content)))
+	})
+
+	It("computes the ETag of objects uploaded in parts, from the same plan as the upload", func() {
+		content := bytes.Repeat([]byte("0123456789abcdef"), int(s3.MultipartThreshold/16)+1024)
+		plan := s3.PlanMultipart(uint64(len(content)))
+
+		partSums := []byte{}
+		for start := uint64(0); start < uint64(len(content)); start += plan.PartSize {
+			end := start + plan.PartSize
+			if end > uint64(len(content)) {
+				end = uint64(len(content))
+			}
+			sum := md5.Sum(content[start:end])
+			partSums = append(partSums, sum[:]...)
+		}
+		etag := fmt.Sprintf("%s-%d", md5Hex(partSums), plan.Parts)
+
+		h, ok := s3.NewETagHash(etag, uint64(len(content)))
+		Expect(ok).To(BeTrue())
+
+		for start := 0; start < len(content); start += 1000003 {
+			end := start + 1000003
+			if end > len(content) {
+				end = len(content)
+			}
+			h.Write(content[start:end])
+		}
+		Expect(h.ETag()).To(Equal(etag))
+	})
+
+	It("computes the ETag of uploads of unknown size, whose parts grow", func() {
+		content := []byte("0123456789abcdefghijklmnopqrstuvwxyz0123456789abcdefghijklmnopqrstuvwxyz0123456789abcdefghij")
+
+		partSums := []byte{}
+		start, partSize := 0, 4
+		for n := 1; start < len(content); n++ {
+			end := start + partSize
+			if end > len(content) {
+				end = len(content)
+			}
+			sum := md5.Sum(content[start:end])
+			partSums = append(partSums, sum[:]...)
+
+			start = end
+			if n%3 == 0 {
+				partSize *= 2
+			}
+		}
+		Expect(len(partSums) / md5.Size).To(Equal(10))
+
+		h := s3.NewGrowingETagHash(4, 3)
+		h.Write(content[:50])
+		h.Write(content[50:])
+		Expect(h.ETag()).To(Equal(fmt.Sprintf("%s-10", md5Hex(partSums))))
+	})
+
+	It("accepts the part count of uploads of unknown size", func() {
+		size := 20 * 1024 * 1024 * uint64(1024)
+		_, ok := s3.NewETagHash(md5Hex([]byte("x"))+"-1140", size)
+		Expect(ok).To(BeTrue())
+
+		_, ok = s3.NewETagHash(md5Hex([]byte("x"))+"-1280", size)
+		Expect(ok).To(BeTrue())
+
+		_, ok = s3.NewETagHash(md5Hex([]byte("x"))+"-1200", size)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("computes the ETag of uploads without knowing it ahead", func() {
+		content := []byte("my file contents")
+		h := s3.NewUploadETagHash(uint64(len(content)))
+		h.Write(content)
+		Expect(h.ETag()).To(Equal(md5Hex(content)))
+
+		content = bytes.Repeat([]byte("0123456789abcdef"), int(s3.MultipartThreshold/16)+1024)
+		h = s3.NewUploadETagHash(uint64(len(content)))
+		h.Write(content)
+		Expect(h.ETag()).To(HaveSuffix(fmt.Sprintf("-%d", s3.PlanMultipart(uint64(len(content))).Parts)))
+
+		expected, ok := s3.NewETagHash(h.ETag(), uint64(len(content)))
+		Expect(ok).To(BeTrue())
+		expected.Write(content)
+		Expect(h.ETag()).To(Equal(expected.ETag()))
+	})
+
+	It("can't reproduce ETags that aren't checksums of the content", func() {
+		_, ok := s3.NewETagHash("not-an-md5", 10)
+		Expect(ok).To(BeFalse())
+
+		_, ok = s3.NewETagHash(md5Hex([]byte("x"))+"-3", s3.MultipartThreshold+1)
+		Expect(ok).To(BeFalse())
+	})
+})
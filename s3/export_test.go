@@ -0,0 +1,27 @@
+package s3
+
+import (
+	"crypto/md5"
+	"time"
+)
+
+// NewGrowingETagHash follows the growth of the parts of uploads of unknown
+// size with parts small enough for the tests.
+func NewGrowingETagHash(partSize, partsPerGrowth uint64) *ETagHash {
+	return &ETagHash{
+		partSize:       partSize,
+		multipart:      true,
+		partsPerGrowth: partsPerGrowth,
+		current:        md5.New(),
+	}
+}
+
+// SetResponseHeaderTimeout shortens how long the requests wait for s3 to
+// answer, and returns a function that restores it.
+func SetResponseHeaderTimeout(timeout time.Duration) func() {
+	previous := httpClient
+	httpClient = newHTTPClient(timeout)
+	return func() {
+		httpClient = previous
+	}
+}
@@ -0,0 +1,92 @@
+package s3
+
+import (
+	"sort"
+	"strings"
+
+	goamzs3 "github.com/mitchellh/goamz/s3"
+)
+
+type Inventory struct {
+	Versions    Versions
+	Staging     []Object
+	OrphanParts []Object
+	OrphanPins  []Object
+	Foreign     []Object
+}
+
+func (c *Client) newInventory(backupName string, keys []goamzs3.Key) Inventory {
+	inventory := Inventory{
+		Versions:    Versions{},
+		Staging:     []Object{},
+		OrphanParts: []Object{},
+		OrphanPins:  []Object{},
+		Foreign:     []Object{},
+	}
+
+	pins := map[string][]Object{}
+	parts := map[string][]Object{}
+	for _, key := range keys {
+		object, err := newObject(key)
+		if err != nil {
+			inventory.Foreign = append(inventory.Foreign, Object{Path: key.Key, Size: uint64(key.Size)})
+			continue
+		}
+
+		if !strings.HasPrefix(key.Key, metadataPath(backupName)) {
+			version, err := c.parseVersion(backupName, key)
+			if err != nil {
+				inventory.Foreign = append(inventory.Foreign, object)
+				continue
+			}
+
+			inventory.Versions = append(inventory.Versions, version)
+			continue
+		}
+
+		if version, ok := parsePinPath(backupName, key.Key); ok {
+			pins[version] = append(pins[version], object)
+		} else if version, ok := parsePartPath(backupName, key.Key); ok {
+			parts[version] = append(parts[version], object)
+		} else if strings.HasPrefix(key.Key, stagingPath(backupName)) {
+			inventory.Staging = append(inventory.Staging, object)
+		} else {
+			inventory.Foreign = append(inventory.Foreign, object)
+		}
+	}
+
+	for i, version := range inventory.Versions {
+		if _, ok := pins[version.Version]; ok {
+			inventory.Versions[i].Pinned = true
+		}
+
+		for _, part := range parts[version.Version] {
+			inventory.Versions[i].PartPaths = append(inventory.Versions[i].PartPaths, part.Path)
+		}
+	}
+
+	for _, version := range inventory.Versions {
+		delete(pins, version.Version)
+		delete(parts, version.Version)
+	}
+
+	for _, version := range sortedKeys(parts) {
+		inventory.OrphanParts = append(inventory.OrphanParts, parts[version]...)
+	}
+
+	for _, version := range sortedKeys(pins) {
+		inventory.OrphanPins = append(inventory.OrphanPins, pins[version]...)
+	}
+
+	return inventory
+}
+
+func sortedKeys(objects map[string][]Object) []string {
+	keys := []string{}
+	for key := range objects {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
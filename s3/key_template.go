@@ -0,0 +1,176 @@
+package s3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const DefaultKeyTemplate = "{{.Name}}/{{.ID}}"
+
+var keyTemplateFieldRegexp = regexp.MustCompile("{{\\s*\\.(\\w+)\\s*}}")
+
+var keyTemplateFieldPatterns = map[string]string{
+	"ID":    "(\\d+(?:-[0-9a-f]+)?)",
+	"Ext":   "(?:\\.[^/]*)?",
+	"Year":  "\\d{4}",
+	"Month": "\\d{2}",
+	"Day":   "\\d{2}",
+	"Hour":  "\\d{2}",
+}
+
+var defaultKeyPattern = newKeyPattern(DefaultKeyTemplate)
+
+type KeyTemplate struct {
+	source    string
+	extension string
+	template  *template.Template
+	pattern   keyPattern
+}
+
+type keyPattern struct {
+	regexp     *regexp.Regexp
+	nameGroups []int
+	idGroup    int
+}
+
+type keyFields struct {
+	Name  string
+	ID    string
+	Ext   string
+	Year  string
+	Month string
+	Day   string
+	Hour  string
+}
+
+func NewKeyTemplate(source, extension string) (KeyTemplate, error) {
+	if !strings.HasPrefix(source, "{{.Name}}/") {
+		return KeyTemplate{}, errors.New("Invalid key template. It must start with '{{.Name}}/'")
+	}
+
+	if strings.Count(source, "{{.ID}}") != 1 {
+		return KeyTemplate{}, errors.New("Invalid key template. It must contain '{{.ID}}' exactly once")
+	}
+
+	if extension != "" && !strings.HasPrefix(extension, ".") || strings.Contains(extension, "/") {
+		return KeyTemplate{}, errors.New("Invalid key extension. It must start with '.' and can't contain '/'")
+	}
+
+	withoutFields := keyTemplateFieldRegexp.ReplaceAllString(source, "")
+	if strings.Contains(withoutFields, "{{") || strings.Contains(withoutFields, "}}") {
+		return KeyTemplate{}, errors.New("Invalid key template. Only fields like '{{.Year}}' are supported")
+	}
+
+	for _, field := range keyTemplateFieldRegexp.FindAllStringSubmatch(source, -1) {
+		if _, ok := keyTemplateFieldPatterns[field[1]]; !ok && field[1] != "Name" {
+			message := fmt.Sprintf("Invalid key template. Unknown field '%s'", field[1])
+			return KeyTemplate{}, errors.New(message)
+		}
+	}
+
+	parsedTemplate, err := template.New("key").Parse(source)
+	if err != nil {
+		return KeyTemplate{}, err
+	}
+
+	return KeyTemplate{
+		source:    source,
+		extension: extension,
+		template:  parsedTemplate,
+		pattern:   newKeyPattern(source),
+	}, nil
+}
+
+func (t KeyTemplate) Key(backupName, version string) (string, error) {
+	if t.template == nil {
+		return backupName + "/" + version, nil
+	}
+
+	at := time.Unix(0, VersionIDTimestamp(version)).UTC()
+	fields := keyFields{
+		Name:  backupName,
+		ID:    version,
+		Ext:   t.extension,
+		Year:  at.Format("2006"),
+		Month: at.Format("01"),
+		Day:   at.Format("02"),
+		Hour:  at.Format("15"),
+	}
+
+	var key bytes.Buffer
+	if err := t.template.Execute(&key, fields); err != nil {
+		return "", fmt.Errorf("Failed to render the key of version '%s': %w", version, err)
+	}
+
+	return key.String(), nil
+}
+
+func (t KeyTemplate) ParseKey(backupName, key string) (string, bool) {
+	name, version, ok := t.keyPattern().parse(key)
+	if !ok || name != backupName {
+		return "", false
+	}
+
+	return version, true
+}
+
+func (t KeyTemplate) ParseName(key string) (string, bool) {
+	name, _, ok := t.keyPattern().parse(key)
+	return name, ok
+}
+
+func (t KeyTemplate) keyPattern() keyPattern {
+	if t.pattern.regexp == nil {
+		return defaultKeyPattern
+	}
+
+	return t.pattern
+}
+
+func newKeyPattern(source string) keyPattern {
+	pattern := "^"
+	position := 0
+	keyPattern := keyPattern{}
+	groups := 0
+	for _, field := range keyTemplateFieldRegexp.FindAllStringSubmatchIndex(source, -1) {
+		pattern += regexp.QuoteMeta(source[position:field[0]])
+		switch name := source[field[2]:field[3]]; name {
+		case "Name":
+			groups++
+			keyPattern.nameGroups = append(keyPattern.nameGroups, groups)
+			pattern += "(.+)"
+		case "ID":
+			groups++
+			keyPattern.idGroup = groups
+			pattern += keyTemplateFieldPatterns[name]
+		default:
+			pattern += keyTemplateFieldPatterns[name]
+		}
+		position = field[1]
+	}
+	pattern += regexp.QuoteMeta(source[position:]) + "$"
+
+	keyPattern.regexp = regexp.MustCompile(pattern)
+	return keyPattern
+}
+
+func (p keyPattern) parse(key string) (string, string, bool) {
+	match := p.regexp.FindStringSubmatch(key)
+	if match == nil {
+		return "", "", false
+	}
+
+	name := match[p.nameGroups[0]]
+	for _, group := range p.nameGroups[1:] {
+		if match[group] != name {
+			return "", "", false
+		}
+	}
+
+	return name, match[p.idGroup], true
+}
@@ -0,0 +1,133 @@
+package s3_test
+
+import (
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeyTemplate", func() {
+	const version = "1427571015905296950-9c1f0a2b5e3d7f10"
+
+	Describe("NewKeyTemplate", func() {
+		It("accepts the default template", func() {
+			_, err := s3.NewKeyTemplate(s3.DefaultKeyTemplate, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fails if the template doesn't start with the name", func() {
+			_, err := s3.NewKeyTemplate("backups/{{.Name}}/{{.ID}}", "")
+			Expect(err).To(MatchError("Invalid key template. It must start with '{{.Name}}/'"))
+		})
+
+		It("fails if the template doesn't contain the id", func() {
+			_, err := s3.NewKeyTemplate("{{.Name}}/{{.Year}}", "")
+			Expect(err).To(MatchError("Invalid key template. It must contain '{{.ID}}' exactly once"))
+		})
+
+		It("fails if the template contains an unknown field", func() {
+			_, err := s3.NewKeyTemplate("{{.Name}}/{{.Week}}/{{.ID}}", "")
+			Expect(err).To(MatchError("Invalid key template. Unknown field 'Week'"))
+		})
+
+		It("fails if the template contains other template actions", func() {
+			_, err := s3.NewKeyTemplate("{{.Name}}/{{if .Ext}}x{{end}}/{{.ID}}", "")
+			Expect(err).To(MatchError("Invalid key template. Only fields like '{{.Year}}' are supported"))
+		})
+
+		It("fails if the extension is invalid", func() {
+			_, err := s3.NewKeyTemplate(s3.DefaultKeyTemplate, "sql/gz")
+			Expect(err).To(MatchError("Invalid key extension. It must start with '.' and can't contain '/'"))
+		})
+	})
+
+	Describe("#Key", func() {
+		It("uses the default layout for the zero value", func() {
+			Expect(s3.KeyTemplate{}.Key("my/backup", version)).To(Equal("my/backup/" + version))
+		})
+
+		It("renders the fields, using the version timestamp in UTC", func() {
+			keyTemplate, err := s3.NewKeyTemplate("{{.Name}}/{{.Year}}/{{.Month}}/{{.Day}}/{{.Hour}}/{{.ID}}{{.Ext}}", ".sql.gz")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(keyTemplate.Key("my/backup", version)).To(Equal("my/backup/2015/03/28/19/" + version + ".sql.gz"))
+		})
+	})
+
+	Describe("#ParseKey", func() {
+		It("returns the version of keys rendered by the template", func() {
+			keyTemplate, err := s3.NewKeyTemplate("{{.Name}}/{{.Year}}/{{.Month}}/{{.Day}}/{{.ID}}{{.Ext}}", ".sql.gz")
+			Expect(err).ToNot(HaveOccurred())
+
+			key, err := keyTemplate.Key("my/backup", version)
+			Expect(err).ToNot(HaveOccurred())
+
+			parsed, ok := keyTemplate.ParseKey("my/backup", key)
+			Expect(ok).To(BeTrue())
+			Expect(parsed).To(Equal(version))
+		})
+
+		It("accepts any extension", func() {
+			keyTemplate, err := s3.NewKeyTemplate("{{.Name}}/{{.ID}}{{.Ext}}", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			parsed, ok := keyTemplate.ParseKey("my/backup", "my/backup/"+version+".tar.bz2")
+			Expect(ok).To(BeTrue())
+			Expect(parsed).To(Equal(version))
+		})
+
+		It("rejects keys of other backups", func() {
+			keyTemplate, err := s3.NewKeyTemplate("{{.Name}}/{{.Year}}/{{.ID}}", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, ok := keyTemplate.ParseKey("my", "my/backup/"+version)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("rejects keys that don't follow the template", func() {
+			keyTemplate, err := s3.NewKeyTemplate("{{.Name}}/{{.Year}}/{{.ID}}", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, ok := keyTemplate.ParseKey("my/backup", "my/backup/README.md")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("requires the same name wherever the template repeats it", func() {
+			keyTemplate, err := s3.NewKeyTemplate("{{.Name}}/{{.ID}}-{{.Name}}", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			parsed, ok := keyTemplate.ParseKey("db", "db/"+version+"-db")
+			Expect(ok).To(BeTrue())
+			Expect(parsed).To(Equal(version))
+
+			_, ok = keyTemplate.ParseKey("db", "db/"+version+"-other")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("#ParseName", func() {
+		It("returns the backup name of keys rendered by the template", func() {
+			keyTemplate, err := s3.NewKeyTemplate("{{.Name}}/{{.Year}}/{{.Month}}/{{.Day}}/{{.ID}}{{.Ext}}", ".sql.gz")
+			Expect(err).ToNot(HaveOccurred())
+
+			key, err := keyTemplate.Key("my/backup", version)
+			Expect(err).ToNot(HaveOccurred())
+
+			name, ok := keyTemplate.ParseName(key)
+			Expect(ok).To(BeTrue())
+			Expect(name).To(Equal("my/backup"))
+		})
+
+		It("uses the default template", func() {
+			name, ok := s3.KeyTemplate{}.ParseName("my/backup/" + version)
+			Expect(ok).To(BeTrue())
+			Expect(name).To(Equal("my/backup"))
+		})
+
+		It("rejects keys that don't follow the template", func() {
+			_, ok := s3.KeyTemplate{}.ParseName("my/backup/README.md")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
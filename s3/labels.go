@@ -0,0 +1,80 @@
+package s3
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	metaHeaderPrefix = "X-Amz-Meta-"
+	labelsMeta       = "S3kup-Labels"
+	labelsHeader     = metaHeaderPrefix + labelsMeta
+)
+
+var labelKeyRegexp = regexp.MustCompile("^[a-zA-Z0-9_.-]+$")
+
+type Labels map[string]string
+
+func ParseLabels(labels []string) (Labels, error) {
+	parsedLabels := Labels{}
+	for _, label := range labels {
+		parts := strings.SplitN(label, "=", 2)
+		if len(parts) != 2 || !labelKeyRegexp.MatchString(parts[0]) {
+			return nil, errors.New("Invalid label '" + label + "'. It must be in the format key=value")
+		}
+
+		parsedLabels[parts[0]] = parts[1]
+	}
+
+	return parsedLabels, nil
+}
+
+func (l Labels) Match(selector Labels) bool {
+	for key, value := range selector {
+		if labelValue, ok := l[key]; !ok || labelValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (l Labels) Equal(other Labels) bool {
+	return len(l) == len(other) && l.Match(other)
+}
+
+func (l Labels) String() string {
+	labels := []string{}
+	for key, value := range l {
+		labels = append(labels, key+"="+value)
+	}
+
+	sort.Strings(labels)
+	return strings.Join(labels, ",")
+}
+
+func (l Labels) encode() string {
+	values := url.Values{}
+	for key, value := range l {
+		values.Set(key, value)
+	}
+
+	return values.Encode()
+}
+
+func decodeLabels(encoded string) (Labels, error) {
+	values, err := url.ParseQuery(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := Labels{}
+	for key := range values {
+		labels[key] = values.Get(key)
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,50 @@
+package s3_test
+
+import (
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Labels", func() {
+	Describe("ParseLabels", func() {
+		It("parses key=value pairs", func() {
+			labels, err := s3.ParseLabels([]string{"kind=pre-deploy", "query=a=b", "empty="})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labels).To(Equal(s3.Labels{"kind": "pre-deploy", "query": "a=b", "empty": ""}))
+		})
+
+		It("fails for malformed labels", func() {
+			for _, label := range []string{"kind", "=nightly", "my kind=nightly"} {
+				_, err := s3.ParseLabels([]string{label})
+				Expect(err).To(MatchError("Invalid label '" + label + "'. It must be in the format key=value"))
+			}
+		})
+	})
+
+	Describe("#Match", func() {
+		labels := s3.Labels{"kind": "nightly", "env": "prod"}
+
+		It("matches when all the selector labels are present", func() {
+			Expect(labels.Match(s3.Labels{"kind": "nightly"})).To(BeTrue())
+			Expect(labels.Match(s3.Labels{})).To(BeTrue())
+			Expect(labels.Match(s3.Labels{"kind": "manual"})).To(BeFalse())
+			Expect(labels.Match(s3.Labels{"region": "eu"})).To(BeFalse())
+		})
+	})
+
+	Describe("#Equal", func() {
+		It("requires the same labels", func() {
+			Expect(s3.Labels{"kind": "nightly"}.Equal(s3.Labels{"kind": "nightly"})).To(BeTrue())
+			Expect(s3.Labels{}.Equal(nil)).To(BeTrue())
+			Expect(s3.Labels{"kind": "nightly"}.Equal(s3.Labels{"kind": "nightly", "env": "prod"})).To(BeFalse())
+		})
+	})
+
+	Describe("#String", func() {
+		It("joins the labels sorted by key", func() {
+			Expect(s3.Labels{"kind": "nightly", "env": "prod"}.String()).To(Equal("env=prod,kind=nightly"))
+		})
+	})
+})
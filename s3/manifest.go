@@ -0,0 +1,61 @@
+package s3
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const manifestFormat = "s3kup-bundle/1"
+
+var partNameRegexp = regexp.MustCompile("^[a-zA-Z0-9_.-]+$")
+
+type Manifest struct {
+	Format string         `json:"format"`
+	Parts  []ManifestPart `json:"parts"`
+}
+
+type ManifestPart struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size uint64 `json:"size"`
+}
+
+func NewManifest(parts []ManifestPart) Manifest {
+	return Manifest{
+		Format: manifestFormat,
+		Parts:  parts,
+	}
+}
+
+func ParseManifest(content []byte) (Manifest, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil || manifest.Format != manifestFormat {
+		return Manifest{}, errors.New("The version is not a bundle")
+	}
+
+	return manifest, nil
+}
+
+func ValidPartName(name string) bool {
+	return partNameRegexp.MatchString(name)
+}
+
+func (m Manifest) Encode() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+func (m Manifest) Part(name string) (ManifestPart, error) {
+	names := []string{}
+	for _, part := range m.Parts {
+		if part.Name == name {
+			return part, nil
+		}
+		names = append(names, part.Name)
+	}
+
+	sort.Strings(names)
+	return ManifestPart{}, &NotFoundError{Message: "The bundle has no part '" + name + "'. Its parts are: " + strings.Join(names, ", ")}
+}
@@ -0,0 +1,48 @@
+package s3_test
+
+import (
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manifest", func() {
+	var manifest s3.Manifest
+
+	BeforeEach(func() {
+		manifest = s3.NewManifest([]s3.ManifestPart{
+			{Name: "db", Path: "my/backup/.s3kup/parts/1/db", Size: 10},
+			{Name: "uploads", Path: "my/backup/.s3kup/parts/1/uploads", Size: 20},
+		})
+	})
+
+	It("can be parsed back after encoded", func() {
+		content, err := manifest.Encode()
+		Expect(err).ToNot(HaveOccurred())
+
+		parsedManifest, err := s3.ParseManifest(content)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsedManifest).To(Equal(manifest))
+	})
+
+	It("fails to parse content that is not a manifest", func() {
+		for _, content := range []string{"pg_dump output", `{"parts": []}`} {
+			_, err := s3.ParseManifest([]byte(content))
+			Expect(err).To(MatchError("The version is not a bundle"))
+		}
+	})
+
+	Describe("#Part", func() {
+		It("finds the part by name", func() {
+			part, err := manifest.Part("uploads")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(part.Path).To(Equal("my/backup/.s3kup/parts/1/uploads"))
+		})
+
+		It("fails for unknown parts", func() {
+			_, err := manifest.Part("config")
+			Expect(err).To(MatchError("The bundle has no part 'config'. Its parts are: db, uploads"))
+		})
+	})
+})
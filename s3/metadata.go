@@ -0,0 +1,61 @@
+package s3
+
+import "strings"
+
+const metadataDir = ".s3kup"
+
+func PinPath(backupName string, version string) string {
+	return metadataPath(backupName) + "pins/" + version
+}
+
+func StagingPath(backupName, version string) string {
+	return stagingPath(backupName) + version
+}
+
+func PartPath(backupName, version, part string) string {
+	return partsPath(backupName) + version + "/" + part
+}
+
+func metadataPath(backupName string) string {
+	return backupName + "/" + metadataDir + "/"
+}
+
+func parseMetadataName(path string) (string, bool) {
+	index := strings.Index(path, "/"+metadataDir+"/")
+	if index <= 0 {
+		return "", false
+	}
+
+	return path[:index], true
+}
+
+func parsePinPath(backupName, path string) (string, bool) {
+	pinsPath := metadataPath(backupName) + "pins/"
+	if !strings.HasPrefix(path, pinsPath) {
+		return "", false
+	}
+
+	version := strings.TrimPrefix(path, pinsPath)
+	return version, ValidVersionID(version)
+}
+
+func stagingPath(backupName string) string {
+	return metadataPath(backupName) + "staging/"
+}
+
+func partsPath(backupName string) string {
+	return metadataPath(backupName) + "parts/"
+}
+
+func parsePartPath(backupName, path string) (string, bool) {
+	if !strings.HasPrefix(path, partsPath(backupName)) {
+		return "", false
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(path, partsPath(backupName)), "/", 2)
+	if len(parts) != 2 || !ValidVersionID(parts[0]) || !ValidPartName(parts[1]) {
+		return "", false
+	}
+
+	return parts[0], true
+}
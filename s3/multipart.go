@@ -0,0 +1,63 @@
+package s3
+
+const (
+	MultipartThreshold uint64 = 64 * 1024 * 1024
+	MinPartSize        uint64 = 16 * 1024 * 1024
+	MaxParts           uint64 = 10000
+	MaxCopySize        uint64 = 5 * 1024 * 1024 * 1024
+	PartsPerGrowth     uint64 = 1000
+)
+
+type MultipartPlan struct {
+	PartSize uint64
+	Parts    int
+}
+
+func PlanMultipart(size uint64) MultipartPlan {
+	if size <= MultipartThreshold {
+		return MultipartPlan{PartSize: size, Parts: 1}
+	}
+
+	partSize := MinPartSize
+	if (size+MaxParts-1)/MaxParts > partSize {
+		const mebibyte = 1024 * 1024
+		partSize = ((size+MaxParts-1)/MaxParts + mebibyte - 1) / mebibyte * mebibyte
+	}
+
+	return MultipartPlan{
+		PartSize: partSize,
+		Parts:    int((size + partSize - 1) / partSize),
+	}
+}
+
+// GrowingParts counts the parts of an upload of unknown size, which starts
+// with parts of MinPartSize and doubles them every PartsPerGrowth parts.
+func GrowingParts(size uint64) int {
+	parts := 0
+	for partSize := MinPartSize; ; partSize *= 2 {
+		if size <= partSize*PartsPerGrowth {
+			return parts + int((size+partSize-1)/partSize)
+		}
+
+		parts += int(PartsPerGrowth)
+		size -= partSize * PartsPerGrowth
+	}
+}
+
+func PlanCopy(size uint64) MultipartPlan {
+	if size <= MaxCopySize {
+		return MultipartPlan{PartSize: size, Parts: 1}
+	}
+
+	return PlanMultipart(size)
+}
+
+func (p MultipartPlan) Bounds(n int, size uint64) (uint64, uint64) {
+	start := uint64(n) * p.PartSize
+	end := start + p.PartSize
+	if end > size {
+		end = size
+	}
+
+	return start, end
+}
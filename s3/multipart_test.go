@@ -0,0 +1,69 @@
+package s3_test
+
+import (
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multipart", func() {
+	const mebibyte uint64 = 1024 * 1024
+
+	Describe("PlanMultipart", func() {
+		It("uses a single upload up to the threshold", func() {
+			Expect(s3.PlanMultipart(0)).To(Equal(s3.MultipartPlan{PartSize: 0, Parts: 1}))
+			Expect(s3.PlanMultipart(s3.MultipartThreshold)).To(Equal(s3.MultipartPlan{PartSize: s3.MultipartThreshold, Parts: 1}))
+		})
+
+		It("splits bigger uploads in parts of the minimum size", func() {
+			Expect(s3.PlanMultipart(s3.MultipartThreshold + 1)).To(Equal(s3.MultipartPlan{PartSize: s3.MinPartSize, Parts: 5}))
+			Expect(s3.PlanMultipart(100 * s3.MinPartSize)).To(Equal(s3.MultipartPlan{PartSize: s3.MinPartSize, Parts: 100}))
+		})
+
+		It("grows the parts so they never exceed the maximum number of parts", func() {
+			size := 200 * 1024 * mebibyte
+			plan := s3.PlanMultipart(size)
+			Expect(plan.PartSize).To(Equal(21 * mebibyte))
+			Expect(uint64(plan.Parts)).To(BeNumerically("<=", s3.MaxParts))
+			Expect(uint64(plan.Parts) * plan.PartSize).To(BeNumerically(">=", size))
+		})
+	})
+
+	Describe("GrowingParts", func() {
+		It("counts parts of the minimum size up to the growth", func() {
+			Expect(s3.GrowingParts(s3.MultipartThreshold + 1)).To(Equal(5))
+			Expect(s3.GrowingParts(s3.PartsPerGrowth * s3.MinPartSize)).To(Equal(int(s3.PartsPerGrowth)))
+		})
+
+		It("doubles the parts every PartsPerGrowth parts", func() {
+			Expect(s3.GrowingParts(s3.PartsPerGrowth*s3.MinPartSize + 1)).To(Equal(int(s3.PartsPerGrowth) + 1))
+			Expect(s3.GrowingParts(20 * 1024 * mebibyte)).To(Equal(1140))
+			Expect(s3.GrowingParts(3*s3.PartsPerGrowth*s3.MinPartSize + 4*s3.MinPartSize)).To(Equal(2*int(s3.PartsPerGrowth) + 1))
+		})
+	})
+
+	Describe("PlanCopy", func() {
+		It("uses a single copy up to the copy limit", func() {
+			Expect(s3.PlanCopy(s3.MaxCopySize)).To(Equal(s3.MultipartPlan{PartSize: s3.MaxCopySize, Parts: 1}))
+		})
+
+		It("copies bigger objects in the same parts as an upload", func() {
+			Expect(s3.PlanCopy(s3.MaxCopySize + 1)).To(Equal(s3.PlanMultipart(s3.MaxCopySize + 1)))
+			Expect(s3.PlanCopy(s3.MaxCopySize + 1)).To(Equal(s3.MultipartPlan{PartSize: s3.MinPartSize, Parts: 321}))
+		})
+	})
+
+	Describe("Bounds", func() {
+		It("returns the byte range of each part, with a shorter last part", func() {
+			size := s3.MaxCopySize + 1
+			plan := s3.PlanCopy(size)
+
+			start, end := plan.Bounds(0, size)
+			Expect([]uint64{start, end}).To(Equal([]uint64{0, s3.MinPartSize}))
+
+			start, end = plan.Bounds(plan.Parts-1, size)
+			Expect([]uint64{start, end}).To(Equal([]uint64{s3.MaxCopySize, size}))
+		})
+	})
+})
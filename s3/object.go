@@ -0,0 +1,35 @@
+package s3
+
+import (
+	"errors"
+	"time"
+
+	goamzs3 "github.com/mitchellh/goamz/s3"
+)
+
+type Object struct {
+	Path         string
+	LastModified time.Time
+	Size         uint64
+}
+
+// Upload is a multipart upload that was started and never completed nor
+// aborted. S3 keeps charging for its parts until it's aborted.
+type Upload struct {
+	Path      string
+	UploadID  string
+	Initiated time.Time
+}
+
+func newObject(key goamzs3.Key) (Object, error) {
+	lastModified, err := time.Parse(time.RFC3339, key.LastModified)
+	if err != nil {
+		return Object{}, errors.New("Failed to parse the object timestamp. '" + key.LastModified + "' was not recognized")
+	}
+
+	return Object{
+		Path:         key.Key,
+		LastModified: lastModified,
+		Size:         uint64(key.Size),
+	}, nil
+}
@@ -0,0 +1,82 @@
+package s3
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var checksumHeaders = []string{"crc32", "crc32c", "crc64nvme", "sha1", "sha256"}
+
+type ObjectInfo struct {
+	Path            string
+	Size            uint64
+	LastModified    time.Time
+	ETag            string
+	StorageClass    string
+	ContentType     string
+	Encryption      string
+	KMSKeyID        string
+	LockMode        string
+	LockRetainUntil time.Time
+	LegalHold       bool
+	Checksum        string
+	TagCount        int
+	Tags            map[string]string
+	Metadata        map[string]string
+	Labels          Labels
+	Attributes      Attributes
+}
+
+func newObjectInfo(path string, resp *http.Response) ObjectInfo {
+	header := resp.Header
+	info := ObjectInfo{
+		Path:         path,
+		Size:         uint64(resp.ContentLength),
+		ETag:         strings.Trim(header.Get("ETag"), `"`),
+		StorageClass: header.Get("X-Amz-Storage-Class"),
+		ContentType:  header.Get("Content-Type"),
+		Encryption:   header.Get("X-Amz-Server-Side-Encryption"),
+		KMSKeyID:     header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"),
+		LockMode:     header.Get("X-Amz-Object-Lock-Mode"),
+		LegalHold:    header.Get("X-Amz-Object-Lock-Legal-Hold") == "ON",
+		Metadata:     map[string]string{},
+		Attributes:   decodeAttributes(header),
+	}
+
+	if info.StorageClass == "" {
+		info.StorageClass = "STANDARD"
+	}
+
+	if lastModified, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		info.LastModified = lastModified
+	}
+
+	if retainUntil, err := time.Parse(time.RFC3339, header.Get("X-Amz-Object-Lock-Retain-Until-Date")); err == nil {
+		info.LockRetainUntil = retainUntil
+	}
+
+	if tagCount, err := strconv.Atoi(header.Get("X-Amz-Tagging-Count")); err == nil {
+		info.TagCount = tagCount
+	}
+
+	for _, algorithm := range checksumHeaders {
+		if checksum := header.Get("X-Amz-Checksum-" + algorithm); checksum != "" {
+			info.Checksum = algorithm + ":" + checksum
+			break
+		}
+	}
+	if info.Checksum == "" && info.KMSKeyID == "" && info.ETag != "" && !strings.Contains(info.ETag, "-") {
+		info.Checksum = "md5:" + info.ETag
+	}
+
+	for key := range header {
+		if strings.HasPrefix(key, metaHeaderPrefix) {
+			info.Metadata[strings.ToLower(strings.TrimPrefix(key, metaHeaderPrefix))] = header.Get(key)
+		}
+	}
+
+	info.Labels, _ = decodeLabels(header.Get(labelsHeader))
+	return info
+}
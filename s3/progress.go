@@ -0,0 +1,81 @@
+package s3
+
+import "io"
+
+type Progress interface {
+	Expect(bytes int64)
+	Transferred(bytes int64)
+	PartStarted()
+	PartFinished()
+}
+
+type noProgress struct{}
+
+func (noProgress) Expect(bytes int64)      {}
+func (noProgress) Transferred(bytes int64) {}
+func (noProgress) PartStarted()            {}
+func (noProgress) PartFinished()           {}
+
+type progressReader struct {
+	reader   io.ReadSeeker
+	progress Progress
+	position int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.position += int64(n)
+	r.progress.Transferred(int64(n))
+	return n, err
+}
+
+func (r *progressReader) Seek(offset int64, whence int) (int64, error) {
+	position, err := r.reader.Seek(offset, whence)
+	if err != nil {
+		return position, err
+	}
+
+	r.progress.Transferred(position - r.position)
+	r.position = position
+	return position, nil
+}
+
+type progressBody struct {
+	body     io.ReadCloser
+	progress Progress
+	size     int64
+	read     int64
+	closed   bool
+}
+
+func newProgressBody(body io.ReadCloser, size int64, progress Progress) *progressBody {
+	if size >= 0 {
+		progress.Expect(size)
+	}
+	progress.PartStarted()
+
+	return &progressBody{
+		body:     body,
+		progress: progress,
+		size:     size,
+	}
+}
+
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	b.progress.Transferred(int64(n))
+	return n, err
+}
+
+func (b *progressBody) Close() error {
+	if !b.closed {
+		b.closed = true
+		if b.size > b.read {
+			b.progress.Expect(b.read - b.size)
+		}
+		b.progress.PartFinished()
+	}
+
+	return b.body.Close()
+}
@@ -0,0 +1,145 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	goamzs3 "github.com/mitchellh/goamz/s3"
+)
+
+// signedSubresources are the query parameters that are part of the signed
+// resource. Others, like the prefix of a listing, are left out of it.
+var signedSubresources = map[string]bool{
+	"partNumber": true,
+	"tagging":    true,
+	"uploadId":   true,
+	"uploads":    true,
+}
+
+// request signs and sends the S3 calls goamz doesn't implement, the same way
+// goamz signs its own.
+func (c *Client) request(method, path string, query url.Values, headers http.Header, body []byte) ([]byte, error) {
+	endpoint, err := url.Parse(c.s3.S3Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Path = "/" + c.bucket.Name + "/" + path
+	endpoint.RawQuery = encodeQuery(query, url.QueryEscape)
+
+	req, err := http.NewRequest(method, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range headers {
+		req.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("Authorization", "AWS "+c.s3.AccessKey+":"+c.signature(req, endpoint.EscapedPath(), query))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := responseError(resp, content); err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+func (c *Client) signature(req *http.Request, resource string, query url.Values) string {
+	amzHeaders := []string{}
+	for key, values := range req.Header {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, "x-amz-") {
+			amzHeaders = append(amzHeaders, key+":"+strings.Join(values, ","))
+		}
+	}
+	sort.Strings(amzHeaders)
+
+	signed := url.Values{}
+	for key, values := range query {
+		if signedSubresources[key] {
+			signed[key] = values
+		}
+	}
+
+	if subresources := encodeQuery(signed, func(value string) string { return value }); subresources != "" {
+		resource += "?" + subresources
+	}
+
+	payload := req.Method + "\n" +
+		req.Header.Get("Content-MD5") + "\n" +
+		req.Header.Get("Content-Type") + "\n" +
+		req.Header.Get("Date") + "\n"
+	for _, header := range amzHeaders {
+		payload += header + "\n"
+	}
+	payload += resource
+
+	mac := hmac.New(sha1.New, []byte(c.s3.SecretKey))
+	mac.Write([]byte(payload))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encodeQuery leaves out the '=' of subresources without a value, like
+// '?uploads', as S3 expects them.
+func encodeQuery(query url.Values, escape func(string) string) string {
+	parameters := []string{}
+	for key, values := range query {
+		for _, value := range values {
+			if value == "" {
+				parameters = append(parameters, escape(key))
+			} else {
+				parameters = append(parameters, escape(key)+"="+escape(value))
+			}
+		}
+	}
+	sort.Strings(parameters)
+
+	return strings.Join(parameters, "&")
+}
+
+// responseError also catches the errors S3 sends with a 200 status in the
+// middle of long copies.
+func responseError(resp *http.Response, content []byte) error {
+	s3Err := &goamzs3.Error{StatusCode: resp.StatusCode}
+	if resp.StatusCode < 300 {
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil
+			}
+			if element, ok := token.(xml.StartElement); ok {
+				if element.Name.Local != "Error" {
+					return nil
+				}
+				s3Err.StatusCode = http.StatusInternalServerError
+				break
+			}
+		}
+	}
+
+	xml.Unmarshal(content, s3Err)
+	if s3Err.Message == "" {
+		s3Err.Message = resp.Status
+	}
+	return s3Err
+}
@@ -3,7 +3,6 @@ package s3
 import (
 	"errors"
 	"regexp"
-	"strconv"
 	"time"
 
 	goamzs3 "github.com/mitchellh/goamz/s3"
@@ -12,26 +11,25 @@ import (
 type Version struct {
 	Path         string
 	BackupName   string
-	Version      int64
+	Version      string
 	LastModified time.Time
 	Size         uint64
+	Pinned       bool
+	Labels       Labels
+	PartPaths    []string
 }
 
 func NewVersion(key goamzs3.Key) (Version, error) {
-	backupNameRegexp, _ := regexp.Compile("^(.*)/\\d+$")
-	backupName := backupNameRegexp.FindStringSubmatch(key.Key)
-
-	versionRegexp, _ := regexp.Compile("^.*/(\\d+)$")
+	versionRegexp, _ := regexp.Compile("^(.*)/([^/]+)$")
 	versionStr := versionRegexp.FindStringSubmatch(key.Key)
-	if len(versionStr) < 2 {
+	if len(versionStr) < 3 || !ValidVersionID(versionStr[2]) {
 		return Version{}, errors.New("Remote version '" + key.Key + "' can't be parsed")
 	}
 
-	versionInt, err := strconv.ParseInt(versionStr[1], 10, 64)
-	if err != nil {
-		return Version{}, errors.New("Remote version '" + versionStr[1] + "' can't be parsed")
-	}
+	return newVersion(key, versionStr[1], versionStr[2])
+}
 
+func newVersion(key goamzs3.Key, backupName, version string) (Version, error) {
 	lastModified, err := time.Parse(time.RFC3339, key.LastModified)
 	if err != nil {
 		return Version{}, errors.New("Failed to parse the version timestamp. '" + key.LastModified + "' was not recognized")
@@ -39,8 +37,8 @@ func NewVersion(key goamzs3.Key) (Version, error) {
 
 	return Version{
 		Path:         key.Key,
-		BackupName:   backupName[1],
-		Version:      versionInt,
+		BackupName:   backupName,
+		Version:      version,
 		LastModified: lastModified,
 		Size:         uint64(key.Size),
 	}, nil
@@ -0,0 +1,83 @@
+package s3
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var versionIDRegexp = regexp.MustCompile("^(\\d+)(?:-([0-9a-f]+))?$")
+
+func NewVersionID(now time.Time, previousVersions Versions) string {
+	timestamp := now.UnixNano()
+	for _, version := range previousVersions {
+		if previous := VersionIDTimestamp(version.Version); previous >= timestamp {
+			timestamp = previous + 1
+		}
+	}
+
+	return fmt.Sprintf("%019d-%s%s", timestamp, hostComponent(), randomComponent())
+}
+
+func ValidVersionID(id string) bool {
+	return versionIDRegexp.MatchString(id)
+}
+
+func VersionIDTimestamp(id string) int64 {
+	match := versionIDRegexp.FindStringSubmatch(id)
+	if match == nil {
+		return 0
+	}
+
+	timestamp, _ := strconv.ParseInt(match[1], 10, 64)
+	return timestamp
+}
+
+func compareVersionIDs(a, b string) int {
+	matchA := versionIDRegexp.FindStringSubmatch(a)
+	matchB := versionIDRegexp.FindStringSubmatch(b)
+	if matchA == nil || matchB == nil {
+		return strings.Compare(a, b)
+	}
+
+	timestampA := strings.TrimLeft(matchA[1], "0")
+	timestampB := strings.TrimLeft(matchB[1], "0")
+	if len(timestampA) != len(timestampB) {
+		if len(timestampA) < len(timestampB) {
+			return -1
+		}
+		return 1
+	}
+
+	if result := strings.Compare(timestampA, timestampB); result != 0 {
+		return result
+	}
+
+	return strings.Compare(matchA[2], matchB[2])
+}
+
+func hostComponent() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(hostname))
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+func randomComponent() string {
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return fmt.Sprintf("%08x", time.Now().Nanosecond())
+	}
+
+	return hex.EncodeToString(random)
+}
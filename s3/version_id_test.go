@@ -0,0 +1,63 @@
+package s3_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tscolari/s3kup/s3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VersionID", func() {
+	Describe("NewVersionID", func() {
+		now := time.Unix(1427571015, 905296950)
+
+		It("uses the timestamp followed by a host and random suffix", func() {
+			id := s3.NewVersionID(now, s3.Versions{})
+			Expect(id).To(MatchRegexp("^1427571015905296950-[0-9a-f]{16}$"))
+		})
+
+		It("never repeats an id", func() {
+			Expect(s3.NewVersionID(now, s3.Versions{})).ToNot(Equal(s3.NewVersionID(now, s3.Versions{})))
+		})
+
+		It("is always newer than the previous versions, even if the local clock is behind", func() {
+			previousVersions := s3.Versions{
+				s3.Version{Version: "1427571015905296999-9c1f0a2b5e3d7f10"},
+				s3.Version{Version: "1427571015905297000"},
+				s3.Version{Version: "1427554100187348642"},
+			}
+
+			id := s3.NewVersionID(now, previousVersions)
+			Expect(id).To(HavePrefix("1427571015905297001-"))
+		})
+	})
+
+	Describe("ValidVersionID", func() {
+		for _, id := range []string{"0", "1427571015905296950", "1427571015905296950-9c1f0a2b5e3d7f10"} {
+			id := id
+			It(fmt.Sprintf("accepts '%s'", id), func() {
+				Expect(s3.ValidVersionID(id)).To(BeTrue())
+			})
+		}
+
+		for _, id := range []string{"", "latest", "-1", "1427571015905296950-", "1427571015905296950-XYZ", "12/34"} {
+			id := id
+			It(fmt.Sprintf("rejects '%s'", id), func() {
+				Expect(s3.ValidVersionID(id)).To(BeFalse())
+			})
+		}
+	})
+
+	Describe("VersionIDTimestamp", func() {
+		It("returns the timestamp of old numeric versions", func() {
+			Expect(s3.VersionIDTimestamp("1427571015905296950")).To(Equal(int64(1427571015905296950)))
+		})
+
+		It("returns the timestamp of suffixed versions", func() {
+			Expect(s3.VersionIDTimestamp("1427571015905296950-9c1f0a2b5e3d7f10")).To(Equal(int64(1427571015905296950)))
+		})
+	})
+})
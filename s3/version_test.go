@@ -26,13 +26,27 @@ var _ = Describe("Version", func() {
 
 			Expect(version.Path).To(Equal("b/a1e53e1d-9b01-cbb99505ac78/0"))
 			Expect(version.BackupName).To(Equal("b/a1e53e1d-9b01-cbb99505ac78"))
-			Expect(version.Version).To(Equal(int64(0)))
+			Expect(version.Version).To(Equal("0"))
 			parsedTime, err := time.Parse(time.RFC3339, "2015-03-29T11:54:42.819+01:00")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(version.LastModified).To(Equal(parsedTime))
 			Expect(version.Size).To(Equal(uint64(4)))
 		})
 
+		It("converts a goamz Key with a host and random suffix to a version", func() {
+			key := goamzs3.Key{
+				Key:          "b/my-bkp.gz/1427571015905296950-9c1f0a2b5e3d7f10",
+				LastModified: "2015-03-29T11:54:42.819+01:00",
+				Size:         4,
+			}
+
+			version, err := s3.NewVersion(key)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(version.BackupName).To(Equal("b/my-bkp.gz"))
+			Expect(version.Version).To(Equal("1427571015905296950-9c1f0a2b5e3d7f10"))
+		})
+
 		It("returns an error if version number is in a wrong format", func() {
 			key := goamzs3.Key{
 				Key:          "b/my-bkp.gz/i-am-wrong",
@@ -3,7 +3,7 @@ package s3
 type Versions []Version
 
 func (v Versions) Less(i, j int) bool {
-	return v[i].LastModified.UnixNano() < v[j].LastModified.UnixNano()
+	return compareVersionIDs(v[i].Version, v[j].Version) < 0
 }
 
 func (v Versions) Len() int {
@@ -15,3 +15,25 @@ func (v Versions) Swap(i, j int) {
 	v[i] = v[j]
 	v[j] = temp
 }
+
+func (v Versions) Unpinned() Versions {
+	unpinned := Versions{}
+	for _, version := range v {
+		if !version.Pinned {
+			unpinned = append(unpinned, version)
+		}
+	}
+
+	return unpinned
+}
+
+func (v Versions) Matching(selector Labels) Versions {
+	matching := Versions{}
+	for _, version := range v {
+		if version.Labels.Match(selector) {
+			matching = append(matching, version)
+		}
+	}
+
+	return matching
+}
@@ -12,21 +12,21 @@ import (
 
 var _ = Describe("Versions", func() {
 
-	createVersion := func(number int, date string) s3.Version {
+	createVersion := func(version string, date string) s3.Version {
 		lastModified, err := time.Parse(time.RFC3339, date)
 		Expect(err).ToNot(HaveOccurred())
 
 		return s3.Version{
-			Version:      int64(number),
+			Version:      version,
 			LastModified: lastModified,
 		}
 	}
 
-	It("responds to the sort interface, sorting by LastModified field", func() {
-		version1 := createVersion(1, "2019-03-29T11:54:42.819+01:00")
-		version10 := createVersion(10, "2020-03-29T11:54:42.819+01:00")
-		version50 := createVersion(50, "2018-03-29T11:54:42.819+01:00")
-		version100 := createVersion(100, "2017-03-29T11:54:42.819+01:00")
+	It("responds to the sort interface, sorting by the version ids", func() {
+		version1 := createVersion("1", "2019-03-29T11:54:42.819+01:00")
+		version10 := createVersion("10", "2020-03-29T11:54:42.819+01:00")
+		version50 := createVersion("50", "2018-03-29T11:54:42.819+01:00")
+		version100 := createVersion("100", "2017-03-29T11:54:42.819+01:00")
 
 		versions := s3.Versions{
 			version50,
@@ -38,10 +38,39 @@ var _ = Describe("Versions", func() {
 		sort.Sort(versions)
 
 		Expect(versions).To(Equal(s3.Versions{
-			version100,
-			version50,
 			version1,
 			version10,
+			version50,
+			version100,
+		}))
+	})
+
+	It("sorts old numeric versions and suffixed versions together", func() {
+		legacy := createVersion("1427571015905296950", "2020-03-29T11:54:42.819+01:00")
+		sameTimeHostA := createVersion("1427571015905296950-0c1f0a2b5e3d7f10", "2019-03-29T11:54:42.819+01:00")
+		sameTimeHostB := createVersion("1427571015905296950-9c1f0a2b5e3d7f10", "2018-03-29T11:54:42.819+01:00")
+		older := createVersion("1427554100187348642-9c1f0a2b5e3d7f10", "2021-03-29T11:54:42.819+01:00")
+
+		versions := s3.Versions{sameTimeHostB, legacy, older, sameTimeHostA}
+		sort.Sort(versions)
+
+		Expect(versions).To(Equal(s3.Versions{
+			older,
+			legacy,
+			sameTimeHostA,
+			sameTimeHostB,
 		}))
 	})
+
+	Describe("#Unpinned", func() {
+		It("returns only the versions that are not pinned", func() {
+			version1 := createVersion("1", "2019-03-29T11:54:42.819+01:00")
+			version2 := createVersion("2", "2020-03-29T11:54:42.819+01:00")
+			version2.Pinned = true
+			version3 := createVersion("3", "2021-03-29T11:54:42.819+01:00")
+
+			versions := s3.Versions{version1, version2, version3}
+			Expect(versions.Unpinned()).To(Equal(s3.Versions{version1, version3}))
+		})
+	})
 })
@@ -5,5 +5,5 @@ import (
 )
 
 func main() {
-	commandline.New().Execute()
+	commandline.Execute()
 }